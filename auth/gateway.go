@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// gatewayBufferSize is the buffer of the in-process connections of the gateway
+const gatewayBufferSize = 1 << 20
+
+// GatewayListener is the in-process listener of the REST gateway. Its connections do not leave
+// the process, they skip the TLS and carry the gateway identity instead of a client certificate.
+type GatewayListener struct {
+	*bufconn.Listener
+}
+
+// NewGatewayListener returns the listener, the gRPC server serves it next to its network listener
+func NewGatewayListener() *GatewayListener {
+	return &GatewayListener{Listener: bufconn.Listen(gatewayBufferSize)}
+}
+
+// Accept marks the connections as the ones of the gateway
+func (l *GatewayListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &gatewayConn{Conn: c}, nil
+}
+
+// ClientConn returns the connection of the gateway to the server serving the listener
+func (l *GatewayListener) ClientConn() (*grpc.ClientConn, error) {
+	return grpc.Dial("gateway",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+type gatewayConn struct {
+	net.Conn
+}
+
+// gatewayAddr names the gateway as the peer address in the logs and the audit records
+type gatewayAddr struct{}
+
+func (gatewayAddr) Network() string { return "gateway" }
+func (gatewayAddr) String() string  { return "gateway" }
+
+func (c *gatewayConn) RemoteAddr() net.Addr {
+	return gatewayAddr{}
+}
+
+// GatewayInfo is the auth info of the gateway connections. The gateway has no identity of its own,
+// its calls are authenticated by the credentials of the HTTP clients it forwards.
+type GatewayInfo struct {
+	credentials.CommonAuthInfo
+}
+
+// AuthType implements credentials.AuthInfo
+func (GatewayInfo) AuthType() string {
+	return "gateway"
+}
+
+// FromGateway reports whether the call came from the in-process gateway
+func FromGateway(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	_, ok = p.AuthInfo.(GatewayInfo)
+	return ok
+}
+
+// ServerCredentials accepts the connections of the gateway listener without a handshake and
+// the other connections with creds, nil is plaintext
+func ServerCredentials(creds credentials.TransportCredentials) credentials.TransportCredentials {
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	return &serverCredentials{TransportCredentials: creds}
+}
+
+type serverCredentials struct {
+	credentials.TransportCredentials
+}
+
+func (c *serverCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	if _, ok := conn.(*gatewayConn); ok {
+		// the connection does not leave the process
+		return conn, GatewayInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity}}, nil
+	}
+	return c.TransportCredentials.ServerHandshake(conn)
+}
+
+func (c *serverCredentials) Clone() credentials.TransportCredentials {
+	return &serverCredentials{TransportCredentials: c.TransportCredentials.Clone()}
+}
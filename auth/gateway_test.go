@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGatewayWithClientCA(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "certs")
+	defer os.RemoveAll(dir)
+	a := newTestAuthenticator(t, writeJWKS(t, dir))
+	ca := newTestCert(t, "ca", nil, x509.ExtKeyUsageAny)
+	caFile, _ := ca.write(t, dir, "ca")
+	// the server certificate has the common name of the backend client certificates
+	certFile, keyFile := newTestCert(t, "billing", ca, x509.ExtKeyUsageAny).write(t, dir, "server")
+	certs, err := NewCertReloader(TLSOptions{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, RequireClientCert: true})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	// the handler reports the client of the call as the error message
+	server := grpc.NewServer(
+		grpc.Creds(ServerCredentials(credentials.NewTLS(certs.ServerConfig()))),
+		grpc.StreamInterceptor(a.StreamServerInterceptor()),
+		grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+			p, _ := FromContext(stream.Context())
+			if !FromGateway(stream.Context()) {
+				return status.Errorf(codes.Internal, "Call of %s is not from the gateway", p.Name())
+			}
+			return status.Errorf(codes.FailedPrecondition, "%s", p.Name())
+		}))
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gatewayLis := NewGatewayListener()
+	go server.Serve(lis)
+	go server.Serve(gatewayLis)
+	defer server.Stop()
+
+	conn, err := gatewayLis.ClientConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	call := func(ctx context.Context, conn *grpc.ClientConn) error {
+		return conn.Invoke(ctx, getSession, &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+	}
+
+	err = call(context.Background(), conn)
+	if status.Code(err) != codes.PermissionDenied || status.Convert(err).Message() != "Client public may not call "+getSession {
+		t.Errorf("Gateway call without credentials got %v", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), APIKeyMetadata, "backend-key")
+	err = call(ctx, conn)
+	if status.Code(err) != codes.FailedPrecondition || status.Convert(err).Message() != "backend" {
+		t.Errorf("Gateway call with the API key got %v", err)
+	}
+
+	// the network listener still requires a client certificate
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	direct, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: roots, ServerName: "billing"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer direct.Close()
+	if err := call(ctx, direct); status.Code(err) != codes.Unavailable {
+		t.Errorf("Call without a client certificate got %v", err)
+	}
+}
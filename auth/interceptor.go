@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/logging"
+)
+
+// Metadata keys of the credentials
+const (
+	APIKeyMetadata        = "x-api-key"
+	AuthorizationMetadata = "authorization"
+)
+
+// Credential types of the principals
+const (
+	ViaAPIKey      = "api_key"
+	ViaJWT         = "jwt"
+	ViaCertificate = "certificate"
+	ViaAnonymous   = "anonymous"
+)
+
+// Principal is the authenticated caller of a call
+type Principal struct {
+	Client  *Client
+	Subject string // JWT subject or certificate common name, empty for API keys
+	Via     string
+
+	keyRules []*KeyRule
+}
+
+// Name returns the client name of the principal
+func (p *Principal) Name() string {
+	return p.Client.Name
+}
+
+// AllowsNamespace reports whether the principal may use the sessions of the namespace
+func (p *Principal) AllowsNamespace(namespace string) bool {
+	if len(p.Client.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range p.Client.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// AllNamespaces reports whether the principal is not restricted to some namespaces
+func (p *Principal) AllNamespaces() bool {
+	return len(p.Client.Namespaces) == 0
+}
+
+// CanReadKey reports whether the principal may see the session key
+func (p *Principal) CanReadKey(key string) bool {
+	if p.Client.Trusted {
+		return true
+	}
+	for _, r := range p.keyRules {
+		if r.Private && r.matches(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanWriteKey reports whether the principal may change or delete the session key
+func (p *Principal) CanWriteKey(key string) bool {
+	if p.Client.Trusted {
+		return true
+	}
+	for _, r := range p.keyRules {
+		if r.matches(key) && (r.Private || r.readOnly(p.Client.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+type principalKey struct{}
+
+type principalSlotKey struct{}
+
+// principalSlot keeps the principal for the interceptors which run ahead of the authentication
+type principalSlot struct {
+	p *Principal
+}
+
+// NewContext returns a context carrying the principal, it is recorded in the slot of TrackPrincipal too
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	if slot, ok := ctx.Value(principalSlotKey{}).(*principalSlot); ok {
+		slot.p = p
+	}
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// TrackPrincipal returns a context in which the authentication records the principal, so the
+// interceptors ahead of it, like the access log, find it with FromContext after the handler returned
+func TrackPrincipal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, principalSlotKey{}, &principalSlot{})
+}
+
+// FromContext returns the principal of the call, false if the server has no authentication
+// or the call is not authenticated yet
+func FromContext(ctx context.Context) (*Principal, bool) {
+	if p, ok := ctx.Value(principalKey{}).(*Principal); ok {
+		return p, true
+	}
+	if slot, ok := ctx.Value(principalSlotKey{}).(*principalSlot); ok && slot.p != nil {
+		return slot.p, true
+	}
+	return nil, false
+}
+
+// Authenticator identifies the callers by the policy and checks their calls
+type Authenticator struct {
+	policy  *Policy
+	jwt     *jwtVerifier
+	apiKeys map[string]*Client
+}
+
+// NewAuthenticator loads the JWKS files of the policy
+func NewAuthenticator(policy *Policy) (*Authenticator, error) {
+	jwt, err := loadJWTVerifier(policy.JWKSFiles, policy.Issuer, policy.Audience)
+	if err != nil {
+		return nil, err
+	}
+	a := &Authenticator{policy: policy, jwt: jwt, apiKeys: make(map[string]*Client)}
+	for _, c := range policy.Clients {
+		for _, hash := range c.APIKeySHA256 {
+			a.apiKeys[hash] = c
+		}
+	}
+	return a, nil
+}
+
+// Authenticate identifies the caller and checks that it may call the method.
+// API keys and bearer tokens take precedence over the client certificate.
+func (a *Authenticator) Authenticate(ctx context.Context, method string) (*Principal, error) {
+	p, err := a.identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Client.allowsMethod(method) {
+		return nil, status.Errorf(codes.PermissionDenied, "Client %s may not call %s", p.Name(), method)
+	}
+	p.keyRules = a.policy.KeyRules
+	return p, nil
+}
+
+func (a *Authenticator) identify(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(APIKeyMetadata); len(keys) > 0 {
+		sum := sha256.Sum256([]byte(keys[0]))
+		if c, ok := a.apiKeys[hex.EncodeToString(sum[:])]; ok {
+			return &Principal{Client: c, Via: ViaAPIKey}, nil
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "Invalid API key")
+	}
+	if values := md.Get(AuthorizationMetadata); len(values) > 0 {
+		token := strings.TrimSpace(values[0])
+		if len(token) < 7 || !strings.EqualFold(token[:7], "Bearer ") {
+			return nil, status.Errorf(codes.Unauthenticated, "Unsupported authorization scheme")
+		}
+		claims, err := a.jwt.verify(strings.TrimSpace(token[7:]))
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		}
+		for _, c := range a.policy.Clients {
+			if contains(c.Subjects, claims.Subject) || contains(c.Subjects, "*") {
+				return &Principal{Client: c, Subject: claims.Subject, Via: ViaJWT}, nil
+			}
+		}
+		return nil, status.Errorf(codes.PermissionDenied, "Unknown subject %q", claims.Subject)
+	}
+	// the calls of the gateway have no certificate, they carry only the forwarded credentials
+	if identity, ok := PeerIdentity(ctx); ok {
+		for _, c := range a.policy.Clients {
+			if contains(c.CertNames, identity.CommonName) {
+				return &Principal{Client: c, Subject: identity.CommonName, Via: ViaCertificate}, nil
+			}
+		}
+	}
+	for _, c := range a.policy.Clients {
+		if c.Anonymous {
+			return &Principal{Client: c, Via: ViaAnonymous}, nil
+		}
+	}
+	return nil, status.Errorf(codes.Unauthenticated, "Missing credentials")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// healthMethodPrefix marks the health checks, which are called by the load balancers without credentials
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// logRejected logs the calls which fail the authentication, the access log covers only the DSessionService
+func logRejected(ctx context.Context, method string, err error) {
+	stat := status.Convert(err)
+	logging.Warn(ctx, "Call rejected", "method", method, "code", stat.Code().String(), "error", stat.Message())
+}
+
+// UnaryServerInterceptor authenticates the unary calls and puts the principal into their context
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, healthMethodPrefix) {
+			return handler(ctx, req)
+		}
+		p, err := a.Authenticate(ctx, info.FullMethod)
+		if err != nil {
+			logRejected(ctx, info.FullMethod, err)
+			return nil, err
+		}
+		return handler(NewContext(ctx, p), req)
+	}
+}
+
+// StreamServerInterceptor authenticates the streaming calls and puts the principal into their context
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, healthMethodPrefix) {
+			return handler(srv, ss)
+		}
+		p, err := a.Authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			logRejected(ss.Context(), info.FullMethod, err)
+			return err
+		}
+		return handler(srv, &principalStream{ServerStream: ss, ctx: NewContext(ss.Context(), p)})
+	}
+}
+
+// principalStream replaces the context of a server stream
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
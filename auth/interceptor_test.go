@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	getSession        = "/hobord.session.DSessionService/GetSession"
+	invalidateSession = "/hobord.session.DSessionService/InvalidateSession"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// signJWT signs the claims with the ES256 key
+func signJWT(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	input := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return input + "." + b64(sig)
+}
+
+func writeJWKS(t *testing.T, dir string, keys ...map[string]string) string {
+	file := filepath.Join(dir, "jwks.json")
+	data, _ := json.Marshal(map[string]interface{}{"keys": keys})
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func newTestAuthenticator(t *testing.T, jwks string) *Authenticator {
+	policy := &Policy{
+		JWKSFiles: []string{jwks},
+		Issuer:    "https://auth.example.com/",
+		Audience:  "dsession",
+		Clients: []*Client{
+			{Name: "backend", APIKeySHA256: []string{hashKey("backend-key")}, CertNames: []string{"billing"}, Methods: []string{"*"}},
+			{Name: "admin", Subjects: []string{"alice"}, Methods: []string{"hobord.session.DSessionAdminService/*"}},
+			{Name: "browser", Subjects: []string{"*"}, Namespaces: []string{"shop"},
+				Methods: []string{"hobord.session.DSessionService/Get*"}},
+			{Name: "public", Anonymous: true, Methods: []string{"grpc.health.v1.Health/*"}},
+		},
+	}
+	if err := policy.init(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	a, err := NewAuthenticator(policy)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	return a
+}
+
+func TestAuthenticate(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "jwks")
+	defer os.RemoveAll(dir)
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a := newTestAuthenticator(t, writeJWKS(t, dir, map[string]string{
+		"kty": "EC", "kid": "k1", "crv": "P-256", "use": "sig",
+		"x": b64(key.X.Bytes()), "y": b64(key.Y.Bytes()),
+	}))
+
+	exp := time.Now().Add(time.Hour).Unix()
+	claims := func(sub string) map[string]interface{} {
+		return map[string]interface{}{"sub": sub, "iss": "https://auth.example.com/", "aud": []string{"dsession"}, "exp": exp}
+	}
+	bearer := func(token string) metadata.MD {
+		return metadata.Pairs("authorization", "Bearer "+token)
+	}
+	expired := claims("bob")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	foreign := claims("bob")
+	foreign["aud"] = "billing"
+
+	tests := []struct {
+		name   string
+		md     metadata.MD
+		method string
+		client string
+		code   codes.Code
+	}{
+		{"api key", metadata.Pairs("x-api-key", "backend-key"), invalidateSession, "backend", codes.OK},
+		{"wrong api key", metadata.Pairs("x-api-key", "guess"), getSession, "", codes.Unauthenticated},
+		{"jwt subject", bearer(signJWT(t, key, "k1", claims("alice"))), "/hobord.session.DSessionAdminService/ListSessions", "admin", codes.OK},
+		{"jwt any subject", bearer(signJWT(t, key, "k1", claims("bob"))), getSession, "browser", codes.OK},
+		{"jwt read only", bearer(signJWT(t, key, "k1", claims("bob"))), invalidateSession, "", codes.PermissionDenied},
+		{"jwt unknown key", bearer(signJWT(t, other, "k1", claims("bob"))), getSession, "", codes.Unauthenticated},
+		{"jwt expired", bearer(signJWT(t, key, "k1", expired)), getSession, "", codes.Unauthenticated},
+		{"jwt audience", bearer(signJWT(t, key, "k1", foreign)), getSession, "", codes.Unauthenticated},
+		{"basic auth", metadata.Pairs("authorization", "Basic Ym9iOnB3"), getSession, "", codes.Unauthenticated},
+		{"anonymous", nil, "/grpc.health.v1.Health/Check", "public", codes.OK},
+		{"anonymous denied", nil, getSession, "", codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+		p, err := a.Authenticate(ctx, tt.method)
+		if code := status.Code(err); code != tt.code {
+			t.Errorf("%s: Got code %s, want %s (%v)", tt.name, code, tt.code, err)
+			continue
+		}
+		if err == nil && p.Name() != tt.client {
+			t.Errorf("%s: Got client %s, want %s", tt.name, p.Name(), tt.client)
+		}
+	}
+}
+
+func TestAuthenticateCertificate(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "jwks")
+	defer os.RemoveAll(dir)
+	a := newTestAuthenticator(t, writeJWKS(t, dir))
+	ca := newTestCert(t, "ca", nil, x509.ExtKeyUsageAny)
+	client := newTestCert(t, "billing", ca, x509.ExtKeyUsageClientAuth)
+	state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{client.cert, ca.cert}}}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+
+	p, err := a.Authenticate(ctx, invalidateSession)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if p.Name() != "backend" || p.Via != ViaCertificate || p.Subject != "billing" {
+		t.Errorf("Got principal %+v", p)
+	}
+}
+
+func TestJWTVerifierRSA(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	v := &jwtVerifier{keys: []*jwk{{Kty: "RSA", Kid: "r1", key: &key.PublicKey}}, now: time.Now}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "r1"})
+	payload, _ := json.Marshal(map[string]interface{}{"sub": "svc", "exp": time.Now().Add(time.Minute).Unix()})
+	input := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(input))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+
+	claims, err := v.verify(input + "." + b64(sig))
+	if err != nil || claims.Subject != "svc" {
+		t.Errorf("Got %v, %v", claims, err)
+	}
+	none, _ := json.Marshal(map[string]string{"alg": "none"})
+	if _, err := v.verify(b64(none) + "." + b64(payload) + "."); err == nil {
+		t.Errorf("Unsigned token is accepted")
+	}
+}
+
+func TestPolicyInit(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+	}{
+		{"no methods", Policy{Clients: []*Client{{Name: "a"}}}},
+		{"bad pattern", Policy{Clients: []*Client{{Name: "a", Methods: []string{"["}}}}},
+		{"bad hash", Policy{Clients: []*Client{{Name: "a", Methods: []string{"*"}, APIKeySHA256: []string{"secret"}}}}},
+		{"duplicated", Policy{Clients: []*Client{{Name: "a", Methods: []string{"*"}}, {Name: "a", Methods: []string{"*"}}}}},
+		{"subjects without jwks", Policy{Clients: []*Client{{Name: "a", Methods: []string{"*"}, Subjects: []string{"*"}}}}},
+	}
+	for _, tt := range tests {
+		if err := tt.policy.init(); err == nil {
+			t.Errorf("%s: Invalid policy is accepted", tt.name)
+		}
+	}
+}
+
+func TestPrincipalKeys(t *testing.T) {
+	rules := []*KeyRule{
+		{Keys: []string{"auth_*"}, Private: true},
+		{Keys: []string{"roles"}, ReadOnly: []string{"browser"}},
+	}
+	browser := &Principal{Client: &Client{Name: "browser"}, keyRules: rules}
+	partner := &Principal{Client: &Client{Name: "partner"}, keyRules: rules}
+	backend := &Principal{Client: &Client{Name: "backend", Trusted: true}, keyRules: rules}
+
+	tests := []struct {
+		p     *Principal
+		key   string
+		read  bool
+		write bool
+	}{
+		{browser, "cart", true, true},
+		{browser, "auth_token", false, false},
+		{browser, "roles", true, false},
+		{partner, "roles", true, true},
+		{backend, "auth_token", true, true},
+	}
+	for _, tt := range tests {
+		if got := tt.p.CanReadKey(tt.key); got != tt.read {
+			t.Errorf("%s read %s: Got %t", tt.p.Name(), tt.key, got)
+		}
+		if got := tt.p.CanWriteKey(tt.key); got != tt.write {
+			t.Errorf("%s write %s: Got %t", tt.p.Name(), tt.key, got)
+		}
+	}
+
+	policy := &Policy{Clients: []*Client{{Name: "a", Methods: []string{"*"}}}, KeyRules: []*KeyRule{{Keys: []string{"roles"}, ReadOnly: []string{"b"}}}}
+	if err := policy.init(); err == nil {
+		t.Errorf("Key rule of an unknown client is accepted")
+	}
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // hashes of the JWT algorithms
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// clockSkew is the tolerance of the exp and nbf claims
+const clockSkew = 30 * time.Second
+
+var errInvalidJWT = errors.New("Invalid bearer token")
+
+// jwtAlgorithms are the supported JWS algorithms, the others (none, HS*) are rejected
+var jwtAlgorithms = map[string]struct {
+	kty  string
+	hash crypto.Hash
+}{
+	"RS256": {"RSA", crypto.SHA256},
+	"RS384": {"RSA", crypto.SHA384},
+	"RS512": {"RSA", crypto.SHA512},
+	"ES256": {"EC", crypto.SHA256},
+	"ES384": {"EC", crypto.SHA384},
+	"ES512": {"EC", crypto.SHA512},
+}
+
+// jwk is a public key of a JWKS file
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+
+	key crypto.PublicKey
+}
+
+// jwtClaims are the checked claims of a token
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+}
+
+// hasAudience reports whether aud, a string or a list, contains the audience
+func (c *jwtClaims) hasAudience(audience string) bool {
+	var single string
+	if json.Unmarshal(c.Audience, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	json.Unmarshal(c.Audience, &list)
+	for _, aud := range list {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// jwtVerifier checks the signature and the claims of the bearer tokens
+type jwtVerifier struct {
+	keys     []*jwk
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+func loadJWTVerifier(files []string, issuer, audience string) (*jwtVerifier, error) {
+	v := &jwtVerifier{issuer: issuer, audience: audience, now: time.Now}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var set struct {
+			Keys []*jwk `json:"keys"`
+		}
+		if err := json.Unmarshal(data, &set); err != nil {
+			return nil, fmt.Errorf("Invalid JWKS file %s: %s", file, err)
+		}
+		for _, k := range set.Keys {
+			if k.Use != "" && k.Use != "sig" {
+				continue
+			}
+			if err := k.parse(); err != nil {
+				return nil, fmt.Errorf("Invalid key %q in %s: %s", k.Kid, file, err)
+			}
+			v.keys = append(v.keys, k)
+		}
+	}
+	return v, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (k *jwk) parse() error {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return err
+		}
+		if n.BitLen() < 2048 {
+			return fmt.Errorf("RSA key is shorter than 2048 bits")
+		}
+		k.key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return fmt.Errorf("point is not on the curve")
+		}
+		k.key = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	default:
+		return fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+	return nil
+}
+
+// verify checks the signed token and returns its claims
+func (v *jwtVerifier) verify(token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidJWT
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errInvalidJWT
+	}
+	alg, ok := jwtAlgorithms[header.Alg]
+	if !ok {
+		return nil, errInvalidJWT
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errInvalidJWT
+	}
+	h := alg.hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	digest := h.Sum(nil)
+
+	verified := false
+	for _, k := range v.keys {
+		if k.Kty != alg.kty || (k.Alg != "" && k.Alg != header.Alg) || (header.Kid != "" && k.Kid != header.Kid) {
+			continue
+		}
+		if verifySignature(k.key, alg.hash, digest, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errInvalidJWT
+	}
+
+	claims := &jwtClaims{}
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return nil, errInvalidJWT
+	}
+	now := v.now()
+	if claims.ExpiresAt == nil || now.Add(-clockSkew).Unix() >= *claims.ExpiresAt {
+		return nil, fmt.Errorf("Bearer token is expired")
+	}
+	if claims.NotBefore != nil && now.Add(clockSkew).Unix() < *claims.NotBefore {
+		return nil, fmt.Errorf("Bearer token is not valid yet")
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, fmt.Errorf("Bearer token has an unknown issuer")
+	}
+	if v.audience != "" && !claims.hasAudience(v.audience) {
+		return nil, fmt.Errorf("Bearer token is not issued for this service")
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func verifySignature(key crypto.PublicKey, hash crypto.Hash, digest, sig []byte) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, digest, sig) == nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
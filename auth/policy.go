@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// Client is a caller of the service and the calls it may make
+type Client struct {
+	Name string `json:"name"`
+	// APIKeySHA256 are the hex SHA-256 hashes of the API keys of the client:
+	// printf %s "$KEY" | sha256sum
+	APIKeySHA256 []string `json:"api_key_sha256"`
+	Subjects     []string `json:"subjects"`   // subjects of the JWT bearer tokens, "*" = any valid token
+	CertNames    []string `json:"cert_names"` // common names of the client certificates
+	Anonymous    bool     `json:"anonymous"`  // the client of the calls without credentials
+	Methods      []string `json:"methods"`    // allowed full method names or path.Match patterns, "*" = all
+	Namespaces   []string `json:"namespaces"` // allowed session namespaces, empty = all
+	Trusted      bool     `json:"trusted"`    // the key rules do not apply to the client
+}
+
+// allowsMethod reports whether the client may call the full method name
+func (c *Client) allowsMethod(method string) bool {
+	method = strings.TrimPrefix(method, "/")
+	for _, pattern := range c.Methods {
+		if pattern == "*" {
+			return true
+		}
+		if ok, _ := path.Match(strings.TrimPrefix(pattern, "/"), method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) init() error {
+	if c.Name == "" {
+		return fmt.Errorf("Client without name")
+	}
+	if len(c.Methods) == 0 {
+		return fmt.Errorf("Client %s has no allowed methods", c.Name)
+	}
+	for _, pattern := range c.Methods {
+		if _, err := path.Match(strings.TrimPrefix(pattern, "/"), ""); err != nil {
+			return fmt.Errorf("Client %s has an invalid method pattern %q", c.Name, pattern)
+		}
+	}
+	for i, hash := range c.APIKeySHA256 {
+		hash = strings.ToLower(hash)
+		if b, err := hex.DecodeString(hash); err != nil || len(b) != 32 {
+			return fmt.Errorf("Client %s has an invalid API key hash %q", c.Name, hash)
+		}
+		c.APIKeySHA256[i] = hash
+	}
+	return nil
+}
+
+// KeyRule restricts the untrusted clients in the use of some session keys
+type KeyRule struct {
+	Keys     []string `json:"keys"`      // path.Match patterns of the session keys
+	Private  bool     `json:"private"`   // the keys are hidden from the untrusted clients
+	ReadOnly []string `json:"read_only"` // untrusted clients which may only read the keys, "*" = all
+}
+
+// matches reports whether the rule covers the session key
+func (r *KeyRule) matches(key string) bool {
+	for _, pattern := range r.Keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// readOnly reports whether the client may only read the keys of the rule
+func (r *KeyRule) readOnly(client string) bool {
+	for _, name := range r.ReadOnly {
+		if name == "*" || name == client {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *KeyRule) init(clients map[string]bool) error {
+	if len(r.Keys) == 0 {
+		return fmt.Errorf("Key rule without keys")
+	}
+	for _, pattern := range r.Keys {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("Invalid key pattern %q", pattern)
+		}
+	}
+	if !r.Private && len(r.ReadOnly) == 0 {
+		return fmt.Errorf("Key rule %v is neither private nor read-only", r.Keys)
+	}
+	for _, name := range r.ReadOnly {
+		if name != "*" && !clients[name] {
+			return fmt.Errorf("Key rule %v refers to the unknown client %s", r.Keys, name)
+		}
+	}
+	return nil
+}
+
+// Policy is the authentication and authorization config of the server, for example
+// browsers with a JWT reading the sessions of a namespace and backends with full access:
+//
+//	{
+//	  "jwks_files": ["/etc/dsession/jwks.json"],
+//	  "audience": "dsession",
+//	  "clients": [
+//	    {"name": "backend", "api_key_sha256": ["9f86d081..."], "methods": ["*"], "trusted": true},
+//	    {"name": "browser", "subjects": ["*"], "namespaces": ["shop"],
+//	     "methods": ["hobord.session.DSessionService/Get*", "hobord.session.DSessionService/WatchSession"]}
+//	  ],
+//	  "key_rules": [
+//	    {"keys": ["auth_*"], "private": true},
+//	    {"keys": ["roles"], "read_only": ["*"]}
+//	  ]
+//	}
+type Policy struct {
+	JWKSFiles []string   `json:"jwks_files"` // public keys of the JWT bearer tokens
+	Issuer    string     `json:"issuer"`     // required iss claim of the tokens, empty = any
+	Audience  string     `json:"audience"`   // required aud claim of the tokens, empty = any
+	Clients   []*Client  `json:"clients"`    // the first matching client is used
+	KeyRules  []*KeyRule `json:"key_rules"`  // access of the untrusted clients to the session keys
+}
+
+// LoadPolicy reads the JSON policy file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("Invalid auth policy %s: %s", path, err)
+	}
+	if err := policy.init(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (p *Policy) init() error {
+	names := make(map[string]bool)
+	anonymous := false
+	for _, c := range p.Clients {
+		if err := c.init(); err != nil {
+			return err
+		}
+		if names[c.Name] {
+			return fmt.Errorf("Duplicated client name: %s", c.Name)
+		}
+		names[c.Name] = true
+		if c.Anonymous {
+			if anonymous {
+				return fmt.Errorf("More than one anonymous client")
+			}
+			anonymous = true
+		}
+		if len(c.Subjects) > 0 && len(p.JWKSFiles) == 0 {
+			return fmt.Errorf("Client %s accepts JWT subjects but no JWKS file is given", c.Name)
+		}
+	}
+	for _, r := range p.KeyRules {
+		if err := r.init(names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
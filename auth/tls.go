@@ -0,0 +1,192 @@
+// Package auth secures the connections of the DSessionService and identifies its clients.
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/hobord/dsession/logging"
+)
+
+// TLSOptions configures the TLS of a listener
+type TLSOptions struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the CA bundle of the client certificates, empty = no client certificates
+	ClientCAFile string
+	// RequireClientCert rejects the clients without a verified certificate
+	RequireClientCert bool
+}
+
+// CertReloader serves the certificate and the client CA bundle of the files,
+// and loads them again when the files change
+type CertReloader struct {
+	opts TLSOptions
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// NewCertReloader loads the files of the options
+func NewCertReloader(opts TLSOptions) (*CertReloader, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, fmt.Errorf("TLS needs a certificate and a key file")
+	}
+	if opts.RequireClientCert && opts.ClientCAFile == "" {
+		return nil, fmt.Errorf("Client certificates are required but no CA file is given")
+	}
+	r := &CertReloader{opts: opts}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *CertReloader) files() []string {
+	files := []string{r.opts.CertFile, r.opts.KeyFile}
+	if r.opts.ClientCAFile != "" {
+		files = append(files, r.opts.ClientCAFile)
+	}
+	return files
+}
+
+// Reload loads the files again, on failure the previous certificate stays in use
+func (r *CertReloader) Reload() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return err
+	}
+	var clientCAs *x509.CertPool
+	if r.opts.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("No certificate found in %s", r.opts.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.mu.Unlock()
+	return nil
+}
+
+// changed reports whether a file was modified since the last load
+func (r *CertReloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			// the file is being replaced, check it again later
+			return false
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch checks the files in every interval and reloads them when they change
+func (r *CertReloader) Watch(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				logging.Error(context.Background(), "Failed to reload the TLS certificate", "error", err)
+				continue
+			}
+			logging.Info(context.Background(), "TLS certificate reloaded")
+		}
+	}()
+}
+
+// Certificate returns the current certificate
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// ServerConfig returns the TLS configuration of the server, every handshake uses the current files
+func (r *CertReloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"h2"},
+			}
+			if r.clientCAs != nil {
+				config.ClientCAs = r.clientCAs
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+				if r.opts.RequireClientCert {
+					config.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+			}
+			return config, nil
+		},
+	}
+}
+
+// Identity is the subject of a verified client certificate
+type Identity struct {
+	CommonName   string
+	Organization []string
+	DNSNames     []string
+	URIs         []string
+}
+
+// PeerIdentity returns the identity of the client certificate of the call,
+// false if the client has no verified certificate
+func PeerIdentity(ctx context.Context) (*Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	cert := info.State.VerifiedChains[0][0]
+	identity := &Identity{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity, true
+}
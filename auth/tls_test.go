@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"dsession"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{cn},
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) write(t *testing.T, dir, name string) (string, string) {
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+"-key.pem")
+	keyDer, _ := x509.MarshalECPrivateKey(c.key)
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCert() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+// handshake connects a client to the server config, it returns the server side state
+func handshake(server, client *tls.Config) (tls.ConnectionState, error) {
+	sconn, cconn := net.Pipe()
+	errc := make(chan error, 1)
+	go func() {
+		c := tls.Client(cconn, client)
+		errc <- c.Handshake()
+		cconn.Close()
+	}()
+	s := tls.Server(sconn, server)
+	err := s.Handshake()
+	state := s.ConnectionState()
+	sconn.Close()
+	if cerr := <-errc; err == nil {
+		err = cerr
+	}
+	return state, err
+}
+
+func TestCertReloaderMutualTLS(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "certs")
+	defer os.RemoveAll(dir)
+	ca := newTestCert(t, "ca", nil, x509.ExtKeyUsageAny)
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, "localhost", ca, x509.ExtKeyUsageServerAuth).write(t, dir, "server")
+	client := newTestCert(t, "billing", ca, x509.ExtKeyUsageClientAuth)
+
+	r, err := NewCertReloader(TLSOptions{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, RequireClientCert: true})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	state, err := handshake(r.ServerConfig(), &tls.Config{RootCAs: roots, ServerName: "localhost", Certificates: []tls.Certificate{client.tlsCert()}})
+	if err != nil {
+		t.Fatalf("Handshake failed: %v", err)
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	identity, ok := PeerIdentity(ctx)
+	if !ok || identity.CommonName != "billing" || identity.Organization[0] != "dsession" {
+		t.Errorf("Got identity %v", identity)
+	}
+
+	if _, err := handshake(r.ServerConfig(), &tls.Config{RootCAs: roots, ServerName: "localhost"}); err == nil {
+		t.Errorf("Client without certificate is accepted")
+	}
+	if _, ok := PeerIdentity(context.Background()); ok {
+		t.Errorf("Got identity without peer")
+	}
+}
+
+func TestCertReloaderReload(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "certs")
+	defer os.RemoveAll(dir)
+	ca := newTestCert(t, "ca", nil, x509.ExtKeyUsageAny)
+	certFile, keyFile := newTestCert(t, "old", ca, x509.ExtKeyUsageServerAuth).write(t, dir, "server")
+
+	r, err := NewCertReloader(TLSOptions{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if r.changed() {
+		t.Errorf("Unchanged files are reported")
+	}
+
+	renewed := newTestCert(t, "new", ca, x509.ExtKeyUsageServerAuth)
+	renewed.write(t, dir, "server")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, later, later)
+	if !r.changed() {
+		t.Fatalf("Changed files are not reported")
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	if _, err := handshake(r.ServerConfig(), &tls.Config{RootCAs: roots, ServerName: "new"}); err != nil {
+		t.Errorf("Reloaded certificate is not served: %v", err)
+	}
+
+	ioutil.WriteFile(keyFile, []byte("broken"), 0600)
+	if err := r.Reload(); err == nil {
+		t.Errorf("Broken key is loaded")
+	}
+	if string(r.Certificate().Certificate[0]) != string(renewed.der) {
+		t.Errorf("Failed reload replaced the certificate")
+	}
+}
@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	st "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/hobord/dsession/session"
+)
+
+// MetadataKey is the gRPC metadata key which carries the session id between the services
+const MetadataKey = "x-dsession-id"
+
+type idContextKey struct{}
+
+// WithSessionID returns a copy of ctx whose outgoing calls carry the session id
+func WithSessionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idContextKey{}, id)
+}
+
+// sessionID returns the id of the session which the outgoing calls of ctx carry
+func sessionID(ctx context.Context) string {
+	if id, ok := ctx.Value(idContextKey{}).(string); ok && id != "" {
+		return id
+	}
+	if s := FromContext(ctx); s != nil {
+		return s.ID()
+	}
+	return ""
+}
+
+// outgoingContext adds the session id to the outgoing metadata if the call has none yet
+func outgoingContext(ctx context.Context) context.Context {
+	id := sessionID(ctx)
+	if id == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(MetadataKey)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+}
+
+// UnaryClientInterceptor sends the session id of the context with the unary calls
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor sends the session id of the context with the streaming calls
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+type cacheEntry struct {
+	values  map[string]*st.Value
+	found   bool
+	expires time.Time
+}
+
+// Resolver loads the sessions of the incoming calls through the DSessionService,
+// the results are cached for a short time
+type Resolver struct {
+	client     pb.DSessionServiceClient
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewResolver returns a resolver which caches the sessions for ttl, at most maxEntries of them.
+// A zero ttl disables the caching.
+func NewResolver(client pb.DSessionServiceClient, ttl time.Duration, maxEntries int) *Resolver {
+	return &Resolver{
+		client:     client,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+// Session returns a lazily loaded session handle of the id
+func (r *Resolver) Session(ctx context.Context, id string) *Session {
+	s := NewSession(ctx, r.client, id)
+	s.resolver = r
+	return s
+}
+
+// fetch returns the values of the session from the cache or the service
+func (r *Resolver) fetch(ctx context.Context, id string) (map[string]*st.Value, bool, error) {
+	r.mu.Lock()
+	entry, ok := r.entries[id]
+	r.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return copyValues(entry.values), entry.found, nil
+	}
+
+	values, found, err := fetchSession(ctx, r.client, id)
+	if err != nil {
+		return nil, false, err
+	}
+	r.put(id, cacheEntry{values: values, found: found})
+	return copyValues(values), found, nil
+}
+
+// store caches the values written by a session
+func (r *Resolver) store(id string, values map[string]*st.Value) {
+	if r == nil {
+		return
+	}
+	r.put(id, cacheEntry{values: copyValues(values), found: true})
+}
+
+// forget drops the session from the cache
+func (r *Resolver) forget(id string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	delete(r.entries, id)
+	r.mu.Unlock()
+}
+
+func (r *Resolver) put(id string, entry cacheEntry) {
+	if r.ttl <= 0 {
+		return
+	}
+	entry.expires = time.Now().Add(r.ttl)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[id]; !ok && r.maxEntries > 0 && len(r.entries) >= r.maxEntries {
+		now := time.Now()
+		for key, e := range r.entries {
+			if now.After(e.expires) {
+				delete(r.entries, key)
+			}
+		}
+		// still full, drop an arbitrary entry
+		for key := range r.entries {
+			if len(r.entries) < r.maxEntries {
+				break
+			}
+			delete(r.entries, key)
+		}
+	}
+	r.entries[id] = entry
+}
+
+// incoming returns ctx with the session of the incoming call, nil if the call has none
+func (r *Resolver) incoming(ctx context.Context) (context.Context, *Session) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+	ids := md.Get(MetadataKey)
+	if len(ids) == 0 || ids[0] == "" {
+		return ctx, nil
+	}
+	s := r.Session(ctx, ids[0])
+	return NewContext(ctx, s), s
+}
+
+// UnaryServerInterceptor puts the session of the incoming unary calls into the context of
+// the handler and flushes its changes before the response, a failed flush fails the call
+func UnaryServerInterceptor(r *Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, s := r.incoming(ctx)
+		res, err := handler(ctx, req)
+		if flushErr := flush(s); err == nil && flushErr != nil {
+			return nil, flushErr
+		}
+		return res, err
+	}
+}
+
+// StreamServerInterceptor puts the session of the incoming streaming calls into the context of
+// the handler and flushes its changes after the handler, a failed flush fails the call
+func StreamServerInterceptor(r *Resolver) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, s := r.incoming(ss.Context())
+		err := handler(srv, &sessionStream{ServerStream: ss, ctx: ctx})
+		if flushErr := flush(s); err == nil {
+			err = flushErr
+		}
+		return err
+	}
+}
+
+// sessionStream is a server stream with the context of the session
+type sessionStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *sessionStream) Context() context.Context {
+	return s.ctx
+}
+
+// flush saves the changes of the session of the call, the error fails the call,
+// as the handler's changes are lost
+func flush(s *Session) error {
+	if s == nil {
+		return nil
+	}
+	return s.Flush()
+}
+
+func copyValues(values map[string]*st.Value) map[string]*st.Value {
+	if values == nil {
+		return nil
+	}
+	copied := make(map[string]*st.Value, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	st "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{
+		{"no session", context.Background(), nil},
+		{"explicit id", WithSessionID(context.Background(), "s1"), []string{"s1"}},
+		{"session", NewContext(context.Background(), NewSession(context.Background(), nil, "s2")), []string{"s2"}},
+		{"already set", metadata.AppendToOutgoingContext(WithSessionID(context.Background(), "s1"), MetadataKey, "s3"), []string{"s3"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			got = md.Get(MetadataKey)
+			return nil
+		}
+		interceptor(tt.ctx, "/test", nil, nil, nil, invoker)
+		if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+			t.Errorf("%s: Got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	c := newMemoryClient()
+	c.sessions["s1"] = map[string]*st.Value{"user": {Kind: &st.Value_StringValue{StringValue: "foo"}}}
+	interceptor := UnaryServerInterceptor(NewResolver(c, time.Minute, 10))
+
+	call := func(id string, handler grpc.UnaryHandler) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, id))
+		interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	}
+
+	for i := 0; i < 3; i++ {
+		call("s1", func(ctx context.Context, req interface{}) (interface{}, error) {
+			if user, _ := FromContext(ctx).GetString("user"); user != "foo" {
+				t.Errorf("Got user %q", user)
+			}
+			return nil, nil
+		})
+	}
+	if c.calls["GetSessions"] != 1 {
+		t.Errorf("Session is not cached: %v", c.calls)
+	}
+
+	call("s1", func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, FromContext(ctx).SetString("user", "bar")
+	})
+	if got := c.sessions["s1"]["user"].GetStringValue(); got != "bar" {
+		t.Errorf("Changes are not flushed: %q", got)
+	}
+	call("s1", func(ctx context.Context, req interface{}) (interface{}, error) {
+		if user, _ := FromContext(ctx).GetString("user"); user != "bar" {
+			t.Errorf("Cache is not updated: %q", user)
+		}
+		return nil, nil
+	})
+
+	call("missing", func(ctx context.Context, req interface{}) (interface{}, error) {
+		if err := FromContext(ctx).SetString("user", "baz"); err != ErrNoSession {
+			t.Errorf("Got error %v", err)
+		}
+		return nil, nil
+	})
+	if c.calls["CreateSession"] != 0 {
+		t.Errorf("Server interceptor created a session")
+	}
+
+	c.saveErr = errors.New("unavailable")
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "s1"))
+	res, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", FromContext(ctx).SetString("user", "baz")
+	})
+	if err != c.saveErr || res != nil {
+		t.Errorf("Got %v, %v, want the flush error", res, err)
+	}
+}
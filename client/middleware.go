@@ -0,0 +1,176 @@
+// Package client provides helpers for the Go services which use the DSessionService.
+//
+// The Middleware keeps the session id in a cookie and puts the lazily loaded
+// session of the request into its context:
+//
+//	m := client.NewMiddleware(pb.NewDSessionServiceClient(conn), client.Options{TTL: 3600})
+//	http.Handle("/", m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//		session := client.FromContext(r.Context())
+//		name, _ := session.GetString("name")
+//		session.SetNumber("visits", 1)
+//	})))
+//
+// Between the services the session id travels in the gRPC metadata. The client
+// interceptors send the session id of the context, the server interceptors resolve
+// it with a cached Resolver and put the session into the context of the handler:
+//
+//	conn, _ := grpc.Dial(addr, grpc.WithUnaryInterceptor(client.UnaryClientInterceptor()))
+//	resolver := client.NewResolver(pb.NewDSessionServiceClient(sessionConn), 5*time.Second, 10000)
+//	s := grpc.NewServer(grpc.UnaryInterceptor(client.UnaryServerInterceptor(resolver)))
+package client
+
+import (
+	"log"
+	"net/http"
+
+	pb "github.com/hobord/dsession/session"
+)
+
+// DefaultCookieName is the name of the session cookie if Options.CookieName is empty
+const DefaultCookieName = "dsession"
+
+// Options configures the session cookie and the created sessions
+type Options struct {
+	CookieName string
+	Domain     string
+	Path       string
+	// Insecure allows the cookie over plain HTTP, only for local development
+	Insecure bool
+	SameSite http.SameSite
+	// TTL of the created sessions in seconds, 0 = no expiry
+	TTL int64
+	// Namespace of the created sessions
+	Namespace string
+	// ErrorHandler writes the response when the session changes cannot be saved,
+	// the default logs the error and responds 500
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Middleware reads and writes the session cookie and flushes the session changes
+// before the response is written
+type Middleware struct {
+	client pb.DSessionServiceClient
+	opts   Options
+}
+
+// NewMiddleware returns a session middleware using the client
+func NewMiddleware(client pb.DSessionServiceClient, opts Options) *Middleware {
+	if opts.CookieName == "" {
+		opts.CookieName = DefaultCookieName
+	}
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+	if opts.ErrorHandler == nil {
+		opts.ErrorHandler = defaultErrorHandler
+	}
+	return &Middleware{client: client, opts: opts}
+}
+
+// Handler wraps next with the session handling
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var id string
+		if cookie, err := r.Cookie(m.opts.CookieName); err == nil {
+			id = cookie.Value
+		}
+
+		ctx := r.Context()
+		session := NewSession(ctx, m.client, id)
+		// cookie is set when the response is committed, the last change wins
+		var cookie *http.Cookie
+		rw := &responseWriter{ResponseWriter: w}
+		session.create = func() (string, error) {
+			if rw.committed {
+				return "", ErrResponseWritten
+			}
+			res, err := m.client.CreateSession(ctx, &pb.CreateSessionMessage{Ttl: m.opts.TTL, Namespace: m.opts.Namespace})
+			if err != nil {
+				return "", err
+			}
+			cookie = m.cookie(res.Id, int(m.opts.TTL))
+			return res.Id, nil
+		}
+		session.invalidated = func() {
+			cookie = m.cookie("", -1)
+		}
+		session.missing = func() {
+			cookie = m.cookie("", -1)
+		}
+		rw.commit = func() error {
+			if err := session.startWriteThrough(); err != nil {
+				m.opts.ErrorHandler(w, r, err)
+				return err
+			}
+			if cookie != nil {
+				http.SetCookie(w, cookie)
+			}
+			return nil
+		}
+
+		next.ServeHTTP(rw, r.WithContext(NewContext(ctx, session)))
+		rw.before()
+	})
+}
+
+func defaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("Failed to save the session: %s", err)
+	http.Error(w, "Failed to save the session", http.StatusInternalServerError)
+}
+
+// responseWriter saves the session before the status and the headers are written,
+// if it fails the error response replaces the response of the handler
+type responseWriter struct {
+	http.ResponseWriter
+	commit    func() error
+	committed bool
+	err       error
+}
+
+// before commits the session once, it reports whether the response can be written
+func (w *responseWriter) before() bool {
+	if !w.committed {
+		w.committed = true
+		w.err = w.commit()
+	}
+	return w.err == nil
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.before() {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.before() {
+		return 0, w.err
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for the streaming handlers
+func (w *responseWriter) Flush() {
+	if !w.before() {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (m *Middleware) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     m.opts.CookieName,
+		Value:    value,
+		Domain:   m.opts.Domain,
+		Path:     m.opts.Path,
+		MaxAge:   maxAge,
+		Secure:   !m.opts.Insecure,
+		HttpOnly: true,
+		SameSite: m.opts.SameSite,
+	}
+}
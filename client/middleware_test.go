@@ -0,0 +1,286 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	st "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/hobord/dsession/session"
+)
+
+// memoryClient is an in-memory DSessionServiceClient
+type memoryClient struct {
+	pb.DSessionServiceClient
+	sessions map[string]map[string]*st.Value
+	calls    map[string]int
+	// saveErr fails the AddValuesToSession calls
+	saveErr error
+}
+
+func newMemoryClient() *memoryClient {
+	return &memoryClient{
+		sessions: make(map[string]map[string]*st.Value),
+		calls:    make(map[string]int),
+	}
+}
+
+func (c *memoryClient) CreateSession(ctx context.Context, in *pb.CreateSessionMessage, opts ...grpc.CallOption) (*pb.SessionResponse, error) {
+	c.calls["CreateSession"]++
+	id := fmt.Sprintf("s%d", len(c.sessions)+1)
+	c.sessions[id] = make(map[string]*st.Value)
+	return &pb.SessionResponse{Id: id}, nil
+}
+
+func (c *memoryClient) GetSessions(ctx context.Context, in *pb.GetSessionsMessage, opts ...grpc.CallOption) (*pb.SessionsResponse, error) {
+	c.calls["GetSessions"]++
+	res := &pb.SessionsResponse{}
+	for _, id := range in.Ids {
+		if id == "invalid" {
+			res.Results = append(res.Results, &pb.SessionResult{Id: id, Code: int32(codes.InvalidArgument), Error: "Invalid session token"})
+			continue
+		}
+		values, ok := c.sessions[id]
+		if !ok {
+			res.Results = append(res.Results, &pb.SessionResult{Id: id, Code: int32(codes.NotFound)})
+			continue
+		}
+		res.Results = append(res.Results, &pb.SessionResult{Id: id, Session: &pb.SessionResponse{Id: id, Values: values}})
+	}
+	return res, nil
+}
+
+func (c *memoryClient) AddValuesToSession(ctx context.Context, in *pb.AddValuesToSessionMessage, opts ...grpc.CallOption) (*pb.SessionResponse, error) {
+	c.calls["AddValuesToSession"]++
+	if c.saveErr != nil {
+		return nil, c.saveErr
+	}
+	for key, value := range in.Values {
+		c.sessions[in.Id][key] = value
+	}
+	return &pb.SessionResponse{Id: in.Id, Values: c.sessions[in.Id]}, nil
+}
+
+func (c *memoryClient) InvalidateSessionValues(ctx context.Context, in *pb.InvalidateSessionValuesMessage, opts ...grpc.CallOption) (*pb.SuccessMessage, error) {
+	c.calls["InvalidateSessionValues"]++
+	for _, key := range in.Keys {
+		delete(c.sessions[in.Id], key)
+	}
+	return &pb.SuccessMessage{Successfull: true}, nil
+}
+
+func (c *memoryClient) InvalidateSession(ctx context.Context, in *pb.InvalidateSessionMessage, opts ...grpc.CallOption) (*pb.SuccessMessage, error) {
+	c.calls["InvalidateSession"]++
+	delete(c.sessions, in.Id)
+	return &pb.SuccessMessage{Successfull: true}, nil
+}
+
+func serve(m *Middleware, cookie string, h http.HandlerFunc) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cookie != "" {
+		req.AddCookie(&http.Cookie{Name: DefaultCookieName, Value: cookie})
+	}
+	rec := httptest.NewRecorder()
+	m.Handler(h).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareCreatesSessionOnSet(t *testing.T) {
+	c := newMemoryClient()
+	m := NewMiddleware(c, Options{TTL: 60})
+
+	rec := serve(m, "", func(w http.ResponseWriter, r *http.Request) {
+		session := FromContext(r.Context())
+		if _, ok := session.GetString("name"); ok {
+			t.Errorf("New session has a value")
+		}
+		session.SetString("name", "foo")
+		session.SetNumber("visits", 1)
+	})
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != "s1" || !cookies[0].Secure || !cookies[0].HttpOnly {
+		t.Fatalf("Got cookies %v", cookies)
+	}
+	if c.calls["AddValuesToSession"] != 1 {
+		t.Errorf("Values are not flushed in one call: %v", c.calls)
+	}
+	if c.sessions["s1"]["name"].GetStringValue() != "foo" {
+		t.Errorf("Got session %v", c.sessions["s1"])
+	}
+}
+
+func TestMiddlewareLoadsLazily(t *testing.T) {
+	c := newMemoryClient()
+	c.sessions["s1"] = map[string]*st.Value{"visits": {Kind: &st.Value_NumberValue{NumberValue: 1}}}
+	m := NewMiddleware(c, Options{})
+
+	serve(m, "s1", func(w http.ResponseWriter, r *http.Request) {})
+	if c.calls["GetSessions"] != 0 {
+		t.Errorf("Unused session was loaded")
+	}
+
+	serve(m, "s1", func(w http.ResponseWriter, r *http.Request) {
+		session := FromContext(r.Context())
+		visits, _ := session.GetNumber("visits")
+		session.SetNumber("visits", visits+1)
+		session.Delete("missing")
+	})
+	if got := c.sessions["s1"]["visits"].GetNumberValue(); got != 2 {
+		t.Errorf("Got visits %v", got)
+	}
+	if c.calls["GetSessions"] != 1 || c.calls["CreateSession"] != 0 {
+		t.Errorf("Got calls %v", c.calls)
+	}
+}
+
+func TestMiddlewareUnknownSession(t *testing.T) {
+	c := newMemoryClient()
+	m := NewMiddleware(c, Options{})
+
+	rec := serve(m, "expired", func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).SetBool("ok", true)
+	})
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != "s1" {
+		t.Errorf("Unknown session is not replaced: %v", cookies)
+	}
+}
+
+func TestMiddlewareClearsStaleCookie(t *testing.T) {
+	c := newMemoryClient()
+	m := NewMiddleware(c, Options{})
+
+	rec := serve(m, "expired", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := FromContext(r.Context()).GetString("name"); ok {
+			t.Errorf("Unknown session has a value")
+		}
+	})
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("Stale cookie is not cleared: %v", cookies)
+	}
+}
+
+func TestMiddlewareInvalidToken(t *testing.T) {
+	c := newMemoryClient()
+	m := NewMiddleware(c, Options{})
+
+	rec := serve(m, "invalid", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := FromContext(r.Context()).GetString("name"); ok {
+			t.Errorf("Invalid session has a value")
+		}
+	})
+	cookies := rec.Result().Cookies()
+	if rec.Code != http.StatusOK || len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("Invalid cookie is not cleared: %d %v", rec.Code, cookies)
+	}
+
+	rec = serve(m, "invalid", func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).SetBool("ok", true)
+	})
+	cookies = rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != "s1" {
+		t.Errorf("Invalid session is not replaced: %v", cookies)
+	}
+}
+
+func TestMiddlewareFlushesBeforeResponse(t *testing.T) {
+	c := newMemoryClient()
+	c.sessions["s1"] = map[string]*st.Value{}
+	m := NewMiddleware(c, Options{})
+
+	serve(m, "s1", func(w http.ResponseWriter, r *http.Request) {
+		session := FromContext(r.Context())
+		session.SetString("name", "foo")
+		w.Write([]byte("ok"))
+		if c.sessions["s1"]["name"].GetStringValue() != "foo" {
+			t.Errorf("Session is not saved before the response")
+		}
+
+		if err := session.SetString("name", "bar"); err != nil {
+			t.Errorf("Got unexpected error: %v", err)
+		}
+		if c.sessions["s1"]["name"].GetStringValue() != "bar" {
+			t.Errorf("Change after the response is not written through")
+		}
+	})
+
+	serve(m, "", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if err := FromContext(r.Context()).SetString("name", "foo"); err != ErrResponseWritten {
+			t.Errorf("Got error %v, want ErrResponseWritten", err)
+		}
+	})
+	if c.calls["CreateSession"] != 0 {
+		t.Errorf("Session is created after the response")
+	}
+}
+
+func TestMiddlewareSaveError(t *testing.T) {
+	c := newMemoryClient()
+	c.sessions["s1"] = map[string]*st.Value{}
+	c.saveErr = errors.New("unavailable")
+	var got error
+	m := NewMiddleware(c, Options{ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+		got = err
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}})
+
+	rec := serve(m, "s1", func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).SetString("name", "foo")
+		if _, err := w.Write([]byte("saved")); err == nil {
+			t.Errorf("Got no error of the write")
+		}
+	})
+
+	if got != c.saveErr || rec.Code != http.StatusServiceUnavailable || rec.Body.Len() != 0 {
+		t.Errorf("Got error %v, status %d, body %q", got, rec.Code, rec.Body)
+	}
+}
+
+func TestMiddlewareInvalidate(t *testing.T) {
+	c := newMemoryClient()
+	c.sessions["s1"] = map[string]*st.Value{}
+	m := NewMiddleware(c, Options{})
+
+	rec := serve(m, "s1", func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Invalidate()
+	})
+
+	if _, ok := c.sessions["s1"]; ok {
+		t.Errorf("Session is not invalidated")
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("Cookie is not expired: %v", cookies)
+	}
+}
+
+func TestSessionEncodeDecode(t *testing.T) {
+	c := newMemoryClient()
+	m := NewMiddleware(c, Options{})
+	type cart struct {
+		Items []string `json:"items"`
+	}
+
+	serve(m, "", func(w http.ResponseWriter, r *http.Request) {
+		session := FromContext(r.Context())
+		if err := session.Encode("cart", cart{Items: []string{"a", "b"}}); err != nil {
+			t.Fatalf("Encode got unexpected error: %v", err)
+		}
+		var got cart
+		ok, err := session.Decode("cart", &got)
+		if !ok || err != nil || len(got.Items) != 2 {
+			t.Errorf("Decode got %v, %v, %v", got, ok, err)
+		}
+	})
+}
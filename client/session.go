@@ -0,0 +1,342 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/jsonpb"
+	st "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/hobord/dsession/session"
+)
+
+// ErrNoSession is returned when the request has no session and it cannot be created
+var ErrNoSession = errors.New("No session")
+
+// ErrResponseWritten is returned when a session would be created after the response
+// is written, its cookie could not be sent anymore
+var ErrResponseWritten = errors.New("The response is already written, the session cannot be created")
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx which carries the session
+func NewContext(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext returns the session of the context, nil if there is none
+func FromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(contextKey{}).(*Session)
+	return s
+}
+
+// Session is the lazily loaded session of a request. The changes are buffered
+// and written with Flush, after the HTTP response is written they are written through.
+type Session struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	client  pb.DSessionServiceClient
+	id      string
+	loaded  bool
+	values  map[string]*st.Value
+	dirty   map[string]*st.Value
+	deleted map[string]bool
+
+	// resolver caches the loaded sessions, nil = no caching
+	resolver *Resolver
+	// create makes a new session when a value is set without one
+	create func() (string, error)
+	// invalidated is called after the session is invalidated
+	invalidated func()
+	// missing is called when the session of the id is not found
+	missing func()
+	// writeThrough writes the changes immediately instead of buffering them
+	writeThrough bool
+}
+
+// NewSession returns a session handle of the id, an empty id means no session yet
+func NewSession(ctx context.Context, client pb.DSessionServiceClient, id string) *Session {
+	return &Session{
+		ctx:     ctx,
+		client:  client,
+		id:      id,
+		dirty:   make(map[string]*st.Value),
+		deleted: make(map[string]bool),
+	}
+}
+
+// ID returns the session id, empty if the request has no session
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// load reads the values of the session once, a missing session is dropped
+func (s *Session) load() error {
+	if s.loaded || s.id == "" {
+		return nil
+	}
+	var values map[string]*st.Value
+	var found bool
+	var err error
+	if s.resolver != nil {
+		values, found, err = s.resolver.fetch(s.ctx, s.id)
+	} else {
+		values, found, err = fetchSession(s.ctx, s.client, s.id)
+	}
+	if err != nil {
+		return err
+	}
+	s.loaded = true
+	if !found {
+		if s.missing != nil {
+			s.missing()
+		}
+		s.id = ""
+		return nil
+	}
+	s.values = values
+	return nil
+}
+
+// fetchSession reads the values of the session, found is false if it does not exist or the id
+// is not a valid token (signed with a retired key, tampered with or unsigned)
+func fetchSession(ctx context.Context, client pb.DSessionServiceClient, id string) (map[string]*st.Value, bool, error) {
+	res, err := client.GetSessions(ctx, &pb.GetSessionsMessage{Ids: []string{id}})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(res.Results) != 1 {
+		return nil, false, nil
+	}
+	if code := codes.Code(res.Results[0].Code); code == codes.NotFound || code == codes.InvalidArgument {
+		return nil, false, nil
+	}
+	if result := res.Results[0]; result.Code != 0 {
+		return nil, false, errors.New(result.Error)
+	}
+	return res.Results[0].Session.Values, true, nil
+}
+
+// Get returns the value of the key, nil if it is not set
+func (s *Session) Get(key string) (*st.Value, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.dirty[key]; ok {
+		return v, nil
+	}
+	if s.deleted[key] {
+		return nil, nil
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s.values[key], nil
+}
+
+// GetString returns the string value of the key
+func (s *Session) GetString(key string) (string, bool) {
+	v, err := s.Get(key)
+	if err != nil || v == nil {
+		return "", false
+	}
+	str, ok := v.Kind.(*st.Value_StringValue)
+	if !ok {
+		return "", false
+	}
+	return str.StringValue, true
+}
+
+// GetNumber returns the number value of the key
+func (s *Session) GetNumber(key string) (float64, bool) {
+	v, err := s.Get(key)
+	if err != nil || v == nil {
+		return 0, false
+	}
+	num, ok := v.Kind.(*st.Value_NumberValue)
+	if !ok {
+		return 0, false
+	}
+	return num.NumberValue, true
+}
+
+// GetBool returns the bool value of the key
+func (s *Session) GetBool(key string) (bool, bool) {
+	v, err := s.Get(key)
+	if err != nil || v == nil {
+		return false, false
+	}
+	b, ok := v.Kind.(*st.Value_BoolValue)
+	if !ok {
+		return false, false
+	}
+	return b.BoolValue, true
+}
+
+// Decode unmarshals the value of the key into out as JSON, it returns false if the key is not set
+func (s *Session) Decode(key string, out interface{}) (bool, error) {
+	v, err := s.Get(key)
+	if err != nil || v == nil {
+		return false, err
+	}
+	data, err := (&jsonpb.Marshaler{}).MarshalToString(v)
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal([]byte(data), out)
+}
+
+// Set changes the value of the key, a session is created if the request has none
+func (s *Session) Set(key string, value *st.Value) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ensure(); err != nil {
+		return err
+	}
+	s.dirty[key] = value
+	delete(s.deleted, key)
+	if s.writeThrough {
+		return s.flush()
+	}
+	return nil
+}
+
+// SetString changes the key to a string value
+func (s *Session) SetString(key, value string) error {
+	return s.Set(key, &st.Value{Kind: &st.Value_StringValue{StringValue: value}})
+}
+
+// SetNumber changes the key to a number value
+func (s *Session) SetNumber(key string, value float64) error {
+	return s.Set(key, &st.Value{Kind: &st.Value_NumberValue{NumberValue: value}})
+}
+
+// SetBool changes the key to a bool value
+func (s *Session) SetBool(key string, value bool) error {
+	return s.Set(key, &st.Value{Kind: &st.Value_BoolValue{BoolValue: value}})
+}
+
+// Encode changes the key to the JSON representation of value
+func (s *Session) Encode(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	v := &st.Value{}
+	if err := jsonpb.UnmarshalString(string(data), v); err != nil {
+		return err
+	}
+	return s.Set(key, v)
+}
+
+// Delete removes the key from the session
+func (s *Session) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.dirty, key)
+	if s.id == "" {
+		return nil
+	}
+	s.deleted[key] = true
+	if s.writeThrough {
+		return s.flush()
+	}
+	return nil
+}
+
+// Invalidate deletes the whole session
+func (s *Session) Invalidate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.id != "" {
+		if _, err := s.client.InvalidateSession(s.ctx, &pb.InvalidateSessionMessage{Id: s.id}); err != nil {
+			return err
+		}
+		s.resolver.forget(s.id)
+	}
+	s.id = ""
+	s.loaded = true
+	s.values = nil
+	s.dirty = make(map[string]*st.Value)
+	s.deleted = make(map[string]bool)
+	if s.invalidated != nil {
+		s.invalidated()
+	}
+	return nil
+}
+
+// ensure creates the session if the request has none
+func (s *Session) ensure() error {
+	if err := s.load(); err != nil {
+		return err
+	}
+	if s.id != "" {
+		return nil
+	}
+	if s.create == nil {
+		return ErrNoSession
+	}
+	id, err := s.create()
+	if err != nil {
+		return err
+	}
+	s.id = id
+	s.values = make(map[string]*st.Value)
+	return nil
+}
+
+// Flush writes the buffered changes, the new values in one AddValuesToSession call
+func (s *Session) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush()
+}
+
+// startWriteThrough writes the buffered changes and the later changes immediately
+func (s *Session) startWriteThrough() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeThrough = true
+	return s.flush()
+}
+
+func (s *Session) flush() error {
+	if s.id == "" {
+		return nil
+	}
+
+	if len(s.deleted) > 0 {
+		keys := make([]string, 0, len(s.deleted))
+		for key := range s.deleted {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		_, err := s.client.InvalidateSessionValues(s.ctx, &pb.InvalidateSessionValuesMessage{Id: s.id, Keys: keys})
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			delete(s.values, key)
+		}
+		s.deleted = make(map[string]bool)
+		s.resolver.forget(s.id)
+	}
+
+	if len(s.dirty) > 0 {
+		res, err := s.client.AddValuesToSession(s.ctx, &pb.AddValuesToSessionMessage{Id: s.id, Values: s.dirty})
+		if err != nil {
+			return err
+		}
+		s.values = res.Values
+		s.loaded = true
+		s.dirty = make(map[string]*st.Value)
+		s.resolver.store(s.id, res.Values)
+	}
+
+	return nil
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	st "github.com/golang/protobuf/ptypes/struct"
+
+	pb "github.com/hobord/dsession/session"
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// parseValue reads a JSON value, a text which is not valid JSON is a string
+func parseValue(text string) *st.Value {
+	v := &st.Value{}
+	if err := jsonpb.UnmarshalString(text, v); err != nil {
+		return &st.Value{Kind: &st.Value_StringValue{StringValue: text}}
+	}
+	return v
+}
+
+// parseAssignments reads the key=value arguments
+func parseAssignments(args []string) (map[string]*st.Value, error) {
+	values := make(map[string]*st.Value)
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("Invalid assignment %q, want key=value", arg)
+		}
+		values[arg[:i]] = parseValue(arg[i+1:])
+	}
+	return values, nil
+}
+
+func cmdCreate(c *cli, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	ttl := fs.Int64("ttl", 0, "ttl in seconds, 0 = no expiry")
+	namespace := fs.String("namespace", "", "namespace of the session")
+	fs.Parse(args)
+	values, err := parseAssignments(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call()
+	defer cancel()
+	res, err := c.session.CreateSession(ctx, &pb.CreateSessionMessage{Ttl: *ttl, Namespace: *namespace})
+	if err != nil {
+		return err
+	}
+	if len(values) > 0 {
+		res, err = c.session.AddValuesToSession(ctx, &pb.AddValuesToSessionMessage{Id: res.Id, Values: values})
+		if err != nil {
+			return err
+		}
+	}
+	return c.out.session(res)
+}
+
+func cmdGet(c *cli, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("Usage: get <id> ...")
+	}
+	ctx, cancel := c.call()
+	defer cancel()
+	if len(args) == 1 {
+		res, err := c.session.GetSession(ctx, &pb.GetSessionMessage{Id: args[0]})
+		if err != nil {
+			return err
+		}
+		return c.out.session(res)
+	}
+	res, err := c.session.GetSessions(ctx, &pb.GetSessionsMessage{Ids: args})
+	if err != nil {
+		return err
+	}
+	return c.out.results(res)
+}
+
+func cmdSet(c *cli, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("Usage: set <id> key=value ...")
+	}
+	values, err := parseAssignments(args[1:])
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.call()
+	defer cancel()
+	res, err := c.session.AddValuesToSession(ctx, &pb.AddValuesToSessionMessage{Id: args[0], Values: values})
+	if err != nil {
+		return err
+	}
+	return c.out.session(res)
+}
+
+func cmdDelete(c *cli, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("Usage: delete <id> [key ...]")
+	}
+	ctx, cancel := c.call()
+	defer cancel()
+	if len(args) == 1 {
+		if _, err := c.session.InvalidateSession(ctx, &pb.InvalidateSessionMessage{Id: args[0]}); err != nil {
+			return err
+		}
+		return c.out.done("Session " + args[0] + " invalidated")
+	}
+	_, err := c.session.InvalidateSessionValues(ctx, &pb.InvalidateSessionValuesMessage{Id: args[0], Keys: args[1:]})
+	if err != nil {
+		return err
+	}
+	return c.out.done("Deleted " + strings.Join(args[1:], ", "))
+}
+
+func cmdRenew(c *cli, args []string) error {
+	fs := flag.NewFlagSet("renew", flag.ExitOnError)
+	ttl := fs.Int64("ttl", 0, "new ttl in seconds, 0 = no expiry")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("Usage: renew -ttl <seconds> <id>")
+	}
+	ctx, cancel := c.call()
+	defer cancel()
+	res, err := c.session.RenewSession(ctx, &pb.RenewSessionMessage{Id: fs.Arg(0), Ttl: *ttl})
+	if err != nil {
+		return err
+	}
+	return c.out.session(res)
+}
+
+// filterFlags registers the SessionFilter flags on the flag set
+func filterFlags(fs *flag.FlagSet) func() (*pb.SessionFilter, error) {
+	namespace := fs.String("namespace", "", "only sessions of the namespace")
+	createdBefore := fs.Int64("created-before", 0, "only sessions created before the unix timestamp")
+	var has, where stringList
+	fs.Var(&has, "has", "only sessions with the key, repeatable")
+	fs.Var(&where, "where", "only sessions where key=value, repeatable")
+	return func() (*pb.SessionFilter, error) {
+		equals, err := parseAssignments(where)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.SessionFilter{
+			Namespace:     *namespace,
+			HasKeys:       has,
+			ValueEquals:   equals,
+			CreatedBefore: *createdBefore,
+		}, nil
+	}
+}
+
+// listSessions calls fn with every page of the sessions matching the filter
+func (c *cli) listSessions(filter *pb.SessionFilter, limit int32, all bool, fn func(page *pb.ListSessionsResponse, first bool) error) error {
+	in := &pb.ListSessionsMessage{Filter: filter, Limit: limit}
+	for first := true; ; first = false {
+		ctx, cancel := c.call()
+		res, err := c.admin.ListSessions(ctx, in)
+		cancel()
+		if err != nil {
+			return err
+		}
+		if err := fn(res, first); err != nil {
+			return err
+		}
+		if res.Cursor == "" || !all {
+			return nil
+		}
+		in.Cursor = res.Cursor
+	}
+}
+
+func cmdList(c *cli, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	filter := filterFlags(fs)
+	limit := fs.Int("limit", 100, "page size")
+	cursor := fs.String("cursor", "", "cursor of the page")
+	all := fs.Bool("all", false, "list every page")
+	fs.Parse(args)
+	f, err := filter()
+	if err != nil {
+		return err
+	}
+
+	if *cursor != "" {
+		ctx, cancel := c.call()
+		defer cancel()
+		res, err := c.admin.ListSessions(ctx, &pb.ListSessionsMessage{Filter: f, Limit: int32(*limit), Cursor: *cursor})
+		if err != nil {
+			return err
+		}
+		return c.printPage(res, true, false)
+	}
+	return c.listSessions(f, int32(*limit), *all, func(res *pb.ListSessionsResponse, first bool) error {
+		return c.printPage(res, first, *all)
+	})
+}
+
+func (c *cli) printPage(res *pb.ListSessionsResponse, first, all bool) error {
+	if err := c.out.summaries(res.Sessions, first); err != nil {
+		return err
+	}
+	if res.Cursor != "" && !all && !c.out.json {
+		fmt.Fprintf(os.Stderr, "More sessions: -cursor %s\n", res.Cursor)
+	}
+	return nil
+}
+
+func cmdWatch(c *cli, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Usage: watch <id>")
+	}
+	stream, err := c.session.WatchSession(c.ctx, &pb.WatchSessionMessage{Id: args[0]})
+	if err != nil {
+		return err
+	}
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := c.out.event(ev, ""); err != nil {
+			return err
+		}
+	}
+}
+
+func cmdEvents(c *cli, args []string) error {
+	fs := flag.NewFlagSet("events", flag.ExitOnError)
+	group := fs.String("group", "", "consumer group, empty = read without acknowledgement")
+	consumer := fs.String("consumer", "dsessionctl", "consumer name in the group")
+	start := fs.String("start", "", "stream id to start after")
+	types := fs.String("types", "", "comma separated event types, empty = all")
+	ack := fs.Bool("ack", false, "acknowledge the printed events in the group")
+	fs.Parse(args)
+
+	in := &pb.SubscribeEventsMessage{Group: *group, Consumer: *consumer, StartId: *start}
+	if *types != "" {
+		for _, name := range strings.Split(*types, ",") {
+			t, ok := pb.SessionEventType_value[strings.TrimSpace(name)]
+			if !ok {
+				return fmt.Errorf("Unknown event type %s", name)
+			}
+			in.Types = append(in.Types, pb.SessionEventType(t))
+		}
+	}
+	stream, err := c.admin.SubscribeEvents(c.ctx, in)
+	if err != nil {
+		return err
+	}
+	for {
+		rec, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := c.out.event(rec.Event, rec.StreamId); err != nil {
+			return err
+		}
+		if *ack && *group != "" {
+			ctx, cancel := c.call()
+			_, err := c.admin.AckEvents(ctx, &pb.AckEventsMessage{Group: *group, StreamIds: []string{rec.StreamId}})
+			cancel()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return os.Stdout, nil
+	}
+	return os.Create(path)
+}
+
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return os.Stdin, nil
+	}
+	return os.Open(path)
+}
+
+// cmdExport writes the sessions as JSON Lines, one ExportedSession per line
+func cmdExport(c *cli, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	filter := filterFlags(fs)
+	file := fs.String("file", "-", "output file, - = stdout")
+	fs.Parse(args)
+	f, err := filter()
+	if err != nil {
+		return err
+	}
+	out, err := openOutput(*file)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+
+	stream, err := c.admin.ExportSessions(c.ctx, &pb.ExportSessionsMessage{Filter: f})
+	if err != nil {
+		return err
+	}
+	count := 0
+	for {
+		session, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		line, err := marshaler.MarshalToString(session)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, line)
+		count++
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d sessions\n", count)
+	return nil
+}
+
+// cmdImport streams the sessions of an export file to the server, the ids are kept
+func cmdImport(c *cli, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("file", "-", "input file, - = stdin")
+	policy := fs.String("conflict", "skip", "existing sessions: skip, overwrite or merge")
+	fs.Parse(args)
+	p, ok := pb.ConflictPolicy_value["CONFLICT_"+strings.ToUpper(*policy)]
+	if !ok {
+		return fmt.Errorf("Unknown conflict policy %s", *policy)
+	}
+	in, err := openInput(*file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	stream, err := c.admin.ImportSessions(c.ctx)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		session := &pb.ExportedSession{}
+		if err := jsonpb.UnmarshalString(scanner.Text(), session); err != nil {
+			stream.CloseSend()
+			return fmt.Errorf("Line %d: %s", line, err)
+		}
+		if err := stream.Send(&pb.ImportSessionMessage{Session: session, Policy: pb.ConflictPolicy(p)}); err != nil {
+			// the server closed the stream, CloseAndRecv returns its error
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		stream.CloseSend()
+		return err
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return c.out.imported(res)
+}
+
+func cmdReencrypt(c *cli, args []string) error {
+	fs := flag.NewFlagSet("reencrypt", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only count the sessions with a data key or values of an old key")
+	fs.Parse(args)
+	ctx, cancel := c.call()
+	defer cancel()
+	res, err := c.admin.StartReencryption(ctx, &pb.ReencryptionMessage{DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+	return c.out.job(res)
+}
+
+func cmdJob(c *cli, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("Usage: job <id>")
+	}
+	ctx, cancel := c.call()
+	defer cancel()
+	res, err := c.admin.GetJob(ctx, &pb.GetJobMessage{Id: args[0]})
+	if err != nil {
+		return err
+	}
+	return c.out.job(res)
+}
+
+func cmdAudit(c *cli, args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	session := fs.String("session", "", "records of the session")
+	client := fs.String("client", "", "records of the client")
+	since := fs.Duration("since", 0, "records of this period, e.g. 24h")
+	limit := fs.Int("limit", 100, "page size")
+	cursor := fs.String("cursor", "", "cursor of the page")
+	fs.Parse(args)
+
+	in := &pb.AuditQueryMessage{SessionId: *session, Client: *client, Limit: int32(*limit), Cursor: *cursor}
+	if *since > 0 {
+		in.Since = time.Now().Add(-*since).UnixNano() / int64(time.Millisecond)
+	}
+	ctx, cancel := c.call()
+	defer cancel()
+	res, err := c.admin.QueryAuditLog(ctx, in)
+	if err != nil {
+		return err
+	}
+	return c.out.audit(res)
+}
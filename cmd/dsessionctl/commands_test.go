@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	st "github.com/golang/protobuf/ptypes/struct"
+
+	pb "github.com/hobord/dsession/session"
+)
+
+func TestParseAssignments(t *testing.T) {
+	values, err := parseAssignments([]string{"n=15", "s=\"x\"", "raw=hello", "obj={\"a\":true}", "empty="})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if values["n"].GetNumberValue() != 15 {
+		t.Errorf("Got n %v", values["n"])
+	}
+	if values["s"].GetStringValue() != "x" || values["raw"].GetStringValue() != "hello" {
+		t.Errorf("Got strings %v, %v", values["s"], values["raw"])
+	}
+	if !values["obj"].GetStructValue().Fields["a"].GetBoolValue() {
+		t.Errorf("Got obj %v", values["obj"])
+	}
+	if _, ok := values["empty"].Kind.(*st.Value_StringValue); !ok {
+		t.Errorf("Got empty %v", values["empty"])
+	}
+
+	if _, err := parseAssignments([]string{"=1"}); err == nil {
+		t.Errorf("Assignment without key is accepted")
+	}
+}
+
+func TestPrinterSession(t *testing.T) {
+	res := &pb.SessionResponse{Id: "s1", Values: map[string]*st.Value{
+		"b": {Kind: &st.Value_NumberValue{NumberValue: 2}},
+		"a": {Kind: &st.Value_StringValue{StringValue: "x"}},
+	}}
+
+	var buf bytes.Buffer
+	p, _ := newPrinter(&buf, "table")
+	p.session(res)
+	want := "ID: s1\nKEY  VALUE\na    \"x\"\nb    2\n"
+	if buf.String() != want {
+		t.Errorf("Got table %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	p, _ = newPrinter(&buf, "json")
+	p.session(res)
+	if !strings.HasPrefix(buf.String(), `{"id":"s1","values":{`) || strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("Got json %q", buf.String())
+	}
+
+	if _, err := newPrinter(&buf, "xml"); err == nil {
+		t.Errorf("Unknown format is accepted")
+	}
+}
@@ -0,0 +1,175 @@
+// Command dsessionctl is the command-line admin tool of the DSessionService.
+//
+//	dsessionctl [flags] <command> [arguments]
+//
+//	create [-ttl 3600] [-namespace shop] [key=value ...]
+//	get <id> ...
+//	set <id> key=value ...
+//	delete <id> [key ...]            without keys the whole session is invalidated
+//	renew -ttl 3600 <id>
+//	list [-namespace shop] [-has key] [-where key=value] [-created-before ts] [-limit 100] [-all]
+//	watch <id>
+//	events [-group g -consumer c] [-types SESSION_CREATED,...]
+//	export [-file sessions.jsonl] [-namespace shop]   one session per line with its remaining ttl
+//	import [-file sessions.jsonl] [-conflict skip|overwrite|merge]
+//	reencrypt [-dry-run]              encrypts every value with the current key in a background job
+//	job <id>
+//	audit [-session id] [-client name] [-since 24h] [-limit 100]
+//
+// The values are JSON, a value which is not valid JSON is taken as a string.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/hobord/dsession/session"
+)
+
+// command runs a subcommand with its arguments
+type command func(c *cli, args []string) error
+
+var commands = map[string]command{
+	"create":    cmdCreate,
+	"get":       cmdGet,
+	"set":       cmdSet,
+	"delete":    cmdDelete,
+	"renew":     cmdRenew,
+	"list":      cmdList,
+	"watch":     cmdWatch,
+	"events":    cmdEvents,
+	"export":    cmdExport,
+	"import":    cmdImport,
+	"reencrypt": cmdReencrypt,
+	"job":       cmdJob,
+	"audit":     cmdAudit,
+}
+
+// cli holds the clients and the output settings of a run
+type cli struct {
+	ctx     context.Context
+	session pb.DSessionServiceClient
+	admin   pb.DSessionAdminServiceClient
+	out     *printer
+	timeout time.Duration
+}
+
+// call returns the context of a unary call
+func (c *cli) call() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.ctx, c.timeout)
+}
+
+func main() {
+	addr := flag.String("addr", envOr("DSESSION_ADDR", "localhost:50051"), "address of the DSessionService")
+	output := flag.String("o", "table", "output format: table or json")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of the unary calls")
+	useTLS := flag.Bool("tls", false, "connect with TLS")
+	caFile := flag.String("ca", "", "CA certificate of the server, implies -tls")
+	certFile := flag.String("cert", "", "client certificate for mTLS, implies -tls")
+	keyFile := flag.String("key", "", "client key for mTLS")
+	serverName := flag.String("server-name", "", "server name to verify, defaults to the host of -addr")
+	skipVerify := flag.Bool("insecure-skip-verify", false, "do not verify the server certificate")
+	apiKey := flag.String("api-key", os.Getenv("DSESSION_API_KEY"), "API key of the calls")
+	token := flag.String("token", os.Getenv("DSESSION_TOKEN"), "JWT bearer token of the calls")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	run, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %s\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	out, err := newPrinter(os.Stdout, *output)
+	if err != nil {
+		fatal(err)
+	}
+
+	opt := grpc.WithInsecure()
+	if *useTLS || *caFile != "" || *certFile != "" {
+		config, err := tlsConfig(*caFile, *certFile, *keyFile, *serverName, *skipVerify)
+		if err != nil {
+			fatal(err)
+		}
+		opt = grpc.WithTransportCredentials(credentials.NewTLS(config))
+	}
+	conn, err := grpc.Dial(*addr, opt)
+	if err != nil {
+		fatal(err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	if *apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", *apiKey)
+	}
+	if *token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+*token)
+	}
+	c := &cli{
+		ctx:     ctx,
+		session: pb.NewDSessionServiceClient(conn),
+		admin:   pb.NewDSessionAdminServiceClient(conn),
+		out:     out,
+		timeout: *timeout,
+	}
+	if err := run(c, flag.Args()[1:]); err != nil {
+		fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: dsessionctl [flags] <command> [arguments]\n\n")
+	fmt.Fprintf(os.Stderr, "Commands: create, get, set, delete, renew, list, watch, events, export, import, reencrypt, job, audit\n")
+	fmt.Fprintf(os.Stderr, "Run dsessionctl <command> -h for the arguments of a command.\n\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "dsessionctl: %s\n", err)
+	os.Exit(1)
+}
+
+func envOr(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// tlsConfig builds the client TLS configuration of the flags
+func tlsConfig(caFile, certFile, keyFile, serverName string, skipVerify bool) (*tls.Config, error) {
+	config := &tls.Config{ServerName: serverName, InsecureSkipVerify: skipVerify}
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificate found in %s", caFile)
+		}
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
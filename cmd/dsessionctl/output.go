@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	proto "github.com/golang/protobuf/proto"
+	st "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/hobord/dsession/session"
+)
+
+var marshaler = &jsonpb.Marshaler{}
+
+// printer writes the results as tables or as one JSON document per line
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case "table":
+		return &printer{w: w}, nil
+	case "json":
+		return &printer{w: w, json: true}, nil
+	default:
+		return nil, fmt.Errorf("Unknown output format %s", format)
+	}
+}
+
+// message writes msg as a JSON line
+func (p *printer) message(msg proto.Message) error {
+	data, err := marshaler.MarshalToString(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(p.w, data)
+	return err
+}
+
+func (p *printer) table(header ...string) *tabwriter.Writer {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	return tw
+}
+
+// session writes the id and the values of a session
+func (p *printer) session(res *pb.SessionResponse) error {
+	if p.json {
+		return p.message(res)
+	}
+	fmt.Fprintf(p.w, "ID: %s\n", res.Id)
+	tw := p.table("KEY", "VALUE")
+	for _, key := range sortedKeys(res.Values) {
+		fmt.Fprintf(tw, "%s\t%s\n", key, formatValue(res.Values[key]))
+	}
+	return tw.Flush()
+}
+
+// results writes the results of GetSessions
+func (p *printer) results(res *pb.SessionsResponse) error {
+	if p.json {
+		return p.message(res)
+	}
+	for i, result := range res.Results {
+		if i > 0 {
+			fmt.Fprintln(p.w)
+		}
+		if result.Code != 0 {
+			fmt.Fprintf(p.w, "ID: %s\nError: %s\n", result.Id, result.Error)
+			continue
+		}
+		if err := p.session(result.Session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// summaries writes a page of ListSessions
+func (p *printer) summaries(sessions []*pb.SessionSummary, header bool) error {
+	if p.json {
+		for _, summary := range sessions {
+			if err := p.message(summary); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	if header {
+		fmt.Fprintln(tw, "ID\tNAMESPACE\tCREATED\tTTL\tREMAINING\tKEYS")
+	}
+	for _, summary := range sessions {
+		remaining := "-"
+		if summary.RemainingTtl >= 0 {
+			remaining = (time.Duration(summary.RemainingTtl) * time.Second).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			summary.Id, summary.Namespace, formatTime(summary.CreatedAt),
+			summary.Ttl, remaining, strings.Join(summary.Keys, ","))
+	}
+	return tw.Flush()
+}
+
+// event writes a session event, streamID is empty for the WatchSession events
+func (p *printer) event(ev *pb.SessionEvent, streamID string) error {
+	if p.json {
+		if streamID != "" {
+			return p.message(&pb.EventRecord{StreamId: streamID, Event: ev})
+		}
+		return p.message(ev)
+	}
+	line := fmt.Sprintf("%s %s %s", time.Unix(0, ev.Time*int64(time.Millisecond)).Format(time.RFC3339), ev.Type, ev.Id)
+	if streamID != "" {
+		line = streamID + " " + line
+	}
+	if ev.Namespace != "" {
+		line += " namespace=" + ev.Namespace
+	}
+	for _, key := range ev.Keys {
+		if v, ok := ev.Values[key]; ok {
+			line += fmt.Sprintf(" %s=%s", key, formatValue(v))
+		} else {
+			line += " " + key
+		}
+	}
+	if ev.Ttl != 0 {
+		line += fmt.Sprintf(" ttl=%d", ev.Ttl)
+	}
+	_, err := fmt.Fprintln(p.w, line)
+	return err
+}
+
+// imported writes the result of ImportSessions
+func (p *printer) imported(res *pb.ImportSessionsResponse) error {
+	if p.json {
+		return p.message(res)
+	}
+	fmt.Fprintf(p.w, "Received %d, created %d, overwritten %d, merged %d, skipped %d, failed %d\n",
+		res.Received, res.Created, res.Overwritten, res.Merged, res.Skipped, res.Failed)
+	for _, e := range res.Errors {
+		fmt.Fprintf(p.w, "%s: %s\n", e.Id, e.Error)
+	}
+	return nil
+}
+
+// job writes the state of a background job
+func (p *printer) job(res *pb.JobResponse) error {
+	if p.json {
+		return p.message(res)
+	}
+	tw := p.table("ID", "KIND", "STATE", "DRY RUN", "SCANNED", "MATCHED", "DELETED", "UPDATED", "STARTED", "FINISHED")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%d\t%d\t%d\t%d\t%s\t%s\n",
+		res.Id, res.Kind, res.State, res.DryRun, res.Scanned, res.Matched, res.Deleted, res.Updated,
+		formatTime(res.StartedAt), formatTime(res.FinishedAt))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if res.Error != "" {
+		fmt.Fprintf(p.w, "Error: %s\n", res.Error)
+	}
+	return nil
+}
+
+// audit writes a page of audit records
+func (p *printer) audit(res *pb.AuditLogResponse) error {
+	if p.json {
+		return p.message(res)
+	}
+	tw := p.table("TIME", "CLIENT", "SUBJECT", "PEER", "METHOD", "SESSION", "KEYS", "RESULT")
+	for _, rec := range res.Records {
+		method := rec.Method[strings.LastIndex(rec.Method, "/")+1:]
+		result := "OK"
+		if rec.Code != 0 {
+			result = codes.Code(rec.Code).String() + ": " + rec.Error
+		}
+		keys := make([]string, 0, len(rec.Keys))
+		for _, key := range rec.Keys {
+			if v, ok := rec.Values[key]; ok {
+				key += "=" + formatValue(v)
+			}
+			keys = append(keys, key)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			time.Unix(0, rec.Time*int64(time.Millisecond)).UTC().Format(time.RFC3339), rec.Client, rec.Subject,
+			rec.Peer, method, rec.SessionId, strings.Join(keys, ","), result)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if res.Cursor != "" {
+		fmt.Fprintf(p.w, "Next page: -cursor %s\n", res.Cursor)
+	}
+	return nil
+}
+
+// done writes the result of the calls without a response body
+func (p *printer) done(msg string) error {
+	if p.json {
+		return p.message(&pb.SuccessMessage{Successfull: true})
+	}
+	_, err := fmt.Fprintln(p.w, msg)
+	return err
+}
+
+func sortedKeys(values map[string]*st.Value) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatValue(v *st.Value) string {
+	data, err := marshaler.MarshalToString(v)
+	if err != nil {
+		return v.String()
+	}
+	return data
+}
+
+func formatTime(unix int64) string {
+	if unix == 0 {
+		return "-"
+	}
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
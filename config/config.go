@@ -0,0 +1,182 @@
+// Package config loads the settings of the server. The defaults are overridden by the
+// config file (JSON, or YAML by the .yaml/.yml extension), then by the environment variables,
+// then by the command line flags.
+//
+//	dsession -config dsession.yaml -redis.url redis://cache:6379/1 -print-config
+//
+// Every setting has a flag named by its path in the file, like -server.http-addr for
+// server.http_addr, and most have an environment variable, like HTTP_PORT.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration written as "30s" in the config file
+type Duration time.Duration
+
+// parseDuration reads a duration like "1m30s", a plain number is seconds
+func parseDuration(s string) (Duration, error) {
+	if seconds, err := strconv.Atoi(s); err == nil {
+		return Duration(time.Duration(seconds) * time.Second), nil
+	}
+	d, err := time.ParseDuration(s)
+	return Duration(d), err
+}
+
+// UnmarshalJSON reads a duration string or a number of seconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	parsed, err := parseDuration(s)
+	if err != nil {
+		return fmt.Errorf("Invalid duration %s", data)
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// Config is the whole configuration of the server
+type Config struct {
+	Server   Server   `json:"server"`
+	TLS      TLS      `json:"tls"`
+	Redis    Redis    `json:"redis"`
+	Sessions Sessions `json:"sessions"`
+	Auth     Auth     `json:"auth"`
+	Audit    Audit    `json:"audit"`
+	Log      Log      `json:"log"`
+	Tracing  Tracing  `json:"tracing"`
+}
+
+// Server configures the listeners
+type Server struct {
+	GRPCAddr            string   `json:"grpc_addr" env:"PORT" desc:"address of the gRPC server"`
+	HTTPAddr            string   `json:"http_addr" env:"HTTP_PORT" desc:"address of the REST gateway, grpc-web and the probes, empty disables it"`
+	MetricsAddr         string   `json:"metrics_addr" env:"METRICS_PORT" desc:"address of the /metrics and probe listener, empty disables the metrics"`
+	GRPCWeb             bool     `json:"grpc_web" env:"GRPC_WEB" desc:"serve grpc-web on the HTTP address"`
+	CORSAllowedOrigins  []string `json:"cors_allowed_origins" env:"CORS_ALLOWED_ORIGINS" desc:"comma separated origins allowed to call the HTTP API, * for any without credentials"`
+	ShutdownTimeout     Duration `json:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" desc:"drain time of the running calls on SIGTERM"`
+	HealthCheckInterval Duration `json:"health_check_interval" env:"HEALTH_CHECK_INTERVAL" desc:"interval of the redis health checks"`
+}
+
+// TLS configures the certificate of the gRPC server
+type TLS struct {
+	CertFile          string `json:"cert_file" env:"TLS_CERT_FILE" desc:"PEM certificate of the server, enables TLS"`
+	KeyFile           string `json:"key_file" env:"TLS_KEY_FILE" desc:"PEM private key of the certificate"`
+	ClientCAFile      string `json:"client_ca_file" env:"TLS_CLIENT_CA_FILE" desc:"PEM CA bundle of the client certificates"`
+	RequireClientCert bool   `json:"require_client_cert" env:"TLS_REQUIRE_CLIENT_CERT" desc:"reject the gRPC clients without a certificate, the HTTP listener has no TLS and needs auth.policy_file"`
+}
+
+// Redis configures the connection of the redis pool, the url is the base and the other
+// settings override its parts when they are set
+type Redis struct {
+	URL                    string   `json:"url" env:"REDIS_URL" secret:"true" desc:"redis://[[user]:password@]host[:port][/db], rediss:// enables TLS"`
+	Host                   string   `json:"host" env:"REDIS_HOST" desc:"redis host"`
+	Port                   string   `json:"port" env:"REDIS_PORT" desc:"redis port"`
+	Username               string   `json:"username" env:"REDIS_USERNAME" desc:"ACL user"`
+	Password               string   `json:"password" env:"REDIS_PASSWORD" secret:"true" desc:"password"`
+	DB                     int      `json:"db" env:"REDIS_DB" desc:"database number"`
+	TLS                    bool     `json:"tls" env:"REDIS_TLS" desc:"connect with TLS"`
+	TLSCAFile              string   `json:"tls_ca_file" env:"REDIS_TLS_CA_FILE" desc:"PEM CA bundle of the redis server, enables TLS"`
+	TLSCertFile            string   `json:"tls_cert_file" env:"REDIS_TLS_CERT_FILE" desc:"PEM client certificate, enables TLS"`
+	TLSKeyFile             string   `json:"tls_key_file" env:"REDIS_TLS_KEY_FILE" desc:"PEM private key of the client certificate"`
+	TLSServerName          string   `json:"tls_server_name" env:"REDIS_TLS_SERVER_NAME" desc:"expected name of the server certificate"`
+	TLSInsecureSkipVerify  bool     `json:"tls_insecure_skip_verify" env:"REDIS_TLS_INSECURE_SKIP_VERIFY" desc:"do not verify the server certificate"`
+	ConnectTimeout         Duration `json:"connect_timeout" env:"REDIS_CONNECT_TIMEOUT" desc:"dial timeout"`
+	ReadTimeout            Duration `json:"read_timeout" env:"REDIS_READ_TIMEOUT" desc:"read timeout, 0 is none"`
+	WriteTimeout           Duration `json:"write_timeout" env:"REDIS_WRITE_TIMEOUT" desc:"write timeout, 0 is none"`
+	MaxIdle                int      `json:"max_idle" env:"REDIS_MAXIDLE" desc:"idle connections kept in the pool"`
+	IdleTimeout            Duration `json:"idle_timeout" env:"REDIS_MAXTIMEOUT" desc:"idle connections are closed after this, a number is seconds"`
+	ConfigureNotifications bool     `json:"configure_notifications" env:"REDIS_CONFIGURE_NOTIFICATIONS" desc:"set notify-keyspace-events Ex, needed by the expiry events, on the redis server by CONFIG SET, otherwise it is only checked"`
+}
+
+// Sessions configures the session tokens, the value encryption and the event stream
+type Sessions struct {
+	EventsMaxLen            int64  `json:"events_maxlen" env:"EVENTS_MAXLEN" desc:"approximate length of the event stream, 0 is unlimited"`
+	TokenKeys               string `json:"token_keys" env:"SESSION_TOKEN_KEYS" secret:"true" desc:"kid=base64key list, the first key signs the new session tokens"`
+	TokenRequired           bool   `json:"token_required" env:"SESSION_TOKEN_REQUIRED" desc:"reject the unsigned session ids"`
+	ValueEncryptionKeys     string `json:"value_encryption_keys" env:"VALUE_ENCRYPTION_KEYS" secret:"true" desc:"kid=base64key list of AES master keys, the first key wraps the data keys of the sessions"`
+	ValueEncryptionKeysFile string `json:"value_encryption_keys_file" env:"VALUE_ENCRYPTION_KEYS_FILE" desc:"file of the value encryption keys"`
+}
+
+// Auth configures the authentication, the rate limits and the webhooks
+type Auth struct {
+	PolicyFile     string `json:"policy_file" env:"AUTH_POLICY_FILE" desc:"JSON policy of the clients, enables the authentication"`
+	RateLimitsFile string `json:"rate_limits_file" env:"RATE_LIMITS_CONFIG" desc:"JSON rate limit rules"`
+	WebhooksFile   string `json:"webhooks_file" env:"WEBHOOKS_CONFIG" desc:"JSON webhook subscriptions"`
+}
+
+// Audit configures the audit log of the session mutations
+type Audit struct {
+	Log           string `json:"log" env:"AUDIT_LOG" desc:"redis or file, empty disables the audit log"`
+	File          string `json:"file" env:"AUDIT_FILE" desc:"JSON Lines file of the file audit log"`
+	IncludeValues bool   `json:"include_values" env:"AUDIT_INCLUDE_VALUES" desc:"record the written values sealed by the value encryption keys, not only their keys"`
+	MaxLen        int64  `json:"maxlen" env:"AUDIT_MAXLEN" desc:"approximate length of the redis audit stream, 0 is unlimited"`
+}
+
+// Log configures the logging
+type Log struct {
+	Level      string `json:"level" env:"LOG_LEVEL" desc:"debug, info, warn or error"`
+	Format     string `json:"format" env:"LOG_FORMAT" desc:"json or text"`
+	SessionIDs string `json:"session_ids" env:"LOG_SESSION_IDS" desc:"hash, redact or plain"`
+}
+
+// Tracing configures the span export
+type Tracing struct {
+	Exporter     string            `json:"exporter" env:"TRACING_EXPORTER" desc:"otlp, stdout or file, empty disables the tracing"`
+	ServiceName  string            `json:"service_name" env:"OTEL_SERVICE_NAME" desc:"service name of the spans"`
+	SampleRatio  float64           `json:"sample_ratio" env:"TRACING_SAMPLE_RATIO" desc:"ratio of the new traces recorded"`
+	OTLPEndpoint string            `json:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" desc:"OTLP/HTTP endpoint of the collector"`
+	OTLPHeaders  map[string]string `json:"otlp_headers" env:"OTEL_EXPORTER_OTLP_HEADERS" secret:"true" desc:"comma separated name=value headers of the OTLP requests"`
+	File         string            `json:"file" env:"TRACING_FILE" desc:"JSON Lines file of the file exporter"`
+}
+
+// Default returns the configuration without a file, environment or flags
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			GRPCAddr:            ":50051",
+			ShutdownTimeout:     Duration(30 * time.Second),
+			HealthCheckInterval: Duration(5 * time.Second),
+		},
+		Redis: Redis{
+			ConnectTimeout: Duration(5 * time.Second),
+			MaxIdle:        3,
+			IdleTimeout:    Duration(240 * time.Second),
+		},
+		Sessions: Sessions{EventsMaxLen: 100000},
+		Audit:    Audit{MaxLen: 1000000},
+		Log:      Log{Level: "info", Format: "json", SessionIDs: "hash"},
+		Tracing: Tracing{
+			ServiceName:  "dsession",
+			SampleRatio:  1,
+			OTLPEndpoint: "http://localhost:4318",
+		},
+	}
+}
+
+// splitList splits a comma separated list, ignoring the empty items
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setenv(t *testing.T, env map[string]string) {
+	for name, value := range env {
+		os.Setenv(name, value)
+	}
+	t.Cleanup(func() {
+		for name := range env {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, printConfig, err := Load(nil, ioutil.Discard)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if printConfig {
+		t.Errorf("Got print-config without the flag")
+	}
+	if cfg.Server.GRPCAddr != ":50051" || cfg.Redis.MaxIdle != 3 || cfg.Sessions.EventsMaxLen != 100000 || cfg.Audit.MaxLen != 1000000 {
+		t.Errorf("Got %+v", cfg)
+	}
+	if time.Duration(cfg.Server.ShutdownTimeout) != 30*time.Second || time.Duration(cfg.Redis.IdleTimeout) != 240*time.Second {
+		t.Errorf("Got durations %v %v", cfg.Server.ShutdownTimeout, cfg.Redis.IdleTimeout)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "dsession.yaml", `
+server:
+  http_addr: ":8080"
+  shutdown_timeout: 10s
+redis:
+  url: redis://file:6379/1
+  max_idle: 5
+log:
+  level: debug
+`)
+	setenv(t, map[string]string{
+		"REDIS_MAXIDLE": "7",
+		"LOG_LEVEL":     "warn",
+	})
+
+	cfg, printConfig, err := Load([]string{"-config", file, "-log.level", "error", "-print-config"}, ioutil.Discard)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if !printConfig {
+		t.Errorf("Got no print-config")
+	}
+	if cfg.Server.HTTPAddr != ":8080" || time.Duration(cfg.Server.ShutdownTimeout) != 10*time.Second || cfg.Redis.URL != "redis://file:6379/1" {
+		t.Errorf("File is not applied: %+v", cfg.Server)
+	}
+	if cfg.Redis.MaxIdle != 7 {
+		t.Errorf("Got max_idle %d, the environment overrides the file", cfg.Redis.MaxIdle)
+	}
+	if cfg.Log.Level != "error" {
+		t.Errorf("Got level %s, the flag overrides the environment", cfg.Log.Level)
+	}
+	if cfg.Server.GRPCAddr != ":50051" {
+		t.Errorf("Got grpc_addr %s, the default is kept", cfg.Server.GRPCAddr)
+	}
+}
+
+func TestLoadFileFromEnv(t *testing.T) {
+	file := writeFile(t, "dsession.json", `{"auth": {"policy_file": "policy.json"}, "tracing": {"exporter": "stdout"}}`)
+	setenv(t, map[string]string{FileEnv: file})
+
+	cfg, _, err := Load(nil, ioutil.Discard)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if cfg.Auth.PolicyFile != "policy.json" || cfg.Tracing.Exporter != "stdout" {
+		t.Errorf("Got %+v %+v", cfg.Auth, cfg.Tracing)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	setenv(t, map[string]string{
+		"PORT":                       ":6000",
+		"CORS_ALLOWED_ORIGINS":       "https://a.example.com, https://b.example.com",
+		"GRPC_WEB":                   "true",
+		"HTTP_PORT":                  ":8080",
+		"REDIS_MAXTIMEOUT":           "60",
+		"TRACING_SAMPLE_RATIO":       "0.25",
+		"OTEL_EXPORTER_OTLP_HEADERS": "x-api-key=secret, x-tenant=shop",
+	})
+	cfg, err := FromEnv()
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if cfg.Server.GRPCAddr != ":6000" || !cfg.Server.GRPCWeb || len(cfg.Server.CORSAllowedOrigins) != 2 || cfg.Server.CORSAllowedOrigins[1] != "https://b.example.com" {
+		t.Errorf("Got %+v", cfg.Server)
+	}
+	if time.Duration(cfg.Redis.IdleTimeout) != time.Minute {
+		t.Errorf("Got idle timeout %v, a number is seconds", cfg.Redis.IdleTimeout)
+	}
+	if cfg.Tracing.SampleRatio != 0.25 || cfg.Tracing.OTLPHeaders["x-tenant"] != "shop" {
+		t.Errorf("Got %+v", cfg.Tracing)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		file string
+		want string
+	}{
+		{"invalid env", nil, map[string]string{"REDIS_MAXIDLE": "many"}, "", "REDIS_MAXIDLE"},
+		{"invalid flag", []string{"-server.shutdown-timeout", "soon"}, nil, "", "-server.shutdown-timeout"},
+		{"unknown flag", []string{"-redis.hots", "cache"}, nil, "", "redis.hots"},
+		{"unknown setting", nil, nil, `{"redis": {"hots": "cache"}}`, "hots"},
+		{"invalid duration", nil, nil, `{"server": {"shutdown_timeout": "soon"}}`, "soon"},
+		{"invalid value", nil, map[string]string{"AUDIT_LOG": "syslog"}, "", `audit.log: unknown value "syslog", use redis, file`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setenv(t, tt.env)
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, "dsession.json", tt.file)}, args...)
+			}
+			_, _, err := Load(args, ioutil.Discard)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.TLS.CertFile = "server.pem"
+	cfg.TLS.RequireClientCert = true
+	cfg.Redis.URL = "http://cache"
+	cfg.Server.HTTPAddr = ":8080"
+	cfg.Server.GRPCWeb = true
+	cfg.Server.CORSAllowedOrigins = []string{"*"}
+	cfg.Audit.Log = "file"
+	cfg.Audit.IncludeValues = true
+	cfg.Log.Format = "xml"
+	cfg.Tracing.Exporter = "otlp"
+	cfg.Tracing.OTLPEndpoint = "collector:4318"
+	cfg.Tracing.SampleRatio = 2
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("Got no error")
+	}
+	for _, want := range []string{
+		"tls.key_file: is required with tls.cert_file",
+		"tls.require_client_cert: needs tls.client_ca_file",
+		"tls.require_client_cert: needs auth.policy_file with server.http_addr",
+		"server.cors_allowed_origins: * is not allowed with server.grpc_web",
+		`redis.url: unknown scheme "http"`,
+		"audit.file: is required with audit.log file",
+		"audit.include_values: needs sessions.value_encryption_keys",
+		`log.format: unknown value "xml", use json, text`,
+		"tracing.otlp_endpoint",
+		"tracing.sample_ratio",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Got %v, want %q", err, want)
+		}
+	}
+
+	if err := Default().Validate(); err != nil {
+		t.Errorf("Got error of the defaults: %v", err)
+	}
+
+	cfg = Default()
+	cfg.TLS = TLS{CertFile: "server.pem", KeyFile: "server.key", ClientCAFile: "ca.pem", RequireClientCert: true}
+	cfg.Server.HTTPAddr = ":8080"
+	cfg.Auth.PolicyFile = "policy.json"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Got error of the client certificates with the auth policy: %v", err)
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Redis.URL = "redis://:hunter2@cache:6379"
+	cfg.Sessions.TokenKeys = "k1=c2VjcmV0"
+	cfg.Tracing.OTLPHeaders = map[string]string{"x-api-key": "secret"}
+
+	var buf bytes.Buffer
+	if err := cfg.Print(&buf); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "c2VjcmV0") || strings.Contains(out, `"secret"`) {
+		t.Errorf("Got secrets in %s", out)
+	}
+	if !strings.Contains(out, `"x-api-key": "[redacted]"`) || !strings.Contains(out, `"shutdown_timeout": "30s"`) {
+		t.Errorf("Got %s", out)
+	}
+	if cfg.Redis.URL != "redis://:hunter2@cache:6379" || cfg.Tracing.OTLPHeaders["x-api-key"] != "secret" {
+		t.Errorf("Print changed the configuration")
+	}
+}
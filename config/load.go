@@ -0,0 +1,269 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the config file when the -config flag is not given
+const FileEnv = "DSESSION_CONFIG"
+
+// setting is a field of the configuration, found by its struct tags
+type setting struct {
+	path   string // like redis.url
+	env    string
+	desc   string
+	secret bool
+	value  reflect.Value
+}
+
+// flagName is the command line flag of the setting, like redis.max-idle
+func (s *setting) flagName() string {
+	return strings.Replace(s.path, "_", "-", -1)
+}
+
+// settings lists the fields of the configuration in the order of their declaration
+func (c *Config) settings() []*setting {
+	var list []*setting
+	sections := reflect.ValueOf(c).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Type().Field(i).Tag.Get("json")
+		fields := sections.Field(i)
+		for j := 0; j < fields.NumField(); j++ {
+			tag := fields.Type().Field(j).Tag
+			list = append(list, &setting{
+				path:   section + "." + tag.Get("json"),
+				env:    tag.Get("env"),
+				desc:   tag.Get("desc"),
+				secret: tag.Get("secret") == "true",
+				value:  fields.Field(j),
+			})
+		}
+	}
+	return list
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// set parses the text of a flag or of an environment variable into the setting
+func (s *setting) set(text string) error {
+	v := s.value
+	switch {
+	case v.Type() == durationType:
+		d, err := parseDuration(text)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, use like 30s or 1m", text)
+		}
+		v.Set(reflect.ValueOf(d))
+	case v.Kind() == reflect.String:
+		v.SetString(text)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, use true or false", text)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", text)
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", text)
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice:
+		v.Set(reflect.ValueOf(splitList(text)))
+	case v.Kind() == reflect.Map:
+		m := map[string]string{}
+		for _, item := range splitList(text) {
+			parts := strings.SplitN(item, "=", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("invalid item %q, use name=value", item)
+			}
+			m[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+		v.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// String formats the setting as it is given on the command line
+func (s *setting) String() string {
+	v := s.value
+	switch v.Kind() {
+	case reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	case reflect.Map:
+		var items []string
+		for name, value := range v.Interface().(map[string]string) {
+			items = append(items, name+"="+value)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// flagValue records the flags given, they are applied after the file and the environment
+type flagValue struct {
+	setting *setting
+	given   *[]flagArg
+}
+
+type flagArg struct {
+	setting *setting
+	text    string
+}
+
+func (f flagValue) String() string {
+	if f.setting == nil || f.setting.value.IsZero() {
+		return ""
+	}
+	return f.setting.String()
+}
+
+func (f flagValue) Set(text string) error {
+	*f.given = append(*f.given, flagArg{setting: f.setting, text: text})
+	return nil
+}
+
+// IsBoolFlag lets the boolean settings be given as -server.grpc-web without a value
+func (f flagValue) IsBoolFlag() bool {
+	return f.setting != nil && f.setting.value.Kind() == reflect.Bool
+}
+
+// Load reads the configuration of the command line arguments (without the program name).
+// The defaults are overridden by the config file, then by the environment, then by the flags.
+// printConfig reports the -print-config flag.
+func Load(args []string, output io.Writer) (cfg *Config, printConfig bool, err error) {
+	cfg = Default()
+	settings := cfg.settings()
+
+	fs := flag.NewFlagSet("dsession", flag.ContinueOnError)
+	fs.SetOutput(output)
+	file := fs.String("config", os.Getenv(FileEnv), "YAML or JSON config file, also "+FileEnv)
+	fs.BoolVar(&printConfig, "print-config", false, "print the effective configuration, secrets redacted, and exit")
+	var given []flagArg
+	for _, s := range settings {
+		usage := s.desc
+		if s.env != "" {
+			usage += ", also " + s.env
+		}
+		fs.Var(flagValue{setting: s, given: &given}, s.flagName(), usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+	if fs.NArg() > 0 {
+		return nil, false, fmt.Errorf("Unexpected argument %q", fs.Arg(0))
+	}
+
+	if *file != "" {
+		if err := cfg.loadFile(*file); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, false, err
+	}
+	for _, arg := range given {
+		if err := arg.setting.set(arg.text); err != nil {
+			return nil, false, fmt.Errorf("Invalid flag -%s: %s", arg.setting.flagName(), err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, false, err
+	}
+	return cfg, printConfig, nil
+}
+
+// loadFile reads a YAML (.yaml or .yml) or a JSON config file, unknown settings are rejected
+func (c *Config) loadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read the config file: %s", err)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("Invalid config file %s: %s", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("Invalid config file %s: %s", path, err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil && err != io.EOF {
+		return fmt.Errorf("Invalid config file %s: %s", path, err)
+	}
+	return nil
+}
+
+// loadEnv overrides the settings by the environment variables which are set
+func (c *Config) loadEnv() error {
+	for _, s := range c.settings() {
+		if s.env == "" {
+			continue
+		}
+		if text := os.Getenv(s.env); text != "" {
+			if err := s.set(text); err != nil {
+				return fmt.Errorf("Invalid %s (%s): %s", s.env, s.path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// FromEnv returns the defaults overridden by the environment, without a file or flags
+func FromEnv() (*Config, error) {
+	cfg := Default()
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Print writes the configuration as JSON, the secrets are redacted
+func (c *Config) Print(w io.Writer) error {
+	redacted := *c
+	for _, s := range redacted.settings() {
+		if s.secret && !s.value.IsZero() {
+			switch s.value.Kind() {
+			case reflect.String:
+				s.value.SetString("[redacted]")
+			case reflect.Map:
+				m := map[string]string{}
+				for name := range s.value.Interface().(map[string]string) {
+					m[name] = "[redacted]"
+				}
+				s.value.Set(reflect.ValueOf(m))
+			}
+		}
+	}
+	data, err := json.MarshalIndent(&redacted, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
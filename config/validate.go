@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Validate checks the settings together, the error lists every problem found
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, path, msg string) {
+		if !ok {
+			problems = append(problems, path+": "+msg)
+		}
+	}
+	oneOf := func(value, path string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		problems = append(problems, path+": unknown value "+strconv.Quote(value)+", use "+strings.Join(nonEmpty(allowed), ", "))
+	}
+	address := func(value, path string) {
+		if value == "" {
+			return
+		}
+		_, _, err := net.SplitHostPort(value)
+		check(err == nil, path, "invalid address "+strconv.Quote(value)+", use host:port or :port")
+	}
+
+	check(c.Server.GRPCAddr != "", "server.grpc_addr", "is required")
+	address(c.Server.GRPCAddr, "server.grpc_addr")
+	address(c.Server.HTTPAddr, "server.http_addr")
+	address(c.Server.MetricsAddr, "server.metrics_addr")
+	check(!c.Server.GRPCWeb || c.Server.HTTPAddr != "", "server.grpc_web", "needs server.http_addr")
+	for _, origin := range c.Server.CORSAllowedOrigins {
+		check(!c.Server.GRPCWeb || origin != "*", "server.cors_allowed_origins",
+			"* is not allowed with server.grpc_web, which always allows credentials, list the origins")
+	}
+	check(c.Server.ShutdownTimeout >= 0, "server.shutdown_timeout", "must not be negative")
+	check(c.Server.HealthCheckInterval > 0, "server.health_check_interval", "must be positive")
+
+	check(c.TLS.CertFile == "" || c.TLS.KeyFile != "", "tls.key_file", "is required with tls.cert_file")
+	check(c.TLS.KeyFile == "" || c.TLS.CertFile != "", "tls.cert_file", "is required with tls.key_file")
+	check(c.TLS.ClientCAFile == "" || c.TLS.CertFile != "", "tls.client_ca_file", "needs tls.cert_file")
+	check(!c.TLS.RequireClientCert || c.TLS.ClientCAFile != "", "tls.require_client_cert", "needs tls.client_ca_file")
+	// the REST gateway and grpc-web are served on the plain HTTP listener, their calls carry no
+	// client certificate and only the auth policy keeps the unauthenticated clients out
+	check(!c.TLS.RequireClientCert || c.Server.HTTPAddr == "" || c.Auth.PolicyFile != "", "tls.require_client_cert",
+		"needs auth.policy_file with server.http_addr, the REST gateway and grpc-web calls have no client certificate")
+
+	if c.Redis.URL != "" {
+		u, err := url.Parse(c.Redis.URL)
+		if err != nil {
+			problems = append(problems, "redis.url: invalid url")
+		} else {
+			check(u.Scheme == "redis" || u.Scheme == "rediss", "redis.url", "unknown scheme "+strconv.Quote(u.Scheme)+", use redis or rediss")
+		}
+	}
+	check(c.Redis.DB >= 0, "redis.db", "must not be negative")
+	check(c.Redis.TLSCertFile == "" || c.Redis.TLSKeyFile != "", "redis.tls_key_file", "is required with redis.tls_cert_file")
+	check(c.Redis.ConnectTimeout >= 0, "redis.connect_timeout", "must not be negative")
+	check(c.Redis.ReadTimeout >= 0, "redis.read_timeout", "must not be negative")
+	check(c.Redis.WriteTimeout >= 0, "redis.write_timeout", "must not be negative")
+	check(c.Redis.MaxIdle >= 0, "redis.max_idle", "must not be negative")
+	check(c.Redis.IdleTimeout >= 0, "redis.idle_timeout", "must not be negative")
+
+	check(c.Sessions.EventsMaxLen >= 0, "sessions.events_maxlen", "must not be negative")
+
+	oneOf(c.Audit.Log, "audit.log", "", "redis", "file")
+	check(c.Audit.Log != "file" || c.Audit.File != "", "audit.file", "is required with audit.log file")
+	check(c.Audit.MaxLen >= 0, "audit.maxlen", "must not be negative")
+	check(!c.Audit.IncludeValues || c.Sessions.ValueEncryptionKeys != "" || c.Sessions.ValueEncryptionKeysFile != "",
+		"audit.include_values", "needs sessions.value_encryption_keys, the recorded values are sealed by them")
+
+	oneOf(strings.ToLower(c.Log.Level), "log.level", "debug", "info", "warn", "error")
+	oneOf(c.Log.Format, "log.format", "json", "text")
+	oneOf(c.Log.SessionIDs, "log.session_ids", "hash", "redact", "plain")
+
+	oneOf(c.Tracing.Exporter, "tracing.exporter", "", "otlp", "stdout", "file")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+	if c.Tracing.Exporter == "otlp" {
+		u, err := url.Parse(c.Tracing.OTLPEndpoint)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"tracing.otlp_endpoint", "invalid url "+strconv.Quote(c.Tracing.OTLPEndpoint)+", use like http://collector:4318")
+	}
+	check(c.Tracing.Exporter != "file" || c.Tracing.File != "", "tracing.file", "is required with tracing.exporter file")
+
+	if len(problems) > 0 {
+		return errors.New("Invalid configuration:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// nonEmpty drops the empty value, which disables a feature, from the listed choices
+func nonEmpty(list []string) []string {
+	var values []string
+	for _, s := range list {
+		if s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
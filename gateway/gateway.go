@@ -0,0 +1,247 @@
+// Package gateway serves the DSessionService as a REST/JSON API.
+//
+// The handler calls the service through a gRPC client, so the requests pass the
+// same interceptors as the native gRPC calls.
+//
+//	POST   /sessions                      CreateSession      {"ttl": 10, "namespace": "shop"}
+//	GET    /sessions?ids=a&ids=b          GetSessions
+//	GET    /sessions/{id}                 GetSession
+//	PATCH  /sessions/{id}/values          AddValuesToSession {"values": {"foo": 15}}
+//	PUT    /sessions/{id}/values/{key}    AddValueToSession  15
+//	DELETE /sessions/{id}/values?keys=a   InvalidateSessionValues
+//	DELETE /sessions/{id}/values/{key}    InvalidateSessionValue
+//	POST   /sessions/{id}/renew           RenewSession       {"ttl": 10}
+//	GET    /sessions/{id}/events          WatchSession as server-sent events
+//	DELETE /sessions/{id}                 InvalidateSession
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	proto "github.com/golang/protobuf/proto"
+	st "github.com/golang/protobuf/ptypes/struct"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/ratelimit"
+	pb "github.com/hobord/dsession/session"
+)
+
+// forwardedHeaders are the only request headers passed to the gRPC call as metadata, with their first value,
+// so the HTTP clients cannot set the other metadata of the server, like the x-dsession-id of the services
+var forwardedHeaders = []string{"Authorization", "X-Request-Id", "X-Api-Key", "Traceparent", "Tracestate"}
+
+var marshaler = &jsonpb.Marshaler{}
+
+var unmarshaler = &jsonpb.Unmarshaler{}
+
+type handler struct {
+	client pb.DSessionServiceClient
+}
+
+// NewHandler returns the REST/JSON handler of the DSessionService
+func NewHandler(client pb.DSessionServiceClient) http.Handler {
+	return &handler{client: client}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	if parts[0] != "sessions" || len(parts) > 4 {
+		writeError(w, status.Error(codes.NotFound, "Not found"))
+		return
+	}
+	// the request id is passed to the gRPC call and returned to the caller
+	requestID := r.Header.Get("X-Request-Id")
+	if requestID == "" {
+		requestID = uuid.New().String()
+		r.Header.Set("X-Request-Id", requestID)
+	}
+	w.Header().Set("X-Request-Id", requestID)
+	ctx := outgoingContext(r)
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		in := &pb.CreateSessionMessage{}
+		if readBody(w, r, in) {
+			h.reply(w, http.StatusCreated)(h.client.CreateSession(ctx, in))
+		}
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		in := &pb.GetSessionsMessage{Ids: r.URL.Query()["ids"]}
+		h.reply(w, http.StatusOK)(h.client.GetSessions(ctx, in))
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		h.reply(w, http.StatusOK)(h.client.GetSession(ctx, &pb.GetSessionMessage{Id: parts[1]}))
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		h.reply(w, http.StatusOK)(h.client.InvalidateSession(ctx, &pb.InvalidateSessionMessage{Id: parts[1]}))
+	case len(parts) == 3 && parts[2] == "values" && r.Method == http.MethodPatch:
+		in := &pb.AddValuesToSessionMessage{}
+		if readBody(w, r, in) {
+			in.Id = parts[1]
+			h.reply(w, http.StatusOK)(h.client.AddValuesToSession(ctx, in))
+		}
+	case len(parts) == 3 && parts[2] == "values" && r.Method == http.MethodDelete:
+		in := &pb.InvalidateSessionValuesMessage{Id: parts[1], Keys: r.URL.Query()["keys"]}
+		h.reply(w, http.StatusOK)(h.client.InvalidateSessionValues(ctx, in))
+	case len(parts) == 4 && parts[2] == "values" && r.Method == http.MethodPut:
+		value := &st.Value{}
+		if readBody(w, r, value) {
+			in := &pb.AddValueToSessionMessage{Id: parts[1], Key: parts[3], Value: value}
+			h.reply(w, http.StatusOK)(h.client.AddValueToSession(ctx, in))
+		}
+	case len(parts) == 4 && parts[2] == "values" && r.Method == http.MethodDelete:
+		in := &pb.InvalidateSessionValueMessage{Id: parts[1], Key: parts[3]}
+		h.reply(w, http.StatusOK)(h.client.InvalidateSessionValue(ctx, in))
+	case len(parts) == 3 && parts[2] == "renew" && r.Method == http.MethodPost:
+		in := &pb.RenewSessionMessage{}
+		if readBody(w, r, in) {
+			in.Id = parts[1]
+			h.reply(w, http.StatusOK)(h.client.RenewSession(ctx, in))
+		}
+	case len(parts) == 3 && parts[2] == "events" && r.Method == http.MethodGet:
+		h.watch(ctx, w, parts[1])
+	default:
+		writeError(w, status.Errorf(codes.Unimplemented, "%s %s is not supported", r.Method, r.URL.Path))
+	}
+}
+
+// reply returns a func which writes the result of a gRPC call
+func (h *handler) reply(w http.ResponseWriter, code int) func(proto.Message, error) {
+	return func(res proto.Message, err error) {
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeMessage(w, code, res)
+	}
+}
+
+// watch streams the session events as server-sent events
+func (h *handler) watch(ctx context.Context, w http.ResponseWriter, id string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, status.Error(codes.Unimplemented, "Streaming is not supported"))
+		return
+	}
+	stream, err := h.client.WatchSession(ctx, &pb.WatchSessionMessage{Id: id})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// the server sends the headers once the watch is set up, without them the call failed
+	if md, err := stream.Header(); err != nil || md == nil {
+		if _, err = stream.Recv(); err != nil {
+			writeError(w, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			stat, _ := status.FromError(err)
+			fmt.Fprintf(w, "event: error\ndata: %q\n\n", stat.Message())
+			flusher.Flush()
+			return
+		}
+		data, err := marshaler.MarshalToString(ev)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+		flusher.Flush()
+	}
+}
+
+// outgoingContext forwards the allowed request headers as gRPC metadata
+func outgoingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	for _, name := range forwardedHeaders {
+		if v := r.Header.Get(name); v != "" {
+			md.Set(name, v)
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		forwarded := host
+		if prior := r.Header.Get("X-Forwarded-For"); prior != "" {
+			forwarded = prior + ", " + host
+		}
+		md.Set("x-forwarded-for", forwarded)
+	}
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+// readBody decodes the JSON body into the message, on failure it writes the error response
+func readBody(w http.ResponseWriter, r *http.Request, msg proto.Message) bool {
+	err := unmarshaler.Unmarshal(r.Body, msg)
+	if err == io.EOF {
+		return true
+	}
+	if err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "Invalid request body: %s", err))
+		return false
+	}
+	return true
+}
+
+func writeMessage(w http.ResponseWriter, code int, msg proto.Message) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	marshaler.Marshal(w, msg)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	stat, _ := status.FromError(err)
+	if delay, ok := ratelimit.RetryDelay(err); ok {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(delay.Seconds())), 10))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(stat.Code()))
+	marshaler.Marshal(w, stat.Proto())
+}
+
+// httpStatus maps the gRPC status codes to HTTP status codes
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
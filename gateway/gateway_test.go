@@ -0,0 +1,122 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	st "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/hobord/dsession/session"
+)
+
+type fakeClient struct {
+	pb.DSessionServiceClient
+	md       metadata.MD
+	lastAdd  *pb.AddValueToSessionMessage
+	sessions map[string]*pb.SessionResponse
+}
+
+func (c *fakeClient) GetSession(ctx context.Context, in *pb.GetSessionMessage, opts ...grpc.CallOption) (*pb.SessionResponse, error) {
+	c.md, _ = metadata.FromOutgoingContext(ctx)
+	session, ok := c.sessions[in.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "Session %s not found", in.Id)
+	}
+	return session, nil
+}
+
+func (c *fakeClient) AddValueToSession(ctx context.Context, in *pb.AddValueToSessionMessage, opts ...grpc.CallOption) (*pb.SessionResponse, error) {
+	c.lastAdd = in
+	return &pb.SessionResponse{Id: in.Id, Values: map[string]*st.Value{in.Key: in.Value}}, nil
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{sessions: map[string]*pb.SessionResponse{
+		"a": {Id: "a", Values: map[string]*st.Value{"foo": {Kind: &st.Value_NumberValue{NumberValue: 15}}}},
+	}}
+}
+
+func TestGetSession(t *testing.T) {
+	client := newFakeClient()
+	req := httptest.NewRequest(http.MethodGet, "/sessions/a", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	NewHandler(client).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Got status %d", rec.Code)
+	}
+	if body := rec.Body.String(); body != `{"id":"a","values":{"foo":15}}` {
+		t.Errorf("Got body %s", body)
+	}
+	if got := client.md.Get("authorization"); len(got) != 1 || got[0] != "Bearer token" {
+		t.Errorf("Authorization is not forwarded: %v", client.md)
+	}
+}
+
+func TestGetSessionNotFound(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(newFakeClient()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sessions/b", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Got status %d", rec.Code)
+	}
+}
+
+func TestPutValue(t *testing.T) {
+	client := newFakeClient()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/sessions/a/values/bar", strings.NewReader(`{"x":"y"}`))
+	NewHandler(client).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Got status %d: %s", rec.Code, rec.Body.String())
+	}
+	if client.lastAdd.Id != "a" || client.lastAdd.Key != "bar" {
+		t.Errorf("Got %v", client.lastAdd)
+	}
+	if client.lastAdd.Value.GetStructValue().Fields["x"].GetStringValue() != "y" {
+		t.Errorf("Got value %v", client.lastAdd.Value)
+	}
+}
+
+func TestInvalidBody(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/sessions/a/values/bar", strings.NewReader(`{`))
+	NewHandler(newFakeClient()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Got status %d", rec.Code)
+	}
+}
+
+func TestForwardedMetadata(t *testing.T) {
+	client := newFakeClient()
+	req := httptest.NewRequest(http.MethodGet, "/sessions/a", nil)
+	req.Header.Add("Authorization", "Bearer token")
+	req.Header.Add("Authorization", "Bearer other")
+	req.Header.Set("Grpc-Metadata-X-Dsession-Id", "b")
+	req.Header.Set("Grpc-Metadata-Authorization", "Bearer other")
+	req.Header.Set("X-Request-Id", "req-1")
+	NewHandler(client).ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := client.md.Get("authorization"); len(got) != 1 || got[0] != "Bearer token" {
+		t.Errorf("Got authorization %v", got)
+	}
+	if got := client.md.Get("x-request-id"); len(got) != 1 || got[0] != "req-1" {
+		t.Errorf("Got request id %v", got)
+	}
+	if got := client.md.Get("x-dsession-id"); len(got) != 0 {
+		t.Errorf("Got x-dsession-id %v, it is not forwarded", got)
+	}
+	if got := client.md.Get("grpc-metadata-x-dsession-id"); len(got) != 0 {
+		t.Errorf("Got %v", client.md)
+	}
+}
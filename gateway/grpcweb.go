@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"google.golang.org/grpc"
+)
+
+// corsHeaders are the request headers the browsers may send
+var corsHeaders = []string{"Authorization", "Content-Type", "X-Api-Key", "X-Request-Id", "X-Grpc-Web", "X-User-Agent", "Traceparent", "Tracestate"}
+
+// grpcWebServicePrefix is the only service served over grpc-web,
+// the admin service, the health checks and the reflection stay native gRPC only
+const grpcWebServicePrefix = "/hobord.session.DSessionService/"
+
+// OriginAllowed returns the check of the origin allowlist, "*" allows every origin
+func OriginAllowed(origins []string) func(origin string) bool {
+	allowed := make(map[string]bool)
+	for _, origin := range origins {
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+	return func(origin string) bool {
+		return allowed["*"] || allowed[origin]
+	}
+}
+
+// listedOrigins returns the origins of the allowlist without the "*" wildcard
+func listedOrigins(origins []string) []string {
+	var listed []string
+	for _, origin := range origins {
+		if origin != "*" {
+			listed = append(listed, origin)
+		}
+	}
+	return listed
+}
+
+// NewGrpcWebHandler serves the grpc-web requests of the browsers to the DSessionService with the gRPC server,
+// every other request is passed to the next handler. The grpc-web responses always allow credentials,
+// so only the listed origins are allowed, not the "*" wildcard.
+func NewGrpcWebHandler(server *grpc.Server, origins []string, next http.Handler) http.Handler {
+	wrapped := grpcweb.WrapServer(server,
+		grpcweb.WithOriginFunc(OriginAllowed(listedOrigins(origins))),
+		grpcweb.WithAllowedRequestHeaders(corsHeaders),
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wrapped.IsGrpcWebRequest(r) || wrapped.IsAcceptableGrpcCorsRequest(r) {
+			if !strings.HasPrefix(r.URL.Path, grpcWebServicePrefix) {
+				http.Error(w, "Service is not available over grpc-web", http.StatusNotFound)
+				return
+			}
+			wrapped.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CORS sets the CORS headers for the allowed origins and answers the preflight requests.
+// Only the listed origins may send credentials, the "*" wildcard allows the other origins without them.
+func CORS(origins []string, next http.Handler) http.Handler {
+	allowed := OriginAllowed(origins)
+	listed := OriginAllowed(listedOrigins(origins))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !allowed(origin) {
+			http.Error(w, "Origin is not allowed", http.StatusForbidden)
+			return
+		}
+
+		if listed(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Add("Vary", "Origin")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsHeaders, ", "))
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
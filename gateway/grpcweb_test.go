@@ -0,0 +1,91 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := OriginAllowed([]string{"https://shop.example.com/"})
+	if !allowed("https://shop.example.com") {
+		t.Errorf("Listed origin is not allowed")
+	}
+	if allowed("https://evil.example.com") {
+		t.Errorf("Unlisted origin is allowed")
+	}
+	if !OriginAllowed([]string{"*"})("https://evil.example.com") {
+		t.Errorf("Wildcard does not allow every origin")
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Preflight reached the next handler")
+	})
+	req := httptest.NewRequest(http.MethodOptions, "/sessions", nil)
+	req.Header.Set("Origin", "https://shop.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rec := httptest.NewRecorder()
+	CORS([]string{"https://shop.example.com"}, next).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Got status %d", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://shop.example.com" {
+		t.Errorf("Got allowed origin %s", got)
+	}
+}
+
+func TestCORSRejectsOrigin(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/sessions/a", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec := httptest.NewRecorder()
+	CORS([]string{"https://shop.example.com"}, http.NotFoundHandler()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Got status %d", rec.Code)
+	}
+}
+
+func TestCORSWildcardWithoutCredentials(t *testing.T) {
+	origins := []string{"https://shop.example.com", "*"}
+	tests := []struct {
+		origin      string
+		allowOrigin string
+		credentials string
+	}{
+		{"https://shop.example.com", "https://shop.example.com", "true"},
+		{"https://other.example.com", "*", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/sessions/a", nil)
+		req.Header.Set("Origin", tt.origin)
+		rec := httptest.NewRecorder()
+		CORS(origins, http.NotFoundHandler()).ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+			t.Errorf("Got allowed origin %s for %s, want %s", got, tt.origin, tt.allowOrigin)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tt.credentials {
+			t.Errorf("Got allowed credentials %q for %s, want %q", got, tt.origin, tt.credentials)
+		}
+	}
+}
+
+func TestGrpcWebServesOnlyTheSessionService(t *testing.T) {
+	handler := NewGrpcWebHandler(grpc.NewServer(), []string{"https://shop.example.com"}, http.NotFoundHandler())
+	for _, path := range []string{"/hobord.session.DSessionAdminService/ListSessions", "/grpc.health.v1.Health/Check"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Content-Type", "application/grpc-web+proto")
+		req.Header.Set("Origin", "https://shop.example.com")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Got status %d of %s", rec.Code, path)
+		}
+	}
+}
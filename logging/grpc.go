@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMetadata is the metadata key of the request id, the X-Request-Id header of the gateway
+const RequestIDMetadata = "x-request-id"
+
+// maxRequestIDLen limits the request ids accepted from the callers
+const maxRequestIDLen = 128
+
+// requestID returns the valid request id of the caller, or a new one
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadata); len(values) > 0 && validRequestID(values[0]) {
+			return values[0]
+		}
+	}
+	return uuid.New().String()
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// UnaryServerInterceptor gives every call a request id, which is returned in the x-request-id header
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := requestID(ctx)
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, id))
+		return handler(NewContext(ctx, id), req)
+	}
+}
+
+// requestStream passes the context with the request id to the stream handler
+type requestStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *requestStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerInterceptor gives every streaming call a request id, which is returned in the x-request-id header
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id := requestID(ss.Context())
+		ss.SetHeader(metadata.Pairs(RequestIDMetadata, id))
+		return handler(srv, &requestStream{ServerStream: ss, ctx: NewContext(ss.Context(), id)})
+	}
+}
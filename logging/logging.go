@@ -0,0 +1,274 @@
+// Package logging writes leveled, structured log lines, as JSON by default.
+// The lines logged with a context carry the request id and the trace id of the call.
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log line
+type Level int
+
+// Levels of the log lines
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < DebugLevel || l > ErrorLevel {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses debug, info, warn or error
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return InfoLevel, fmt.Errorf("Unknown log level %s", name)
+}
+
+// Session id modes, how the session ids are written to the log
+const (
+	SessionIDsHash   = "hash"   // a short hash, so the lines of one session can still be correlated
+	SessionIDsRedact = "redact" // nothing of the id
+	SessionIDsPlain  = "plain"  // the id itself, only for the local development
+)
+
+// Options configures the logger
+type Options struct {
+	Level      Level
+	Format     string // json or text
+	SessionIDs string // hash, redact or plain
+	Output     io.Writer
+}
+
+// logger is the configured output of the package
+type logger struct {
+	mu         sync.Mutex
+	level      Level
+	text       bool
+	sessionIDs string
+	out        io.Writer
+}
+
+var std = &logger{level: InfoLevel, sessionIDs: SessionIDsHash, out: os.Stderr}
+
+// Setup configures the logger, the lines of the standard log package are written as info lines too
+func Setup(opts Options) error {
+	if opts.Output == nil {
+		opts.Output = os.Stderr
+	}
+	switch opts.Format {
+	case "", "json", "text":
+	default:
+		return fmt.Errorf("Unknown log format %s, use json or text", opts.Format)
+	}
+	switch opts.SessionIDs {
+	case "":
+		opts.SessionIDs = SessionIDsHash
+	case SessionIDsHash, SessionIDsRedact, SessionIDsPlain:
+	default:
+		return fmt.Errorf("Unknown session id mode %s, use hash, redact or plain", opts.SessionIDs)
+	}
+	std.mu.Lock()
+	std.level = opts.Level
+	std.text = opts.Format == "text"
+	std.sessionIDs = opts.SessionIDs
+	std.out = opts.Output
+	std.mu.Unlock()
+
+	log.SetFlags(0)
+	log.SetOutput(stdlogWriter{})
+	return nil
+}
+
+// stdlogWriter turns the lines of the standard log package into info lines
+type stdlogWriter struct{}
+
+func (stdlogWriter) Write(p []byte) (int, error) {
+	std.write(context.Background(), InfoLevel, string(bytes.TrimRight(p, "\n")), nil)
+	return len(p), nil
+}
+
+// Enabled reports whether the lines of the level are written
+func Enabled(level Level) bool {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	return level >= std.level
+}
+
+// Debug logs a debug line, fields are key value pairs
+func Debug(ctx context.Context, msg string, fields ...interface{}) {
+	std.write(ctx, DebugLevel, msg, fields)
+}
+
+// Info logs an info line, fields are key value pairs
+func Info(ctx context.Context, msg string, fields ...interface{}) {
+	std.write(ctx, InfoLevel, msg, fields)
+}
+
+// Warn logs a warning line, fields are key value pairs
+func Warn(ctx context.Context, msg string, fields ...interface{}) {
+	std.write(ctx, WarnLevel, msg, fields)
+}
+
+// Error logs an error line, fields are key value pairs
+func Error(ctx context.Context, msg string, fields ...interface{}) {
+	std.write(ctx, ErrorLevel, msg, fields)
+}
+
+// Log logs a line of the level, fields are key value pairs
+func Log(ctx context.Context, level Level, msg string, fields ...interface{}) {
+	std.write(ctx, level, msg, fields)
+}
+
+// Fatal logs an error line and exits
+func Fatal(msg string, fields ...interface{}) {
+	std.write(context.Background(), ErrorLevel, msg, fields)
+	os.Exit(1)
+}
+
+func (l *logger) write(ctx context.Context, level Level, msg string, fields []interface{}) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !Enabled(level) {
+		return
+	}
+	line := map[string]interface{}{
+		"time":  time.Now().UTC().Format(time.RFC3339Nano),
+		"level": level.String(),
+		"msg":   msg,
+	}
+	if id := RequestID(ctx); id != "" {
+		line["request_id"] = id
+	}
+	for _, field := range contextFields() {
+		if value := field.value(ctx); value != "" {
+			line[field.key] = value
+		}
+	}
+	for i := 0; i < len(fields); i += 2 {
+		key, ok := fields[i].(string)
+		if !ok || i+1 == len(fields) {
+			line["!BADKEY"] = fields[i]
+			continue
+		}
+		switch v := fields[i+1].(type) {
+		case error:
+			line[key] = v.Error()
+		case time.Duration:
+			line[key] = float64(v) / float64(time.Millisecond)
+		case fmt.Stringer:
+			line[key] = v.String()
+		default:
+			line[key] = v
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var data []byte
+	if l.text {
+		data = formatText(line)
+	} else {
+		var err error
+		if data, err = json.Marshal(line); err != nil {
+			data, _ = json.Marshal(map[string]interface{}{"time": line["time"], "level": line["level"], "msg": msg, "!ERROR": err.Error()})
+		}
+	}
+	l.out.Write(append(data, '\n'))
+}
+
+// formatText formats the line as time level msg key=value..., for the local development
+func formatText(line map[string]interface{}) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %-5s %s", line["time"], strings.ToUpper(line["level"].(string)), line["msg"])
+	keys := make([]string, 0, len(line))
+	for key := range line {
+		if key != "time" && key != "level" && key != "msg" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, " %s=%v", key, line[key])
+	}
+	return buf.Bytes()
+}
+
+// SessionID returns the session id as it may be logged: hashed by default
+func SessionID(id string) string {
+	if id == "" {
+		return ""
+	}
+	std.mu.Lock()
+	mode := std.sessionIDs
+	std.mu.Unlock()
+	switch mode {
+	case SessionIDsPlain:
+		return id
+	case SessionIDsRedact:
+		return "[redacted]"
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:6])
+}
+
+// contextField is a field of every line which is read from the context
+type contextField struct {
+	key   string
+	value func(ctx context.Context) string
+}
+
+var (
+	fieldsMu         sync.RWMutex
+	registeredFields []contextField
+)
+
+// RegisterContextField adds a field read from the context to every line, like the trace id of the tracing package
+func RegisterContextField(key string, value func(ctx context.Context) string) {
+	fieldsMu.Lock()
+	registeredFields = append(registeredFields, contextField{key: key, value: value})
+	fieldsMu.Unlock()
+}
+
+func contextFields() []contextField {
+	fieldsMu.RLock()
+	defer fieldsMu.RUnlock()
+	return registeredFields
+}
+
+type requestIDKey struct{}
+
+// NewContext returns the context of a request with its id
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request id of the context, empty without one
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func setupTest(t *testing.T, opts Options) *bytes.Buffer {
+	var buf bytes.Buffer
+	opts.Output = &buf
+	if err := Setup(opts); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	return &buf
+}
+
+func TestJSONLines(t *testing.T) {
+	buf := setupTest(t, Options{Level: InfoLevel})
+	defer log.SetOutput(os.Stderr)
+
+	ctx := NewContext(context.Background(), "req-1")
+	Debug(ctx, "hidden")
+	Warn(ctx, "Webhook failed", "webhook", "crm", "attempts", 3, "error", errors.New("timeout"), "duration", 1500*time.Microsecond)
+	log.Printf("legacy line")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Got lines %q", buf.String())
+	}
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &line); err != nil {
+		t.Fatalf("Got invalid JSON %s", lines[0])
+	}
+	want := map[string]interface{}{"level": "warn", "msg": "Webhook failed", "request_id": "req-1",
+		"webhook": "crm", "attempts": float64(3), "error": "timeout", "duration": 1.5}
+	for key, value := range want {
+		if line[key] != value {
+			t.Errorf("Got %s=%v, want %v", key, line[key], value)
+		}
+	}
+	if !strings.Contains(lines[1], `"msg":"legacy line"`) || !strings.Contains(lines[1], `"level":"info"`) {
+		t.Errorf("Got %s", lines[1])
+	}
+}
+
+func TestSessionID(t *testing.T) {
+	defer log.SetOutput(os.Stderr)
+	id := "8f60aaef-a0bd-4c55-ab49-00c4ed5a4091"
+	sum := sha256.Sum256([]byte(id))
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{SessionIDsPlain, id},
+		{SessionIDsRedact, "[redacted]"},
+		{SessionIDsHash, hex.EncodeToString(sum[:6])},
+	}
+	for _, tt := range tests {
+		setupTest(t, Options{SessionIDs: tt.mode})
+		if got := SessionID(id); got != tt.want {
+			t.Errorf("%s: Got %s", tt.mode, got)
+		}
+	}
+	if err := Setup(Options{SessionIDs: "base64"}); err == nil {
+		t.Errorf("Unknown mode is accepted")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	call := func(ctx context.Context) string {
+		var got string
+		interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			got = RequestID(ctx)
+			return nil, nil
+		})
+		return got
+	}
+	given := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadata, "abc-123"))
+	if id := call(given); id != "abc-123" {
+		t.Errorf("Got %s", id)
+	}
+	invalid := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDMetadata, "bad id\n"))
+	if id := call(invalid); id == "" || id == "bad id\n" {
+		t.Errorf("Got %q", id)
+	}
+}
@@ -2,48 +2,368 @@
 // go get github.com/fullstorydev/grpcurl
 // go install github.com/fullstorydev/grpcurl/cmd/grpcurl
 /*
-// docker run -p 6379:6379 --name redis-redisjson redislabs/rejson:latest
+// docker run -p 6379:6379 --name redis-redisjson redislabs/rejson:latest --notify-keyspace-events Ex
 grpcurl.exe -plaintext localhost:50051 list
 
 grpcurl -plaintext -d '{"ttl":10}' localhost:50051 hobord.session.DSessionService/CreateSession
 grpcurl -plaintext -d '{"ttl":0}' localhost:50051 hobord.session.DSessionService/CreateSession
 grpcurl -plaintext -d '{"id":"8f60aaef-a0bd-4c55-ab49-00c4ed5a4091", "key":"foo", "value": {"numberValue": 15}}' localhost:50051 hobord.session.DSessionService/AddValueToSession
 grpcurl -plaintext -d '{"id":"8f60aaef-a0bd-4c55-ab49-00c4ed5a4091"}'  localhost:50051 hobord.session.DSessionService/GetSession
+grpcurl -plaintext -d '{"ids":["8f60aaef-a0bd-4c55-ab49-00c4ed5a4091"]}'  localhost:50051 hobord.session.DSessionService/GetSessions
+grpcurl -plaintext -d '{"id":"8f60aaef-a0bd-4c55-ab49-00c4ed5a4091"}'  localhost:50051 hobord.session.DSessionService/WatchSession
+grpcurl -plaintext -d '{"group":"analytics","consumer":"worker-1"}' localhost:50051 hobord.session.DSessionAdminService/SubscribeEvents
+curl -X POST -d '{"ttl":10}' localhost:8080/sessions
+curl -X PATCH -d '{"values":{"foo":15}}' localhost:8080/sessions/8f60aaef-a0bd-4c55-ab49-00c4ed5a4091/values
+curl localhost:8080/sessions/8f60aaef-a0bd-4c55-ab49-00c4ed5a4091
+curl localhost:9090/metrics
+curl localhost:8080/readyz
+grpcurl -plaintext -d '{"service":"hobord.session.DSessionService"}' localhost:50051 grpc.health.v1.Health/Check
+grpcurl -plaintext -d '{"filter":{"namespace":"shop","createdBefore":1560000000},"dryRun":true}' localhost:50051 hobord.session.DSessionAdminService/StartBulkInvalidation
+grpcurl -plaintext -d '{"id":"<job id>"}' localhost:50051 hobord.session.DSessionAdminService/GetJob
+grpcurl -plaintext -d '{"filter":{"namespace":"shop"},"limit":50}' localhost:50051 hobord.session.DSessionAdminService/ListSessions
+go run ./cmd/dsessionctl list -namespace shop
+go run ./cmd/dsessionctl -ca ca.pem -cert client.pem -key client-key.pem list
+go run ./cmd/dsessionctl -o json get 8f60aaef-a0bd-4c55-ab49-00c4ed5a4091
+go run ./cmd/dsessionctl export -namespace shop -file shop.jsonl
+go run ./cmd/dsessionctl -addr other:50051 import -file shop.jsonl -conflict merge
+go run ./cmd/dsessionctl -api-key "$BACKEND_KEY" list
+go run ./cmd/dsessionctl audit -session 8f60aaef-a0bd-4c55-ab49-00c4ed5a4091
+go run ./cmd/dsessionctl reencrypt && go run ./cmd/dsessionctl job <job id>
+go run . -config dsession.yaml -redis.url redis://localhost:6379/1 -print-config
 
 */
 
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"log"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/hobord/dsession/auth"
+	"github.com/hobord/dsession/config"
+	"github.com/hobord/dsession/gateway"
+	"github.com/hobord/dsession/logging"
+	"github.com/hobord/dsession/metrics"
+	"github.com/hobord/dsession/ratelimit"
 	pb "github.com/hobord/dsession/session"
+	"github.com/hobord/dsession/tracing"
 )
 
 func main() {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = ":50051"
+	cfg, printConfig, err := config.Load(os.Args[1:], os.Stderr)
+	if err == flag.ErrHelp {
+		os.Exit(0)
 	}
-	lis, err := net.Listen("tcp", port)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
-	fmt.Println("Server listen: ", port)
+	if printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := setupLogging(cfg.Log); err != nil {
+		logging.Fatal("failed to configure logging", "error", err)
+	}
+
+	lis, err := net.Listen("tcp", cfg.Server.GRPCAddr)
+	if err != nil {
+		logging.Fatal("failed to listen", "error", err)
+	}
+	logging.Info(context.Background(), "Server listen", "address", cfg.Server.GRPCAddr)
+
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor(), metrics.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor(), metrics.StreamServerInterceptor()),
+	}
+	var tracer *sdktrace.TracerProvider
+	if cfg.Tracing.Exporter != "" {
+		tracer, err = newTracerProvider(cfg.Tracing)
+		if err != nil {
+			logging.Fatal("failed to configure tracing", "error", err)
+		}
+		tracing.SetProvider(tracer)
+		opts = append(opts, grpc.StatsHandler(tracing.ServerHandler()))
+	}
+	var creds credentials.TransportCredentials
+	if cfg.TLS.CertFile != "" {
+		certs, err := auth.NewCertReloader(auth.TLSOptions{
+			CertFile:          cfg.TLS.CertFile,
+			KeyFile:           cfg.TLS.KeyFile,
+			ClientCAFile:      cfg.TLS.ClientCAFile,
+			RequireClientCert: cfg.TLS.RequireClientCert,
+		})
+		if err != nil {
+			logging.Fatal("failed to load TLS certificate", "error", err)
+		}
+		certs.Watch(30 * time.Second)
+		creds = credentials.NewTLS(certs.ServerConfig())
+	}
+	// the connections of the in-process gateway skip the TLS
+	opts = append(opts, grpc.Creds(auth.ServerCredentials(creds)))
+
+	pbImpl, err := pb.NewRedisImpl(cfg)
+	if err != nil {
+		logging.Fatal("failed to create the session service", "error", err)
+	}
+	pbImpl.StartEvents()
+	metrics.RegisterRedisPool(pbImpl.RedisPool)
+
+	// the access log runs ahead of the limits and the authentication, so it has the rejected calls too
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(pbImpl.AccessLogUnaryInterceptor()),
+		grpc.ChainStreamInterceptor(pbImpl.AccessLogStreamInterceptor()))
 
-	s := grpc.NewServer()
+	// the ip limits run ahead of the authentication, so the floods with bad credentials reach them
+	var clientRules []*ratelimit.Rule
+	if cfg.Auth.RateLimitsFile != "" {
+		rules, err := ratelimit.LoadRules(cfg.Auth.RateLimitsFile)
+		if err != nil {
+			logging.Fatal("failed to load rate limits", "error", err)
+		}
+		var ipRules []*ratelimit.Rule
+		ipRules, clientRules = ratelimit.SplitRules(rules)
+		if len(ipRules) > 0 {
+			limiter := ratelimit.NewLimiter(pbImpl.RedisPool, ipRules, pbImpl.SessionID)
+			opts = append(opts,
+				grpc.ChainUnaryInterceptor(limiter.UnaryServerInterceptor()),
+				grpc.ChainStreamInterceptor(limiter.StreamServerInterceptor()))
+		}
+	}
+
+	if cfg.Auth.PolicyFile != "" {
+		policy, err := auth.LoadPolicy(cfg.Auth.PolicyFile)
+		if err != nil {
+			logging.Fatal("failed to load auth policy", "error", err)
+		}
+		authenticator, err := auth.NewAuthenticator(policy)
+		if err != nil {
+			logging.Fatal("failed to load auth keys", "error", err)
+		}
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(authenticator.StreamServerInterceptor()))
+	}
+
+	if len(clientRules) > 0 {
+		limiter := ratelimit.NewLimiter(pbImpl.RedisPool, clientRules, pbImpl.SessionID)
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(limiter.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(limiter.StreamServerInterceptor()))
+	}
+
+	if cfg.Audit.Log != "" {
+		auditOpts := pb.AuditOptions{IncludeValues: cfg.Audit.IncludeValues, MaxLen: cfg.Audit.MaxLen}
+		if cfg.Audit.Log == "file" {
+			auditOpts.File = cfg.Audit.File
+		}
+		if err := pbImpl.StartAudit(auditOpts); err != nil {
+			logging.Fatal("failed to open the audit log", "error", err)
+		}
+		opts = append(opts, grpc.ChainUnaryInterceptor(pbImpl.AuditUnaryInterceptor()))
+	}
+
+	s := grpc.NewServer(opts...)
 	reflection.Register(s)
 
-	pbImpl := pb.CreateRedisImpl()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+	healthCtx, stopHealthChecks := context.WithCancel(context.Background())
+	pbImpl.StartHealthChecks(healthCtx, healthServer, time.Duration(cfg.Server.HealthCheckInterval))
+
+	if cfg.Auth.WebhooksFile != "" {
+		hooks, err := pb.LoadWebhooks(cfg.Auth.WebhooksFile)
+		if err != nil {
+			logging.Fatal("failed to load webhooks", "error", err)
+		}
+		pbImpl.StartWebhooks(hooks)
+	}
 	pb.RegisterDSessionServiceServer(s, pbImpl)
+	pb.RegisterDSessionAdminServiceServer(s, pbImpl)
+
+	var metricsServer, httpServer *http.Server
+	var gatewayConn *grpc.ClientConn
+	if cfg.Server.MetricsAddr != "" {
+		metricsServer = serveMetrics(cfg.Server.MetricsAddr, pbImpl)
+	}
+	if cfg.Server.HTTPAddr != "" {
+		httpServer, gatewayConn = serveHTTP(cfg.Server, s, pbImpl)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+		sig := <-signals
+		shutdownTimeout := time.Duration(cfg.Server.ShutdownTimeout)
+		logging.Info(context.Background(), "Shutting down", "signal", sig.String(), "timeout", shutdownTimeout.String())
+
+		// fail the health checks first, so no new calls are routed here
+		healthServer.Shutdown()
+		stopHealthChecks()
+		pbImpl.Drain()
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if httpServer != nil {
+			if err := httpServer.Shutdown(ctx); err != nil {
+				logging.Warn(ctx, "HTTP requests are cut", "error", err)
+			}
+			gatewayConn.Close()
+		}
+		gracefulStop(ctx, s)
+		if metricsServer != nil {
+			metricsServer.Close()
+		}
+		if tracer != nil {
+			if err := tracer.Shutdown(ctx); err != nil {
+				logging.Warn(ctx, "Spans are dropped", "error", err)
+			}
+		}
+		if err := pbImpl.Close(); err != nil {
+			logging.Warn(ctx, "Failed to close the redis pool", "error", err)
+		}
+		close(stopped)
+	}()
 
 	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+		logging.Fatal("failed to serve", "error", err)
+	}
+	<-stopped
+	logging.Info(context.Background(), "Server stopped")
+}
+
+// gracefulStop waits for the running calls until the context is done, then cuts them
+func gracefulStop(ctx context.Context, s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logging.Warn(ctx, "Drain timeout, the running calls are cut")
+		s.Stop()
+		<-done
+	}
+}
+
+// listenAndServe serves the HTTP server in the background
+func listenAndServe(server *http.Server, name string) {
+	logging.Info(context.Background(), name+" listen", "address", server.Addr)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Fatal("failed to serve "+strings.ToLower(name), "error", err)
+		}
+	}()
+}
+
+// serveHTTP serves the REST/JSON gateway, the probes and optionally grpc-web,
+// the gateway calls the gRPC server over an in-process listener with the returned connection
+func serveHTTP(server config.Server, grpcServer *grpc.Server, pbImpl *pb.GrpcRedisImplServer) (*http.Server, *grpc.ClientConn) {
+	gatewayLis := auth.NewGatewayListener()
+	go func() {
+		if err := grpcServer.Serve(gatewayLis); err != nil {
+			logging.Fatal("failed to serve the gateway", "error", err)
+		}
+	}()
+	conn, err := gatewayLis.ClientConn()
+	if err != nil {
+		logging.Fatal("failed to dial grpc server", "error", err)
 	}
+
+	// the HTTP listener has no TLS, the config validation requires the auth policy
+	// next to tls.require_client_cert since these calls carry no client certificate
+	rest := gateway.NewHandler(pb.NewDSessionServiceClient(conn))
+	mux := http.NewServeMux()
+	mux.Handle("/sessions", rest)
+	mux.Handle("/sessions/", rest)
+	handleProbes(mux, pbImpl)
+
+	handler := gateway.CORS(server.CORSAllowedOrigins, mux)
+	if server.GRPCWeb {
+		handler = gateway.NewGrpcWebHandler(grpcServer, server.CORSAllowedOrigins, handler)
+	}
+
+	httpServer := &http.Server{Addr: server.HTTPAddr, Handler: handler}
+	listenAndServe(httpServer, "HTTP")
+	return httpServer, conn
+}
+
+// setupLogging configures the level, the format and how the session ids are logged
+func setupLogging(cfg config.Log) error {
+	level, err := logging.ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	return logging.Setup(logging.Options{Level: level, Format: cfg.Format, SessionIDs: cfg.SessionIDs})
+}
+
+// newTracerProvider configures the span exporter, otlp posts to the collector,
+// stdout and file write JSON lines for the local use
+func newTracerProvider(cfg config.Tracing) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "otlp":
+		exporter, err = tracing.NewOTLPExporter(context.Background(), cfg.OTLPEndpoint, cfg.OTLPHeaders)
+	case "stdout":
+		exporter, err = tracing.NewWriterExporter(os.Stdout)
+	case "file":
+		var file *os.File
+		file, err = os.OpenFile(cfg.File, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err == nil {
+			exporter, err = tracing.NewWriterExporter(file)
+		}
+	default:
+		err = fmt.Errorf("unknown tracing exporter %s, use otlp, stdout or file", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tracing.NewProvider(exporter, tracing.Options{ServiceName: cfg.ServiceName, SampleRatio: cfg.SampleRatio})
+}
+
+// handleProbes adds the liveness (/healthz) and the readiness (/readyz) endpoints,
+// the server is ready while redis answers the health checks
+func handleProbes(mux *http.ServeMux, pbImpl *pb.GrpcRedisImplServer) {
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := pbImpl.Ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// serveMetrics serves the /metrics and the probe endpoints, the metrics are kept off the public HTTP port
+func serveMetrics(metricsPort string, pbImpl *pb.GrpcRedisImplServer) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	handleProbes(mux, pbImpl)
+	server := &http.Server{Addr: metricsPort, Handler: mux}
+	listenAndServe(server, "Metrics")
+	return server
 }
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dsession_grpc_requests_total",
+		Help: "Number of the finished gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dsession_grpc_request_duration_seconds",
+		Help:    "Latency of the unary gRPC calls by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// UnaryServerInterceptor counts the unary calls and observes their latencies
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		grpcDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		grpcRequests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor counts the streaming calls when they finish
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		grpcRequests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		return err
+	}
+}
@@ -0,0 +1,14 @@
+// Package metrics collects the counters and histograms of the service with the Prometheus
+// client and serves them, with the Go runtime and process metrics, on the metrics listener.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves the metrics of the default Prometheus registry
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHandler(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+	interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "Session not found")
+	})
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`dsession_grpc_requests_total{code="NotFound",method="/test.Service/Get"} 1`,
+		`dsession_grpc_request_duration_seconds_count{method="/test.Service/Get"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Got no %s in\n%s", want, body)
+		}
+	}
+}
+
+// replyConn answers every command with the error
+type replyConn struct {
+	err error
+}
+
+func (c *replyConn) Close() error                                   { return nil }
+func (c *replyConn) Err() error                                     { return nil }
+func (c *replyConn) Do(string, ...interface{}) (interface{}, error) { return nil, c.err }
+func (c *replyConn) Send(string, ...interface{}) error              { return nil }
+func (c *replyConn) Flush() error                                   { return nil }
+func (c *replyConn) Receive() (interface{}, error)                  { return nil, nil }
+
+func TestInstrumentRedisConn(t *testing.T) {
+	conn := InstrumentRedisConn(&replyConn{})
+	conn.Do("hgetall", "abc")
+	conn.Do("")
+	if testutil.CollectAndCount(redisDuration) != 2 {
+		t.Errorf("Commands are not observed")
+	}
+
+	conn = InstrumentRedisConn(&replyConn{err: errors.New("NOSCRIPT No matching script")})
+	conn.Do("EVALSHA")
+	if testutil.ToFloat64(redisErrors.WithLabelValues("EVALSHA")) != 0 {
+		t.Errorf("NOSCRIPT is counted as error")
+	}
+	conn = InstrumentRedisConn(&replyConn{err: errors.New("connection refused")})
+	conn.Do("GET")
+	if testutil.ToFloat64(redisErrors.WithLabelValues("GET")) != 1 {
+		t.Errorf("Got errors %v", testutil.ToFloat64(redisErrors.WithLabelValues("GET")))
+	}
+}
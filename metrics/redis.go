@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	redisDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dsession_redis_command_duration_seconds",
+		Help:    "Latency of the redis commands, the flushed pipelines are labelled pipeline.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"command"})
+	redisErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dsession_redis_command_errors_total",
+		Help: "Number of the failed redis commands.",
+	}, []string{"command"})
+)
+
+// RegisterRedisPool exposes the connection statistics of the pool
+func RegisterRedisPool(pool *redis.Pool) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{Name: "dsession_redis_pool_active_connections", Help: "Number of the connections of the redis pool."},
+		func() float64 { return float64(pool.Stats().ActiveCount) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{Name: "dsession_redis_pool_idle_connections", Help: "Number of the idle connections of the redis pool."},
+		func() float64 { return float64(pool.Stats().IdleCount) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{Name: "dsession_redis_pool_wait_count", Help: "Number of the waits for a connection of the redis pool."},
+		func() float64 { return float64(pool.Stats().WaitCount) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{Name: "dsession_redis_pool_wait_duration_seconds", Help: "Total time waited for a connection of the redis pool."},
+		func() float64 { return pool.Stats().WaitDuration.Seconds() })
+}
+
+// InstrumentRedisConn observes the latencies and the errors of the commands of the connection
+func InstrumentRedisConn(c redis.Conn) redis.Conn {
+	return &instrumentedConn{Conn: c}
+}
+
+// instrumentedConn times Do, the commands queued with Send are timed with the Do("") which flushes them
+type instrumentedConn struct {
+	redis.Conn
+}
+
+func commandLabel(cmd string) string {
+	if cmd == "" {
+		return "pipeline"
+	}
+	return strings.ToUpper(cmd)
+}
+
+func observe(cmd string, start time.Time, err error) {
+	label := commandLabel(cmd)
+	redisDuration.WithLabelValues(label).Observe(time.Since(start).Seconds())
+	// the NOSCRIPT reply of EVALSHA is retried by redis.Script with EVAL
+	if err != nil && !strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		redisErrors.WithLabelValues(label).Inc()
+	}
+}
+
+func (c *instrumentedConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	start := time.Now()
+	reply, err := c.Conn.Do(cmd, args...)
+	observe(cmd, start, err)
+	return reply, err
+}
+
+func (c *instrumentedConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	start := time.Now()
+	reply, err := redis.DoWithTimeout(c.Conn, timeout, cmd, args...)
+	observe(cmd, start, err)
+	return reply, err
+}
+
+func (c *instrumentedConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return redis.ReceiveWithTimeout(c.Conn, timeout)
+}
@@ -0,0 +1,346 @@
+// Package ratelimit limits the calls of the DSessionService with token buckets kept in Redis,
+// so the replicas share the limits.
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/hobord/dsession/auth"
+	"github.com/hobord/dsession/logging"
+)
+
+// Identities of the callers which a rule limits
+const (
+	ByClient  = "client"  // the authenticated client and subject
+	ByIP      = "ip"      // the peer address, behind the in-process gateway the forwarded address
+	BySession = "session" // the session id of the request
+)
+
+// RetryAfterMetadata is the header of the rejected calls with the seconds to wait
+const RetryAfterMetadata = "retry-after"
+
+// healthMethodPrefix marks the health checks, the rules without methods do not limit the probes
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// bucketKeyPrefix is the prefix of the redis hashes of the buckets
+const bucketKeyPrefix = "dsession:ratelimit:"
+
+// Limit is a token bucket refilled by Rate tokens per second up to Burst tokens
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// unlimited reports whether the limit lets every call through
+func (l Limit) unlimited() bool {
+	return l.Rate <= 0
+}
+
+// Rule limits the calls of the methods for every identity separately
+type Rule struct {
+	Name    string           `json:"name"`
+	By      string           `json:"by"`      // client, ip or session
+	Methods []string         `json:"methods"` // full method names or path.Match patterns, empty = all but the health checks
+	Limit                    // default limit of the identities
+	Clients map[string]Limit `json:"clients"` // limits of some clients instead of the default, rate 0 = unlimited, not for ip
+}
+
+func (r *Rule) init() error {
+	if r.Name == "" {
+		return fmt.Errorf("Rate limit rule without name")
+	}
+	switch r.By {
+	case ByClient, ByIP, BySession:
+	default:
+		return fmt.Errorf("Rate limit rule %s has an invalid identity %q", r.Name, r.By)
+	}
+	if r.By == ByIP && len(r.Clients) > 0 {
+		// the ip rules run ahead of the authentication, the client is not known yet
+		return fmt.Errorf("Rate limit rule %s limits by ip, it cannot have client limits", r.Name)
+	}
+	for _, pattern := range r.Methods {
+		if _, err := path.Match(strings.TrimPrefix(pattern, "/"), ""); err != nil {
+			return fmt.Errorf("Rate limit rule %s has an invalid method pattern %q", r.Name, pattern)
+		}
+	}
+	limits := []Limit{r.Limit}
+	for _, l := range r.Clients {
+		limits = append(limits, l)
+	}
+	for _, l := range limits {
+		if !l.unlimited() && l.Burst < 1 {
+			return fmt.Errorf("Rate limit rule %s needs a burst of at least 1", r.Name)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) matchesMethod(method string) bool {
+	if len(r.Methods) == 0 {
+		return !strings.HasPrefix(method, healthMethodPrefix)
+	}
+	method = strings.TrimPrefix(method, "/")
+	for _, pattern := range r.Methods {
+		if ok, _ := path.Match(strings.TrimPrefix(pattern, "/"), method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// limitOf returns the limit of the client
+func (r *Rule) limitOf(client string) Limit {
+	if l, ok := r.Clients[client]; ok && client != "" {
+		return l
+	}
+	return r.Limit
+}
+
+// LoadRules reads the JSON list of the rules
+func LoadRules(file string) ([]*Rule, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var rules []*Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("Invalid rate limits config %s: %s", file, err)
+	}
+	names := make(map[string]bool)
+	for _, r := range rules {
+		if err := r.init(); err != nil {
+			return nil, err
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("Duplicated rate limit rule name: %s", r.Name)
+		}
+		names[r.Name] = true
+	}
+	return rules, nil
+}
+
+// SplitRules separates the ip rules, which run ahead of the authentication so the calls with
+// bad credentials are limited too, from the rules which need the authenticated client
+func SplitRules(rules []*Rule) (ip, authenticated []*Rule) {
+	for _, r := range rules {
+		if r.By == ByIP {
+			ip = append(ip, r)
+		} else {
+			authenticated = append(authenticated, r)
+		}
+	}
+	return ip, authenticated
+}
+
+// bucketScript takes a token from the bucket KEYS[1] refilled by ARGV[1] tokens per second
+// up to ARGV[2] tokens at ARGV[3] milliseconds. It returns 1 and 0 when the call is allowed,
+// 0 and the milliseconds until the next token when it is not.
+var bucketScript = redis.NewScript(1, `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+	ts = now
+end
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", ts)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, wait}
+`)
+
+// Limiter checks the calls against the rules
+type Limiter struct {
+	pool  *redis.Pool
+	rules []*Rule
+	// sessionID verifies the session token of a request and returns its session id
+	sessionID func(token string) (string, error)
+	now       func() time.Time
+}
+
+// NewLimiter returns a limiter keeping its buckets in the redis pool. sessionID verifies
+// the session tokens, so every token of a session shares the bucket of the session id.
+func NewLimiter(pool *redis.Pool, rules []*Rule, sessionID func(token string) (string, error)) *Limiter {
+	return &Limiter{pool: pool, rules: rules, sessionID: sessionID, now: time.Now}
+}
+
+// take takes a token from the bucket, it returns the wait until the next token when it is empty
+func (l *Limiter) take(conn redis.Conn, key string, limit Limit) (bool, time.Duration, error) {
+	now := l.now().UnixNano() / int64(time.Millisecond)
+	reply, err := redis.Int64s(bucketScript.Do(conn, key, limit.Rate, limit.Burst, now))
+	if err != nil {
+		return false, 0, err
+	}
+	if len(reply) != 2 {
+		return false, 0, fmt.Errorf("Unexpected rate limit reply %v", reply)
+	}
+	return reply[0] == 1, time.Duration(reply[1]) * time.Millisecond, nil
+}
+
+// Check takes a token for the call from the bucket of every matching rule, the request
+// is nil for the streaming calls. A rejected call gets a ResourceExhausted error.
+// When Redis fails the call is allowed.
+func (l *Limiter) Check(ctx context.Context, method string, req interface{}) error {
+	client := ""
+	if p, ok := auth.FromContext(ctx); ok {
+		client = p.Name()
+	}
+
+	var conn redis.Conn
+	for _, r := range l.rules {
+		if !r.matchesMethod(method) {
+			continue
+		}
+		limit := r.limitOf(client)
+		if limit.unlimited() {
+			continue
+		}
+		identity := l.callerIdentity(ctx, r.By, req)
+		if identity == "" {
+			continue
+		}
+		if conn == nil {
+			conn = l.pool.Get()
+			defer conn.Close()
+		}
+		allowed, wait, err := l.take(conn, bucketKeyPrefix+r.Name+":"+identity, limit)
+		if err != nil {
+			logging.Warn(ctx, "Rate limit is not checked", "rule", r.Name, "error", err)
+			continue
+		}
+		if !allowed {
+			return rejected(ctx, r.Name, wait)
+		}
+	}
+	return nil
+}
+
+// rejected returns the error of a limited call with the retry delay in its details and headers
+func rejected(ctx context.Context, rule string, wait time.Duration) error {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(RetryAfterMetadata, strconv.FormatInt(seconds, 10)))
+	st := status.Newf(codes.ResourceExhausted, "Rate limit %s exceeded, retry after %ds", rule, seconds)
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// RetryDelay returns the retry delay of a rate limit error
+func RetryDelay(err error) (time.Duration, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.RetryDelay != nil {
+			return info.RetryDelay.AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// sessionRequest is a request with a session id
+type sessionRequest interface {
+	GetId() string
+}
+
+// callerIdentity returns the identity of the call for the rule, empty if it has none.
+// The invalid session tokens have none, their calls are rejected by the service.
+func (l *Limiter) callerIdentity(ctx context.Context, by string, req interface{}) string {
+	switch by {
+	case ByClient:
+		p, ok := auth.FromContext(ctx)
+		if !ok {
+			return ""
+		}
+		if p.Subject != "" {
+			return p.Name() + ":" + p.Subject
+		}
+		return p.Name()
+	case ByIP:
+		return peerIP(ctx)
+	case BySession:
+		if r, ok := req.(sessionRequest); ok {
+			if id, err := l.sessionID(r.GetId()); err == nil {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// peerIP returns the address of the caller. The calls of the in-process gateway carry the
+// address of the HTTP client as the last x-forwarded-for entry.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if auth.FromGateway(ctx) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if forwarded := md.Get("x-forwarded-for"); len(forwarded) > 0 {
+			hops := strings.Split(forwarded[len(forwarded)-1], ",")
+			if last := strings.TrimSpace(hops[len(hops)-1]); last != "" {
+				return last
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return host
+}
+
+// UnaryServerInterceptor rejects the unary calls over the limits
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.Check(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects the streaming calls over the limits, the session rules
+// do not apply to them because the request is not read yet
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.Check(ss.Context(), info.FullMethod, nil); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,225 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/auth"
+)
+
+// bucketConn answers the bucket script with the reply and records the bucket keys
+type bucketConn struct {
+	reply interface{}
+	err   error
+	keys  []string
+}
+
+func (c *bucketConn) Close() error { return nil }
+func (c *bucketConn) Err() error   { return nil }
+func (c *bucketConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if cmd == "" {
+		return nil, nil
+	}
+	c.keys = append(c.keys, args[2].(string))
+	return c.reply, c.err
+}
+func (c *bucketConn) Send(string, ...interface{}) error { return nil }
+func (c *bucketConn) Flush() error                      { return nil }
+func (c *bucketConn) Receive() (interface{}, error)     { return nil, nil }
+
+func newTestLimiter(conn *bucketConn, rules ...*Rule) *Limiter {
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return conn, nil }}
+	return NewLimiter(pool, rules, testSessionID)
+}
+
+// testSessionID accepts the tokens like token-<id>
+func testSessionID(token string) (string, error) {
+	if !strings.HasPrefix(token, "token-") {
+		return "", errors.New("Invalid session id")
+	}
+	return strings.TrimPrefix(token, "token-"), nil
+}
+
+type idRequest struct{ id string }
+
+func (r *idRequest) GetId() string { return r.id }
+
+func TestCheck(t *testing.T) {
+	rules := []*Rule{
+		{Name: "create", By: ByClient, Methods: []string{"hobord.session.DSessionService/CreateSession"},
+			Limit: Limit{Rate: 1, Burst: 5}, Clients: map[string]Limit{"backend": {}}},
+		{Name: "session", By: BySession, Limit: Limit{Rate: 10, Burst: 20}},
+	}
+	browser := auth.NewContext(context.Background(), &auth.Principal{Client: &auth.Client{Name: "browser"}, Subject: "bob"})
+	backend := auth.NewContext(context.Background(), &auth.Principal{Client: &auth.Client{Name: "backend"}})
+
+	conn := &bucketConn{reply: []interface{}{int64(0), int64(1500)}}
+	l := newTestLimiter(conn, rules...)
+	err := l.Check(browser, "/hobord.session.DSessionService/CreateSession", nil)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Got %v", err)
+	}
+	if delay, ok := RetryDelay(err); !ok || delay != 1500*time.Millisecond {
+		t.Errorf("Got retry delay %s", delay)
+	}
+	if len(conn.keys) != 1 || conn.keys[0] != "dsession:ratelimit:create:browser:bob" {
+		t.Errorf("Got buckets %v", conn.keys)
+	}
+
+	conn.keys = nil
+	if err := l.Check(backend, "/hobord.session.DSessionService/CreateSession", nil); err != nil {
+		t.Errorf("Unlimited client is rejected: %v", err)
+	}
+	if err := l.Check(backend, "/hobord.session.DSessionService/GetSession", &idRequest{"token-abc"}); err == nil {
+		t.Errorf("Session limit is not checked")
+	}
+	if err := l.Check(backend, "/hobord.session.DSessionService/GetSession", &idRequest{"forged-abc"}); err != nil {
+		t.Errorf("Invalid session token got a bucket: %v", err)
+	}
+	if len(conn.keys) != 1 || conn.keys[0] != "dsession:ratelimit:session:abc" {
+		t.Errorf("Got buckets %v", conn.keys)
+	}
+
+	conn.reply = []interface{}{int64(1), int64(0)}
+	if err := l.Check(browser, "/hobord.session.DSessionService/CreateSession", nil); err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+
+	conn.err = errors.New("connection refused")
+	if err := l.Check(browser, "/hobord.session.DSessionService/CreateSession", nil); err != nil {
+		t.Errorf("Redis failure rejects the call: %v", err)
+	}
+}
+
+func TestPeerIP(t *testing.T) {
+	remote := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 4000}})
+	if ip := peerIP(remote); ip != "203.0.113.7" {
+		t.Errorf("Got %s", ip)
+	}
+
+	// the forwarded address is trusted only from the local gateway
+	md := metadata.Pairs("x-forwarded-for", "10.0.0.1, 198.51.100.2")
+	spoofed := metadata.NewIncomingContext(remote, md)
+	if ip := peerIP(spoofed); ip != "203.0.113.7" {
+		t.Errorf("Got %s", ip)
+	}
+	loopback := peer.NewContext(metadata.NewIncomingContext(context.Background(), md),
+		&peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 4000}})
+	if ip := peerIP(loopback); ip != "127.0.0.1" {
+		t.Errorf("Got %s", ip)
+	}
+	gateway := peer.NewContext(metadata.NewIncomingContext(context.Background(), md),
+		&peer.Peer{Addr: &net.TCPAddr{}, AuthInfo: auth.GatewayInfo{}})
+	if ip := peerIP(gateway); ip != "198.51.100.2" {
+		t.Errorf("Got %s", ip)
+	}
+}
+
+func TestMatchesMethod(t *testing.T) {
+	all := &Rule{Name: "all", By: ByIP}
+	create := &Rule{Name: "create", By: ByIP, Methods: []string{"/hobord.session.DSessionService/Create*", "grpc.health.v1.Health/Check"}}
+	tests := []struct {
+		rule    *Rule
+		method  string
+		matches bool
+	}{
+		{all, "/hobord.session.DSessionService/GetSession", true},
+		{all, "/grpc.health.v1.Health/Check", false},
+		{all, "/grpc.health.v1.Health/Watch", false},
+		{create, "/hobord.session.DSessionService/CreateSession", true},
+		{create, "/hobord.session.DSessionService/GetSession", false},
+		{create, "/grpc.health.v1.Health/Check", true},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.matchesMethod(tt.method); got != tt.matches {
+			t.Errorf("%s %s: Got %v", tt.rule.Name, tt.method, got)
+		}
+	}
+
+	ip, authenticated := SplitRules([]*Rule{all, {Name: "session", By: BySession}, create, {Name: "client", By: ByClient}})
+	if len(ip) != 2 || ip[0] != all || ip[1] != create {
+		t.Errorf("Got ip rules %v", ip)
+	}
+	if len(authenticated) != 2 || authenticated[0].Name != "session" || authenticated[1].Name != "client" {
+		t.Errorf("Got authenticated rules %v", authenticated)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	tests := []struct {
+		config string
+		err    bool
+	}{
+		{`[{"name": "ip", "by": "ip", "rate": 5, "burst": 10}]`, false},
+		{`[{"name": "ip", "by": "peer", "rate": 5, "burst": 10}]`, true},
+		{`[{"name": "ip", "by": "ip", "rate": 5}]`, true},
+		{`[{"name": "ip", "by": "ip", "rate": 5, "burst": 1, "methods": ["["]}]`, true},
+		{`[{"name": "ip", "by": "ip"}, {"name": "ip", "by": "client"}]`, true},
+		{`[{"name": "ip", "by": "ip", "rate": 5, "burst": 10, "clients": {"backend": {}}}]`, true},
+	}
+	file, _ := ioutil.TempFile("", "ratelimits")
+	defer os.Remove(file.Name())
+	for _, tt := range tests {
+		ioutil.WriteFile(file.Name(), []byte(tt.config), 0600)
+		if _, err := LoadRules(file.Name()); (err != nil) != tt.err {
+			t.Errorf("%s: Got error %v", tt.config, err)
+		}
+	}
+}
+
+func TestBucketScript(t *testing.T) {
+	mr := miniredis.RunT(t)
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }}
+	defer pool.Close()
+	start := time.Unix(1600000000, 0)
+	now := start
+	l := NewLimiter(pool, nil, testSessionID)
+	l.now = func() time.Time { return now }
+	conn := pool.Get()
+	defer conn.Close()
+	limit := Limit{Rate: 2, Burst: 2}
+
+	tests := []struct {
+		at      time.Duration
+		allowed bool
+		wait    time.Duration
+	}{
+		{0, true, 0},
+		{0, true, 0},
+		// empty, the next token comes in 1/rate
+		{0, false, 500 * time.Millisecond},
+		// half a token is refilled
+		{250 * time.Millisecond, false, 250 * time.Millisecond},
+		// refilled up to the burst
+		{10 * time.Second, true, 0},
+		{10 * time.Second, true, 0},
+		{10 * time.Second, false, 500 * time.Millisecond},
+	}
+	for i, tt := range tests {
+		now = start.Add(tt.at)
+		allowed, wait, err := l.take(conn, "bucket", limit)
+		if err != nil {
+			t.Fatalf("Got unexpected error: %v", err)
+		}
+		if allowed != tt.allowed || wait != tt.wait {
+			t.Errorf("%d: Got %v %s, want %v %s", i, allowed, wait, tt.allowed, tt.wait)
+		}
+	}
+	// the bucket is kept until it is full again
+	if ttl := mr.TTL("bucket"); ttl != 2*time.Second {
+		t.Errorf("Got ttl %s", ttl)
+	}
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/auth"
+	"github.com/hobord/dsession/logging"
+)
+
+// accessLogPrefix selects the calls of the access log, the RPCs of the DSessionService
+const accessLogPrefix = "/hobord.session.DSessionService/"
+
+// accessLevel is the level of the access line of a status code, the server side failures are errors
+func accessLevel(code codes.Code) logging.Level {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded:
+		return logging.ErrorLevel
+	}
+	return logging.InfoLevel
+}
+
+// logAccess writes the access line of a call, with the hashed session id
+func (s *GrpcRedisImplServer) logAccess(ctx context.Context, method string, req interface{}, start time.Time, err error) {
+	code := status.Code(err)
+	fields := []interface{}{
+		"method", strings.TrimPrefix(method, accessLogPrefix),
+		"code", code.String(),
+		"duration_ms", time.Since(start),
+	}
+	if r, ok := req.(interface{ GetId() string }); ok && r.GetId() != "" {
+		id := r.GetId()
+		if verified, err := s.tokens.verify(id); err == nil {
+			id = verified
+		}
+		fields = append(fields, "session", logging.SessionID(id))
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		fields = append(fields, "client", p.Name())
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		fields = append(fields, "peer", p.Addr.String())
+	}
+	if err != nil {
+		fields = append(fields, "error", status.Convert(err).Message())
+	}
+	logging.Log(ctx, accessLevel(code), "access", fields...)
+}
+
+// AccessLogUnaryInterceptor logs every unary call of the DSessionService. It runs ahead of the
+// authentication, so the rejected calls are logged too, and the principal is tracked for the line.
+func (s *GrpcRedisImplServer) AccessLogUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, accessLogPrefix) {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		ctx = auth.TrackPrincipal(ctx)
+		resp, err := handler(ctx, req)
+		s.logAccess(ctx, info.FullMethod, req, start, err)
+		return resp, err
+	}
+}
+
+// accessStream keeps the request of a server streaming call for the access line
+type accessStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req interface{}
+}
+
+func (a *accessStream) Context() context.Context {
+	return a.ctx
+}
+
+func (a *accessStream) RecvMsg(m interface{}) error {
+	err := a.ServerStream.RecvMsg(m)
+	if err == nil && a.req == nil {
+		a.req = m
+	}
+	return err
+}
+
+// AccessLogStreamInterceptor logs every streaming call of the DSessionService when it ends, it runs
+// ahead of the authentication like AccessLogUnaryInterceptor
+func (s *GrpcRedisImplServer) AccessLogStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !strings.HasPrefix(info.FullMethod, accessLogPrefix) {
+			return handler(srv, ss)
+		}
+		start := time.Now()
+		stream := &accessStream{ServerStream: ss, ctx: auth.TrackPrincipal(ss.Context())}
+		err := handler(srv, stream)
+		s.logAccess(stream.ctx, info.FullMethod, stream.req, start, err)
+		return err
+	}
+}
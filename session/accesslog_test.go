@@ -0,0 +1,87 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/auth"
+	"github.com/hobord/dsession/logging"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logging.Setup(logging.Options{Output: &buf})
+	defer log.SetOutput(os.Stderr)
+
+	s := &GrpcRedisImplServer{tokens: &tokenCodec{}}
+	interceptor := s.AccessLogUnaryInterceptor()
+	ctx := auth.NewContext(logging.NewContext(context.Background(), "req-1"), &auth.Principal{Client: &auth.Client{Name: "backend"}})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "connection refused")
+	}
+	interceptor(ctx, &GetSessionMessage{Id: testID}, &grpc.UnaryServerInfo{FullMethod: "/hobord.session.DSessionService/GetSession"}, handler)
+	interceptor(ctx, &GetJobMessage{Id: "job"}, &grpc.UnaryServerInfo{FullMethod: "/hobord.session.DSessionAdminService/GetJob"}, handler)
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Got %s", buf.String())
+	}
+	want := map[string]interface{}{"level": "error", "msg": "access", "method": "GetSession", "code": "Internal",
+		"client": "backend", "request_id": "req-1", "session": logging.SessionID(testID), "error": "connection refused"}
+	for key, value := range want {
+		if line[key] != value {
+			t.Errorf("Got %s=%v, want %v", key, line[key], value)
+		}
+	}
+	if line["session"] == testID {
+		t.Errorf("Session id is not hashed")
+	}
+}
+
+func TestAccessLogRejectedCall(t *testing.T) {
+	var buf bytes.Buffer
+	logging.Setup(logging.Options{Output: &buf})
+	defer log.SetOutput(os.Stderr)
+
+	// authenticate stands for the authenticator behind the access log, it rejects the calls without a client
+	authenticate := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if req.(*GetSessionMessage).Id == "" {
+			return nil, status.Error(codes.Unauthenticated, "Missing credentials")
+		}
+		return handler(auth.NewContext(ctx, &auth.Principal{Client: &auth.Client{Name: "backend"}}), req)
+	}
+	s := &GrpcRedisImplServer{tokens: &tokenCodec{}}
+	interceptor := s.AccessLogUnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/hobord.session.DSessionService/GetSession"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return authenticate(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &SessionResponse{}, nil
+		})
+	}
+
+	for _, id := range []string{"", testID} {
+		interceptor(context.Background(), &GetSessionMessage{Id: id}, info, handler)
+	}
+	dec := json.NewDecoder(&buf)
+	var rejected, accepted map[string]interface{}
+	if err := dec.Decode(&rejected); err != nil {
+		t.Fatalf("Rejected call has no access line: %v", err)
+	}
+	if rejected["code"] != "Unauthenticated" || rejected["error"] != "Missing credentials" || rejected["client"] != nil {
+		t.Errorf("Got %v", rejected)
+	}
+	if err := dec.Decode(&accepted); err != nil {
+		t.Fatalf("Accepted call has no access line: %v", err)
+	}
+	if accepted["code"] != "OK" || accepted["client"] != "backend" {
+		t.Errorf("Got %v", accepted)
+	}
+}
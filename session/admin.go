@@ -0,0 +1,549 @@
+package session
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/gomodule/redigo/redis"
+	uuid "github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/logging"
+)
+
+const (
+	jobKeyPrefix = "dsession:job:"
+	// jobRetention is how long a finished job stays queryable
+	jobRetention = 24 * time.Hour
+	// scanBatchSize is the COUNT hint of the SCAN calls
+	scanBatchSize = 500
+	// defaultListLimit is the page size of ListSessions without a limit
+	defaultListLimit = 100
+)
+
+const (
+	jobKindBulkInvalidation = "bulk_invalidation"
+	jobKindReencryption     = "reencryption"
+)
+
+// jobRecord is the redis hash representation of a background job
+type jobRecord struct {
+	Kind       string `redis:"kind"`
+	State      int32  `redis:"state"`
+	DryRun     bool   `redis:"dry_run"`
+	Scanned    int64  `redis:"scanned"`
+	Matched    int64  `redis:"matched"`
+	Deleted    int64  `redis:"deleted"`
+	Updated    int64  `redis:"updated"`
+	Error      string `redis:"error"`
+	StartedAt  int64  `redis:"started_at"`
+	FinishedAt int64  `redis:"finished_at"`
+}
+
+func (j *jobRecord) response(id string) *JobResponse {
+	return &JobResponse{
+		Id:         id,
+		Kind:       j.Kind,
+		State:      JobState(j.State),
+		DryRun:     j.DryRun,
+		Scanned:    j.Scanned,
+		Matched:    j.Matched,
+		Deleted:    j.Deleted,
+		Updated:    j.Updated,
+		Error:      j.Error,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+	}
+}
+
+func (s *GrpcRedisImplServer) saveJob(conn redis.Conn, id string, job *jobRecord) error {
+	key := jobKeyPrefix + id
+	if _, err := conn.Do("HSET", redis.Args{}.Add(key).AddFlat(job)...); err != nil {
+		return err
+	}
+	if JobState(job.State) != JobState_JOB_RUNNING {
+		_, err := conn.Do("EXPIRE", key, int64(jobRetention/time.Second))
+		return err
+	}
+	return nil
+}
+
+// StartBulkInvalidation starts a background job which deletes every session matching the filter
+func (s *GrpcRedisImplServer) StartBulkInvalidation(ctx context.Context, in *BulkInvalidationMessage) (*JobResponse, error) {
+	if err := authorizeFilter(ctx, in.Filter); err != nil {
+		return &JobResponse{}, err
+	}
+	conn := s.getConn(ctx)
+	defer conn.Close()
+
+	filter := in.Filter
+	if filter == nil {
+		filter = &SessionFilter{}
+	}
+
+	return s.startJob(conn, jobKindBulkInvalidation, in.DryRun, func(id string, job *jobRecord) {
+		s.runBulkInvalidation(id, job, filter)
+	})
+}
+
+// StartReencryption starts a background job which rewraps the data keys of the sessions with the current
+// master key and encrypts the values written before the data keys with them
+func (s *GrpcRedisImplServer) StartReencryption(ctx context.Context, in *ReencryptionMessage) (*JobResponse, error) {
+	if err := authorizeAllNamespaces(ctx); err != nil {
+		return &JobResponse{}, err
+	}
+	if s.values == nil || s.values.current == "" {
+		return &JobResponse{}, status.Error(codes.FailedPrecondition, "Value encryption is not configured")
+	}
+	conn := s.getConn(ctx)
+	defer conn.Close()
+
+	return s.startJob(conn, jobKindReencryption, in.DryRun, s.runReencryption)
+}
+
+// startJob saves the new job and runs it in the background
+func (s *GrpcRedisImplServer) startJob(conn redis.Conn, kind string, dryRun bool, run func(id string, job *jobRecord)) (*JobResponse, error) {
+	id := uuid.New().String()
+	job := &jobRecord{
+		Kind:      kind,
+		State:     int32(JobState_JOB_RUNNING),
+		DryRun:    dryRun,
+		StartedAt: time.Now().Unix(),
+	}
+	if err := s.saveJob(conn, id, job); err != nil {
+		return &JobResponse{}, err
+	}
+
+	response := job.response(id)
+	go run(id, job)
+
+	return response, nil
+}
+
+// GetJob returns the progress of a background job
+func (s *GrpcRedisImplServer) GetJob(ctx context.Context, in *GetJobMessage) (*JobResponse, error) {
+	if err := authorizeAllNamespaces(ctx); err != nil {
+		return &JobResponse{}, err
+	}
+	conn := s.getConn(ctx)
+	defer conn.Close()
+
+	values, err := redis.Values(conn.Do("HGETALL", jobKeyPrefix+in.Id))
+	if err != nil {
+		return &JobResponse{}, err
+	}
+	if len(values) == 0 {
+		return &JobResponse{}, status.Errorf(codes.NotFound, "Job %s not found", in.Id)
+	}
+
+	job := &jobRecord{}
+	if err := redis.ScanStruct(values, job); err != nil {
+		return &JobResponse{}, err
+	}
+
+	return job.response(in.Id), nil
+}
+
+// ListSessions returns a page of the sessions matching the filter. The page is filled
+// by whole SCAN steps, so it may hold somewhat more sessions than the limit.
+func (s *GrpcRedisImplServer) ListSessions(ctx context.Context, in *ListSessionsMessage) (*ListSessionsResponse, error) {
+	if err := authorizeFilter(ctx, in.Filter); err != nil {
+		return &ListSessionsResponse{}, err
+	}
+	conn := s.getConn(ctx)
+	defer conn.Close()
+
+	filter := in.Filter
+	if filter == nil {
+		filter = &SessionFilter{}
+	}
+	limit := int(in.Limit)
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxBatchSize {
+		return &ListSessionsResponse{}, status.Errorf(codes.InvalidArgument, "Limit is larger than %d", maxBatchSize)
+	}
+
+	cursor := in.Cursor
+	if cursor == "" {
+		cursor = "0"
+	}
+	var matched []*storedSession
+	for {
+		next, batch, err := s.scanPage(conn, cursor)
+		if err != nil {
+			if _, ok := err.(redis.Error); ok {
+				return &ListSessionsResponse{}, status.Errorf(codes.InvalidArgument, "Invalid cursor %s", in.Cursor)
+			}
+			return &ListSessionsResponse{}, err
+		}
+		for _, stored := range batch {
+			if matchSessionFilter(filter, stored) {
+				matched = append(matched, stored)
+			}
+		}
+		cursor = next
+		if cursor == "0" || len(matched) >= limit || ctx.Err() != nil {
+			break
+		}
+	}
+
+	for _, stored := range matched {
+		conn.Send("TTL", stored.ID)
+	}
+	if err := conn.Flush(); err != nil {
+		return &ListSessionsResponse{}, err
+	}
+	res := &ListSessionsResponse{}
+	for _, stored := range matched {
+		remaining, err := redis.Int64(conn.Receive())
+		if err != nil {
+			return &ListSessionsResponse{}, err
+		}
+		if remaining == -2 {
+			// expired since the scan
+			continue
+		}
+		stored.Values = visibleValues(ctx, stored.Values)
+		res.Sessions = append(res.Sessions, stored.summary(s.tokens, remaining))
+	}
+	if cursor != "0" {
+		res.Cursor = cursor
+	}
+
+	return res, nil
+}
+
+func (s *GrpcRedisImplServer) runBulkInvalidation(id string, job *jobRecord, filter *SessionFilter) {
+	conn := s.RedisPool.Get()
+	defer conn.Close()
+
+	err := s.scanSessions(conn, func(batch []*storedSession) error {
+		var matched []*storedSession
+		for _, stored := range batch {
+			if matchSessionFilter(filter, stored) {
+				matched = append(matched, stored)
+			}
+		}
+		job.Scanned += int64(len(batch))
+		job.Matched += int64(len(matched))
+
+		if !job.DryRun && len(matched) > 0 {
+			for _, stored := range matched {
+				conn.Send("DEL", stored.ID)
+			}
+			if err := conn.Flush(); err != nil {
+				return err
+			}
+			var deleted int64
+			for range matched {
+				n, err := redis.Int64(conn.Receive())
+				if err != nil {
+					return err
+				}
+				deleted += n
+			}
+
+			events := make([]*SessionEvent, 0, len(matched))
+			for _, stored := range matched {
+				if err := conn.Send("DEL", expiryKeyPrefix+stored.ID); err != nil {
+					return err
+				}
+				ev := &SessionEvent{
+					Type:      SessionEventType_SESSION_INVALIDATED,
+					Id:        stored.ID,
+					Namespace: stored.Namespace,
+				}
+				if err := s.sendEvent(conn, ev); err != nil {
+					return err
+				}
+				events = append(events, ev)
+			}
+			if err := runPipeline(conn); err != nil {
+				return err
+			}
+			for _, ev := range events {
+				countEvent(ev)
+			}
+			// counted once the invalidation events are written too
+			job.Deleted += deleted
+		}
+
+		return s.saveJob(conn, id, job)
+	})
+
+	s.finishJob(conn, id, job, err)
+}
+
+// runPipeline flushes the queued commands, it returns the first error of their replies
+func runPipeline(conn redis.Conn) error {
+	replies, err := redis.Values(conn.Do(""))
+	if err != nil {
+		return err
+	}
+	for _, reply := range replies {
+		if err, ok := reply.(redis.Error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// finishJob saves the final state of the job
+func (s *GrpcRedisImplServer) finishJob(conn redis.Conn, id string, job *jobRecord, err error) {
+	job.State = int32(JobState_JOB_DONE)
+	if err != nil {
+		logging.Error(context.Background(), "Job failed", "kind", job.Kind, "job", id, "error", err)
+		job.State = int32(JobState_JOB_FAILED)
+		job.Error = err.Error()
+	}
+	job.FinishedAt = time.Now().Unix()
+	if err := s.saveJob(conn, id, job); err != nil {
+		logging.Error(context.Background(), "Failed to save the job", "job", id, "error", err)
+	}
+}
+
+// reencryptScript replaces the fields when every one of them still has the old value,
+// ARGV holds field, old value, new value triplets, an empty old value is a missing field.
+// The data key and the values sealed by it are replaced together.
+var reencryptScript = redis.NewScript(1, `
+for i = 1, #ARGV, 3 do
+	if (redis.call('HGET', KEYS[1], ARGV[i]) or '') ~= ARGV[i + 1] then
+		return 0
+	end
+end
+for i = 1, #ARGV, 3 do
+	redis.call('HSET', KEYS[1], ARGV[i], ARGV[i + 2])
+end
+return 1
+`)
+
+func (s *GrpcRedisImplServer) runReencryption(id string, job *jobRecord) {
+	conn := s.RedisPool.Get()
+	defer conn.Close()
+
+	cursor := "0"
+	var err error
+	for {
+		var keys []string
+		cursor, keys, err = scanKeys(conn, cursor)
+		if err != nil {
+			break
+		}
+		if err = s.reencryptBatch(conn, keys, job); err != nil {
+			break
+		}
+		if err = s.saveJob(conn, id, job); err != nil {
+			break
+		}
+		if cursor == "0" {
+			break
+		}
+	}
+
+	s.finishJob(conn, id, job, err)
+}
+
+// reencryptBatch rewrites the stale data keys and values of the sessions
+func (s *GrpcRedisImplServer) reencryptBatch(conn redis.Conn, keys []string, job *jobRecord) error {
+	for _, key := range keys {
+		conn.Send("HGETALL", key)
+	}
+	if err := conn.Flush(); err != nil {
+		return err
+	}
+	raw := make([]map[string]string, len(keys))
+	for i := range keys {
+		fields, err := redis.StringMap(conn.Receive())
+		if err != nil {
+			if _, ok := err.(redis.Error); ok {
+				continue
+			}
+			return err
+		}
+		if _, ok := fields[ttlField]; ok {
+			raw[i] = fields
+		}
+	}
+
+	for i, key := range keys {
+		if raw[i] == nil {
+			continue
+		}
+		job.Scanned++
+		args, err := s.reencryptArgs(key, raw[i])
+		if err != nil {
+			logging.Warn(context.Background(), "Skipping session", "session", logging.SessionID(key), "error", err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		job.Matched++
+		if job.DryRun {
+			continue
+		}
+		// a session changed meanwhile is left to the next run
+		updated, err := redis.Int(reencryptScript.Do(conn, redis.Args{key}.AddFlat(args)...))
+		if err != nil {
+			return err
+		}
+		job.Updated += int64(updated)
+	}
+	return nil
+}
+
+// reencryptArgs returns the field, old value, new value triplets of the data key wrapped by
+// an old master key and of the values not sealed by the data key of the session
+func (s *GrpcRedisImplServer) reencryptArgs(id string, fields map[string]string) ([]string, error) {
+	var stale []string
+	for key, stored := range fields {
+		if !isMetaField(key) && s.values.stale(stored) {
+			stale = append(stale, key)
+		}
+	}
+	wrapped := fields[keyField]
+	current := wrapped
+	var err error
+	switch {
+	case wrapped == "" && len(stale) > 0:
+		current, err = s.values.newDataKey(id)
+	case wrapped != "" && s.values.staleKey(wrapped):
+		current, err = s.values.rewrap(id, wrapped)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var args []string
+	if current != wrapped {
+		args = append(args, keyField, wrapped, current)
+	}
+	if len(stale) == 0 {
+		return args, nil
+	}
+	dataKey, err := s.values.dataKey(id, current)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(stale)
+	for _, key := range stale {
+		plain, err := s.values.open(dataKey, id, key, fields[key])
+		if err != nil {
+			return nil, err
+		}
+		sealed, err := dataKey.seal(key, plain)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, key, fields[key], sealed)
+	}
+	return args, nil
+}
+
+// scanSessions iterates over every session in the database and calls fn with each loaded batch
+func (s *GrpcRedisImplServer) scanSessions(conn redis.Conn, fn func(batch []*storedSession) error) error {
+	cursor := "0"
+	for {
+		next, batch, err := s.scanPage(conn, cursor)
+		if err != nil {
+			return err
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		if next == "0" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// scanKeys runs one SCAN step of the session keys from the cursor
+func scanKeys(conn redis.Conn, cursor string) (string, []string, error) {
+	res, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", sessionKeyPattern, "COUNT", scanBatchSize))
+	if err != nil {
+		return "", nil, err
+	}
+	var next string
+	var keys []string
+	if _, err := redis.Scan(res, &next, &keys); err != nil {
+		return "", nil, err
+	}
+	return next, keys, nil
+}
+
+// scanPage runs one SCAN step from the cursor and loads the found sessions
+func (s *GrpcRedisImplServer) scanPage(conn redis.Conn, cursor string) (string, []*storedSession, error) {
+	next, keys, err := scanKeys(conn, cursor)
+	if err != nil {
+		return "", nil, err
+	}
+
+	batch, err := s.loadSessions(conn, keys)
+	if err != nil {
+		return "", nil, err
+	}
+	return next, batch, nil
+}
+
+// loadSessions reads the given sessions in one pipeline, missing and non-session keys are skipped
+func (s *GrpcRedisImplServer) loadSessions(conn redis.Conn, ids []string) ([]*storedSession, error) {
+	for _, id := range ids {
+		if err := conn.Send("HGETALL", id); err != nil {
+			return nil, err
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		return nil, err
+	}
+
+	var sessions []*storedSession
+	for _, id := range ids {
+		fields, err := redis.StringMap(conn.Receive())
+		if err != nil {
+			if _, ok := err.(redis.Error); ok {
+				continue
+			}
+			return nil, err
+		}
+		if _, ok := fields[ttlField]; !ok {
+			continue
+		}
+		stored, err := parseStoredSession(id, fields, s.values)
+		if err != nil {
+			logging.Warn(context.Background(), "Skipping session", "session", logging.SessionID(id), "error", err)
+			continue
+		}
+		sessions = append(sessions, stored)
+	}
+
+	return sessions, nil
+}
+
+// matchSessionFilter reports whether the session satisfies every condition of the filter
+func matchSessionFilter(filter *SessionFilter, stored *storedSession) bool {
+	if filter.Namespace != "" && filter.Namespace != stored.Namespace {
+		return false
+	}
+	if filter.CreatedBefore > 0 && (stored.CreatedAt == 0 || stored.CreatedAt >= filter.CreatedBefore) {
+		return false
+	}
+	for _, key := range filter.HasKeys {
+		if _, ok := stored.Values[key]; !ok {
+			return false
+		}
+	}
+	for key, want := range filter.ValueEquals {
+		got, ok := stored.Values[key]
+		if !ok || !proto.Equal(got, want) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package session
+
+import (
+	"testing"
+
+	st "github.com/golang/protobuf/ptypes/struct"
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestMatchSessionFilter(t *testing.T) {
+	stored := &storedSession{
+		ID:        "8f60aaef-a0bd-4c55-ab49-00c4ed5a4091",
+		Namespace: "shop",
+		CreatedAt: 1000,
+		Values: map[string]*st.Value{
+			"foo": {Kind: &st.Value_NumberValue{NumberValue: 15}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		filter *SessionFilter
+		want   bool
+	}{
+		{"empty", &SessionFilter{}, true},
+		{"namespace", &SessionFilter{Namespace: "shop"}, true},
+		{"other namespace", &SessionFilter{Namespace: "blog"}, false},
+		{"created before", &SessionFilter{CreatedBefore: 1001}, true},
+		{"created after", &SessionFilter{CreatedBefore: 1000}, false},
+		{"has key", &SessionFilter{HasKeys: []string{"foo"}}, true},
+		{"missing key", &SessionFilter{HasKeys: []string{"foo", "bar"}}, false},
+		{"value equals", &SessionFilter{ValueEquals: map[string]*st.Value{
+			"foo": {Kind: &st.Value_NumberValue{NumberValue: 15}},
+		}}, true},
+		{"value differs", &SessionFilter{ValueEquals: map[string]*st.Value{
+			"foo": {Kind: &st.Value_StringValue{StringValue: "15"}},
+		}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchSessionFilter(tt.filter, stored); got != tt.want {
+			t.Errorf("%s: matchSessionFilter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRunBulkInvalidation(t *testing.T) {
+	mr, pool := newTestRedis(t)
+	s := &GrpcRedisImplServer{RedisPool: pool}
+	shop := []string{"8f60aaef-a0bd-4c55-ab49-00c4ed5a4091", "9a7b1c2d-a0bd-4c55-ab49-00c4ed5a4091"}
+	blog := "1c2d3e4f-a0bd-4c55-ab49-00c4ed5a4091"
+	for _, id := range shop {
+		mr.HSet(id, namespaceField, "shop", ttlField, "60")
+		mr.Set(expiryKeyPrefix+id, "shop")
+	}
+	mr.HSet(blog, namespaceField, "blog", ttlField, "60")
+
+	loadJob := func(id string) *jobRecord {
+		conn := pool.Get()
+		defer conn.Close()
+		values, err := redis.Values(conn.Do("HGETALL", jobKeyPrefix+id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		job := &jobRecord{}
+		if err := redis.ScanStruct(values, job); err != nil {
+			t.Fatal(err)
+		}
+		return job
+	}
+
+	s.runBulkInvalidation("done", &jobRecord{Kind: jobKindBulkInvalidation}, &SessionFilter{Namespace: "shop"})
+	job := loadJob("done")
+	if JobState(job.State) != JobState_JOB_DONE || job.Scanned != 3 || job.Matched != 2 || job.Deleted != 2 {
+		t.Errorf("Got job %+v", job)
+	}
+	for _, id := range shop {
+		if mr.Exists(id) || mr.Exists(expiryKeyPrefix+id) {
+			t.Errorf("Session %s is not deleted", id)
+		}
+	}
+	if !mr.Exists(blog) {
+		t.Errorf("Session of the other namespace is deleted")
+	}
+	if entries, err := mr.Stream(eventStream); err != nil || len(entries) != 2 {
+		t.Errorf("Got stream %v, %v", entries, err)
+	}
+
+	// the failed events are not counted as invalidated
+	mr.Del(eventStream)
+	mr.Set(eventStream, "broken")
+	s.runBulkInvalidation("failed", &jobRecord{Kind: jobKindBulkInvalidation}, &SessionFilter{Namespace: "blog"})
+	job = loadJob("failed")
+	if JobState(job.State) != JobState_JOB_FAILED || job.Matched != 1 || job.Deleted != 0 || job.Error == "" {
+		t.Errorf("Got job %+v", job)
+	}
+}
@@ -0,0 +1,365 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	proto "github.com/golang/protobuf/proto"
+	st "github.com/golang/protobuf/ptypes/struct"
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/auth"
+	"github.com/hobord/dsession/logging"
+)
+
+const (
+	// auditStream is the redis stream of every audit record
+	auditStream = "dsession:audit"
+	// auditSessionPrefix + session id is the redis stream of the audit records of one session
+	auditSessionPrefix = "dsession:audit:"
+	// auditSessionMaxLen limits the records kept for one session
+	auditSessionMaxLen = 1000
+	// auditRetention is how long the records of a session are kept after its last change
+	auditRetention = 30 * 24 * time.Hour
+	// defaultAuditLimit is the page size of QueryAuditLog
+	defaultAuditLimit = 100
+)
+
+// auditedMethods are the calls recorded in the audit log, the mutations of the sessions
+var auditedMethods = map[string]bool{
+	"/hobord.session.DSessionService/CreateSession":              true,
+	"/hobord.session.DSessionService/AddValueToSession":          true,
+	"/hobord.session.DSessionService/AddValuesToSession":         true,
+	"/hobord.session.DSessionService/InvalidateSessionValue":     true,
+	"/hobord.session.DSessionService/InvalidateSessionValues":    true,
+	"/hobord.session.DSessionService/InvalidateSession":          true,
+	"/hobord.session.DSessionService/RenewSession":               true,
+	"/hobord.session.DSessionAdminService/StartBulkInvalidation": true,
+	"/hobord.session.DSessionAdminService/StartReencryption":     true,
+}
+
+// importSessionsMethod is recorded by ImportSessions for every imported session
+const importSessionsMethod = "/hobord.session.DSessionAdminService/ImportSessions"
+
+// AuditOptions configures the audit log
+type AuditOptions struct {
+	File          string // JSON Lines file of the records instead of the redis streams
+	IncludeValues bool   // record the written values sealed by the value encryption keys, by default only their keys
+	MaxLen        int64  // approximate length of the global redis stream, 0 = unlimited
+}
+
+// auditSink stores the audit records
+type auditSink interface {
+	write(rec *AuditRecord) error
+}
+
+// redisAuditSink appends the records to the global and to the per-session streams
+type redisAuditSink struct {
+	pool   *redis.Pool
+	maxLen int64
+}
+
+func (r *redisAuditSink) write(rec *AuditRecord) error {
+	data, err := proto.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	args := redis.Args{}.Add(auditStream)
+	if r.maxLen > 0 {
+		args = args.Add("MAXLEN", "~", r.maxLen)
+	}
+	conn.Send("XADD", args.Add("*", "record", data)...)
+	if rec.SessionId != "" {
+		key := auditSessionPrefix + rec.SessionId
+		conn.Send("XADD", key, "MAXLEN", "~", auditSessionMaxLen, "*", "record", data)
+		conn.Send("PEXPIRE", key, int64(auditRetention/time.Millisecond))
+	}
+	_, err = conn.Do("")
+	return err
+}
+
+// fileAuditSink appends the records to a file as JSON lines
+type fileAuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+var auditMarshaler = &jsonpb.Marshaler{OrigName: true}
+
+func (f *fileAuditSink) write(rec *AuditRecord) error {
+	line, err := auditMarshaler.MarshalToString(rec)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = f.file.WriteString(line + "\n")
+	return err
+}
+
+// auditLog records the mutations of the sessions
+type auditLog struct {
+	sink          auditSink
+	includeValues bool
+}
+
+// StartAudit enables the audit log, the records are written by AuditUnaryInterceptor
+func (s *GrpcRedisImplServer) StartAudit(opts AuditOptions) error {
+	if opts.IncludeValues && !s.values.enabled() {
+		return fmt.Errorf("The audit log of the values needs the value encryption keys")
+	}
+	a := &auditLog{includeValues: opts.IncludeValues}
+	if opts.File != "" {
+		file, err := os.OpenFile(opts.File, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		a.sink = &fileAuditSink{file: file}
+	} else {
+		a.sink = &redisAuditSink{pool: s.RedisPool, maxLen: opts.MaxLen}
+	}
+	s.audit = a
+	return nil
+}
+
+// record writes the audit record of a call, a failure is only logged
+func (s *GrpcRedisImplServer) record(ctx context.Context, method, id string, keys []string, values map[string]*st.Value, err error) {
+	if s.audit == nil {
+		return
+	}
+	rec := &AuditRecord{
+		Time:      nowMillis(),
+		Method:    method,
+		SessionId: id,
+		Keys:      keys,
+	}
+	if s.audit.includeValues && len(values) > 0 {
+		sealed, err := s.sealAuditValues(id, values)
+		if err != nil {
+			logging.Error(ctx, "Failed to seal the audited values", "method", method, "error", err)
+		}
+		rec.Values = sealed
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		rec.Client = p.Name()
+		rec.Subject = p.Subject
+		rec.Via = p.Via
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		rec.Peer = p.Addr.String()
+	}
+	if err != nil {
+		stat := status.Convert(err)
+		rec.Code = int32(stat.Code())
+		rec.Error = stat.Message()
+	}
+	if err := s.audit.sink.write(rec); err != nil {
+		logging.Error(ctx, "Failed to write the audit record", "method", method, "error", err)
+	}
+}
+
+func auditAdditionalData(id, key string) []byte {
+	return []byte("audit\x00" + id + "\x00" + key)
+}
+
+// sealAuditValues returns the values sealed by the master key as string values,
+// the records outlive the sessions and their data keys
+func (s *GrpcRedisImplServer) sealAuditValues(id string, values map[string]*st.Value) (map[string]*st.Value, error) {
+	sealed := make(map[string]*st.Value, len(values))
+	for key, value := range values {
+		text, err := s.values.sealMaster([]byte(proto.MarshalTextString(value)), auditAdditionalData(id, key))
+		if err != nil {
+			return nil, err
+		}
+		sealed[key] = &st.Value{Kind: &st.Value_StringValue{StringValue: text}}
+	}
+	return sealed, nil
+}
+
+// openAuditValues returns the values of sealAuditValues, the ones which cannot be opened are dropped
+func (s *GrpcRedisImplServer) openAuditValues(ctx context.Context, id string, sealed map[string]*st.Value) map[string]*st.Value {
+	if len(sealed) == 0 {
+		return nil
+	}
+	values := make(map[string]*st.Value, len(sealed))
+	for key, value := range sealed {
+		text, err := s.values.openMaster(value.GetStringValue(), auditAdditionalData(id, key))
+		if err == nil {
+			v := &st.Value{}
+			if err = proto.UnmarshalText(string(text), v); err == nil {
+				values[key] = v
+				continue
+			}
+		}
+		logging.Warn(ctx, "Cannot open the audited value", "key", key, "error", err)
+	}
+	return values
+}
+
+// auditedRequest returns the session id, the keys and the values of a request
+func (s *GrpcRedisImplServer) auditedRequest(req, resp interface{}) (string, []string, map[string]*st.Value) {
+	var id string
+	if r, ok := req.(interface{ GetId() string }); ok {
+		id = r.GetId()
+	}
+	if r, ok := resp.(*SessionResponse); ok && id == "" {
+		id = r.Id
+	}
+	if id != "" {
+		// an invalid id is not recorded, it must not name a session stream
+		id, _ = s.tokens.verify(id)
+	}
+
+	var keys []string
+	var values map[string]*st.Value
+	switch r := req.(type) {
+	case *AddValueToSessionMessage:
+		keys = []string{r.Key}
+		values = map[string]*st.Value{r.Key: r.Value}
+	case *AddValuesToSessionMessage:
+		for key := range r.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values = r.Values
+	case *InvalidateSessionValueMessage:
+		keys = []string{r.Key}
+	case *InvalidateSessionValuesMessage:
+		keys = r.Keys
+	}
+	return id, keys, values
+}
+
+// AuditUnaryInterceptor records the unary mutations of the sessions when the audit log is enabled
+func (s *GrpcRedisImplServer) AuditUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if s.audit != nil && auditedMethods[info.FullMethod] {
+			id, keys, values := s.auditedRequest(req, resp)
+			s.record(ctx, info.FullMethod, id, keys, values, err)
+		}
+		return resp, err
+	}
+}
+
+// QueryAuditLog returns a page of the audit records, newest first
+func (s *GrpcRedisImplServer) QueryAuditLog(ctx context.Context, in *AuditQueryMessage) (*AuditLogResponse, error) {
+	if err := authorizeAllNamespaces(ctx); err != nil {
+		return &AuditLogResponse{}, err
+	}
+	if s.audit == nil {
+		return &AuditLogResponse{}, status.Error(codes.FailedPrecondition, "Audit log is not enabled")
+	}
+	if _, ok := s.audit.sink.(*redisAuditSink); !ok {
+		return &AuditLogResponse{}, status.Error(codes.FailedPrecondition, "Audit log is written to a file")
+	}
+	limit := int(in.Limit)
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxBatchSize {
+		return &AuditLogResponse{}, status.Errorf(codes.InvalidArgument, "Limit is larger than %d", maxBatchSize)
+	}
+
+	stream := auditStream
+	if in.SessionId != "" {
+		id, err := s.tokens.verify(in.SessionId)
+		if err != nil {
+			return &AuditLogResponse{}, err
+		}
+		stream = auditSessionPrefix + id
+	}
+	start, end := "-", "+"
+	if in.Since > 0 {
+		start = strconv.FormatInt(in.Since, 10)
+	}
+	if in.Until > 0 {
+		end = strconv.FormatInt(in.Until, 10)
+	}
+	if in.Cursor != "" {
+		before, err := beforeStreamID(in.Cursor)
+		if err != nil {
+			return &AuditLogResponse{}, status.Errorf(codes.InvalidArgument, "Invalid cursor %q", in.Cursor)
+		}
+		end = before
+	}
+
+	conn := s.getConn(ctx)
+	defer conn.Close()
+
+	res := &AuditLogResponse{}
+	for {
+		items, err := redis.Values(conn.Do("XREVRANGE", stream, end, start, "COUNT", limit))
+		if err != nil {
+			return &AuditLogResponse{}, err
+		}
+		entries, err := parseStreamRange(items)
+		if err != nil {
+			return &AuditLogResponse{}, err
+		}
+		for _, entry := range entries {
+			rec := &AuditRecord{}
+			if err := proto.Unmarshal([]byte(entry.Fields["record"]), rec); err != nil {
+				logging.Warn(ctx, "Invalid audit record", "record", entry.ID, "error", err)
+				continue
+			}
+			if in.Client != "" && rec.Client != in.Client {
+				continue
+			}
+			rec.Id = entry.ID
+			// the caller gets only the keys and the values which it may read
+			rec.Keys = visibleKeys(ctx, rec.Keys)
+			rec.Values = visibleValues(ctx, s.openAuditValues(ctx, rec.SessionId, rec.Values))
+			if rec.SessionId != "" {
+				rec.SessionId = s.tokens.sign(rec.SessionId)
+			}
+			res.Records = append(res.Records, rec)
+			if len(res.Records) == limit {
+				res.Cursor = entry.ID
+				return res, nil
+			}
+		}
+		if len(entries) < limit {
+			return res, nil
+		}
+		if end, err = beforeStreamID(entries[len(entries)-1].ID); err != nil {
+			// the oldest possible entry is reached
+			return res, nil
+		}
+	}
+}
+
+// beforeStreamID returns the stream id right before the id, the range bound which excludes it
+func beforeStreamID(id string) (string, error) {
+	parts := strings.SplitN(id, "-", 2)
+	ms, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || len(parts) != 2 {
+		return "", fmt.Errorf("Invalid stream id %s", id)
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("Invalid stream id %s", id)
+	}
+	if seq > 0 {
+		return fmt.Sprintf("%d-%d", ms, seq-1), nil
+	}
+	if ms == 0 {
+		return "", fmt.Errorf("No stream id before %s", id)
+	}
+	return fmt.Sprintf("%d-%d", ms-1, uint64(math.MaxUint64)), nil
+}
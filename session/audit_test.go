@@ -0,0 +1,145 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	st "github.com/golang/protobuf/ptypes/struct"
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/auth"
+)
+
+func TestAuditFileSink(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "audit")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "audit.jsonl")
+
+	s := &GrpcRedisImplServer{tokens: &tokenCodec{}}
+	if err := s.StartAudit(AuditOptions{File: file}); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	interceptor := s.AuditUnaryInterceptor()
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Client: &auth.Client{Name: "backend"}, Via: auth.ViaAPIKey})
+
+	calls := []struct {
+		method string
+		req    interface{}
+		err    error
+	}{
+		{"/hobord.session.DSessionService/AddValuesToSession", &AddValuesToSessionMessage{Id: testID, Values: map[string]*st.Value{
+			"role": {Kind: &st.Value_StringValue{StringValue: "admin"}},
+			"cart": {Kind: &st.Value_NumberValue{NumberValue: 2}},
+		}}, nil},
+		{"/hobord.session.DSessionService/GetSession", &GetSessionMessage{Id: testID}, nil},
+		{"/hobord.session.DSessionService/InvalidateSessionValue", &InvalidateSessionValueMessage{Id: testID, Key: "role"},
+			status.Error(codes.PermissionDenied, "Client backend may not change key role")},
+	}
+	for _, call := range calls {
+		interceptor(ctx, call.req, &grpc.UnaryServerInfo{FullMethod: call.method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &SessionResponse{}, call.err
+		})
+	}
+
+	data, _ := ioutil.ReadFile(file)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Got records %q", data)
+	}
+	var first, second map[string]interface{}
+	json.Unmarshal([]byte(lines[0]), &first)
+	json.Unmarshal([]byte(lines[1]), &second)
+	if first["client"] != "backend" || first["via"] != "api_key" || first["session_id"] != testID {
+		t.Errorf("Got record %s", lines[0])
+	}
+	if keys, _ := first["keys"].([]interface{}); len(keys) != 2 || keys[0] != "cart" {
+		t.Errorf("Got keys %v", first["keys"])
+	}
+	if _, ok := first["values"]; ok {
+		t.Errorf("Values are not redacted: %s", lines[0])
+	}
+	if second["code"] != float64(codes.PermissionDenied) || second["error"] == "" {
+		t.Errorf("Got record %s", lines[1])
+	}
+
+	if _, err := s.QueryAuditLog(ctx, &AuditQueryMessage{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Got %v", err)
+	}
+}
+
+func TestBeforeStreamID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+		err  bool
+	}{
+		{"1560000000000-5", "1560000000000-4", false},
+		{"1560000000000-0", "1559999999999-18446744073709551615", false},
+		{"0-0", "", true},
+		{"abc", "", true},
+	}
+	for _, tt := range tests {
+		got, err := beforeStreamID(tt.id)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("%s: Got %s, %v", tt.id, got, err)
+		}
+	}
+}
+
+func TestAuditValues(t *testing.T) {
+	_, pool := newTestRedis(t)
+	c, _ := newValueCipher("k1=" + testKey('a'))
+	s := &GrpcRedisImplServer{RedisPool: pool, tokens: &tokenCodec{}}
+	if err := s.StartAudit(AuditOptions{IncludeValues: true}); err == nil {
+		t.Errorf("Values are audited without encryption keys")
+	}
+	s.values = c
+	if err := s.StartAudit(AuditOptions{IncludeValues: true}); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	interceptor := s.AuditUnaryInterceptor()
+	for _, id := range []string{testID, "dsession:audit"} {
+		req := &AddValuesToSessionMessage{Id: id, Values: map[string]*st.Value{
+			"auth_token": {Kind: &st.Value_StringValue{StringValue: "secret"}},
+			"cart":       {Kind: &st.Value_NumberValue{NumberValue: 2}},
+		}}
+		interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: "/hobord.session.DSessionService/AddValuesToSession"},
+			func(ctx context.Context, req interface{}) (interface{}, error) { return &SessionResponse{}, nil })
+	}
+
+	conn := pool.Get()
+	defer conn.Close()
+	raw, _ := redis.Values(conn.Do("XRANGE", auditStream, "-", "+"))
+	if len(raw) != 2 || strings.Contains(fmt.Sprint(raw), "secret") {
+		t.Errorf("Got raw records %q", raw)
+	}
+	if n, _ := redis.Int(conn.Do("EXISTS", auditSessionPrefix+"dsession:audit")); n != 0 {
+		t.Errorf("Invalid session id got a session stream")
+	}
+
+	a, _ := auth.NewAuthenticator(&auth.Policy{
+		Clients:  []*auth.Client{{Name: "admin", Anonymous: true, Methods: []string{"*"}}},
+		KeyRules: []*auth.KeyRule{{Keys: []string{"auth_*"}, Private: true}},
+	})
+	p, _ := a.Authenticate(context.Background(), "/hobord.session.DSessionAdminService/QueryAuditLog")
+	res, err := s.QueryAuditLog(auth.NewContext(context.Background(), p), &AuditQueryMessage{SessionId: testID})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if len(res.Records) != 1 {
+		t.Fatalf("Got records %v", res.Records)
+	}
+	rec := res.Records[0]
+	if len(rec.Keys) != 1 || rec.Keys[0] != "cart" || len(rec.Values) != 1 || rec.Values["cart"].GetNumberValue() != 2 {
+		t.Errorf("Got record %v", rec)
+	}
+}
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"context"
+
+	st "github.com/golang/protobuf/ptypes/struct"
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/auth"
+)
+
+// authorizeNamespace checks that the caller may use the sessions of the namespace,
+// every call is allowed when the server has no authentication
+func authorizeNamespace(ctx context.Context, namespace string) error {
+	if p, ok := auth.FromContext(ctx); ok && !p.AllowsNamespace(namespace) {
+		return status.Errorf(codes.PermissionDenied, "Client %s may not use namespace %q", p.Name(), namespace)
+	}
+	return nil
+}
+
+// authorizeAllNamespaces checks that the caller is not restricted to some namespaces,
+// it guards the calls which reach the sessions of every namespace
+func authorizeAllNamespaces(ctx context.Context) error {
+	if p, ok := auth.FromContext(ctx); ok && !p.AllNamespaces() {
+		return status.Errorf(codes.PermissionDenied, "Client %s is restricted to some namespaces", p.Name())
+	}
+	return nil
+}
+
+// authorizeFilter checks the namespace of a session filter, an empty one matches every namespace
+func authorizeFilter(ctx context.Context, filter *SessionFilter) error {
+	if filter == nil || filter.Namespace == "" {
+		return authorizeAllNamespaces(ctx)
+	}
+	return authorizeNamespace(ctx, filter.Namespace)
+}
+
+// authorizeSession checks the namespace of the stored session,
+// it is read only for the callers restricted to some namespaces
+func authorizeSession(ctx context.Context, conn redis.Conn, id string) error {
+	if p, ok := auth.FromContext(ctx); !ok || p.AllNamespaces() {
+		return nil
+	}
+	namespace, err := sessionNamespace(conn, id)
+	if err != nil {
+		return err
+	}
+	return authorizeNamespace(ctx, namespace)
+}
+
+// authorizeKeys checks that the caller may change or delete the session keys
+func authorizeKeys(ctx context.Context, keys []string) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	for _, key := range keys {
+		if !p.CanWriteKey(key) {
+			return status.Errorf(codes.PermissionDenied, "Client %s may not change key %s", p.Name(), key)
+		}
+	}
+	return nil
+}
+
+// visibleValues removes the keys which are hidden from the caller
+func visibleValues(ctx context.Context, values map[string]*st.Value) map[string]*st.Value {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return values
+	}
+	for key := range values {
+		if !p.CanReadKey(key) {
+			delete(values, key)
+		}
+	}
+	return values
+}
+
+// visibleKeys returns the keys which the caller may read
+func visibleKeys(ctx context.Context, keys []string) []string {
+	p, ok := auth.FromContext(ctx)
+	if !ok {
+		return keys
+	}
+	var visible []string
+	for _, key := range keys {
+		if p.CanReadKey(key) {
+			visible = append(visible, key)
+		}
+	}
+	return visible
+}
+
+// visibleEvent returns the event without the keys which are hidden from the caller,
+// nil if every key of a value event is hidden
+func visibleEvent(ctx context.Context, ev *SessionEvent) *SessionEvent {
+	p, ok := auth.FromContext(ctx)
+	if !ok || len(ev.Keys) == 0 {
+		return ev
+	}
+	// the event is shared by the watchers, it is copied before the change
+	visible := *ev
+	visible.Keys = nil
+	visible.Values = nil
+	for _, key := range ev.Keys {
+		if !p.CanReadKey(key) {
+			continue
+		}
+		visible.Keys = append(visible.Keys, key)
+		if value, ok := ev.Values[key]; ok {
+			if visible.Values == nil {
+				visible.Values = make(map[string]*st.Value)
+			}
+			visible.Values[key] = value
+		}
+	}
+	if len(visible.Keys) == 0 {
+		return nil
+	}
+	return &visible
+}
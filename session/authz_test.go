@@ -0,0 +1,70 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	st "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/auth"
+)
+
+func TestAuthorizeNamespace(t *testing.T) {
+	shop := auth.NewContext(context.Background(), &auth.Principal{Client: &auth.Client{Name: "browser", Namespaces: []string{"shop"}}})
+	backend := auth.NewContext(context.Background(), &auth.Principal{Client: &auth.Client{Name: "backend"}})
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		filter *SessionFilter
+		code   codes.Code
+	}{
+		{"no authentication", context.Background(), nil, codes.OK},
+		{"unrestricted", backend, &SessionFilter{Namespace: "admin"}, codes.OK},
+		{"allowed namespace", shop, &SessionFilter{Namespace: "shop"}, codes.OK},
+		{"other namespace", shop, &SessionFilter{Namespace: "admin"}, codes.PermissionDenied},
+		{"every namespace", shop, &SessionFilter{}, codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		if code := status.Code(authorizeFilter(tt.ctx, tt.filter)); code != tt.code {
+			t.Errorf("%s: Got %s, want %s", tt.name, code, tt.code)
+		}
+	}
+}
+
+func TestVisibleEvent(t *testing.T) {
+	policy := &auth.Policy{
+		Clients:  []*auth.Client{{Name: "browser", Anonymous: true, Methods: []string{"*"}}},
+		KeyRules: []*auth.KeyRule{{Keys: []string{"auth_*"}, Private: true}},
+	}
+	a, err := auth.NewAuthenticator(policy)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	p, err := a.Authenticate(context.Background(), "/hobord.session.DSessionService/WatchSession")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	ctx := auth.NewContext(context.Background(), p)
+
+	ev := &SessionEvent{
+		Type: SessionEventType_VALUES_CHANGED,
+		Keys: []string{"auth_token", "cart"},
+		Values: map[string]*st.Value{
+			"auth_token": {Kind: &st.Value_StringValue{StringValue: "secret"}},
+			"cart":       {Kind: &st.Value_NumberValue{NumberValue: 2}},
+		},
+	}
+	visible := visibleEvent(ctx, ev)
+	if visible == nil || len(visible.Keys) != 1 || visible.Values["auth_token"] != nil || visible.Values["cart"] == nil {
+		t.Errorf("Got %v", visible)
+	}
+	if len(ev.Keys) != 2 {
+		t.Errorf("The shared event is changed")
+	}
+	if visibleEvent(ctx, &SessionEvent{Type: SessionEventType_VALUES_DELETED, Keys: []string{"auth_token"}}) != nil {
+		t.Errorf("Event of hidden keys is sent")
+	}
+}
@@ -0,0 +1,372 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/gomodule/redigo/redis"
+	uuid "github.com/google/uuid"
+	"google.golang.org/grpc/status"
+)
+
+// maxImportErrors limits the failures reported by ImportSessions
+const maxImportErrors = 100
+
+// ExportSessions streams every session matching the filter with its values, metadata and remaining TTL.
+// The backup carries the raw session ids, not the signed tokens, so it is restored by ImportSessions
+// with any token keys, it must be kept as secret as the sessions themselves.
+func (s *GrpcRedisImplServer) ExportSessions(in *ExportSessionsMessage, stream DSessionAdminService_ExportSessionsServer) error {
+	if err := authorizeFilter(stream.Context(), in.Filter); err != nil {
+		return err
+	}
+	conn := s.getConn(stream.Context())
+	defer conn.Close()
+
+	filter := in.Filter
+	if filter == nil {
+		filter = &SessionFilter{}
+	}
+
+	return s.scanSessions(conn, func(batch []*storedSession) error {
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+		var matched []*storedSession
+		for _, stored := range batch {
+			if matchSessionFilter(filter, stored) {
+				matched = append(matched, stored)
+				conn.Send("TTL", stored.ID)
+			}
+		}
+		if err := conn.Flush(); err != nil {
+			return err
+		}
+		for _, stored := range matched {
+			remaining, err := redis.Int64(conn.Receive())
+			if err != nil {
+				return err
+			}
+			if remaining == -2 {
+				continue
+			}
+			err = stream.Send(&ExportedSession{
+				Id:           stored.ID,
+				Namespace:    stored.Namespace,
+				CreatedAt:    stored.CreatedAt,
+				Ttl:          stored.TTL,
+				RemainingTtl: remaining,
+				Values:       visibleValues(stream.Context(), stored.Values),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ImportSessions writes the streamed sessions with their original ids, the existing
+// sessions are handled by the conflict policy of the message
+func (s *GrpcRedisImplServer) ImportSessions(stream DSessionAdminService_ImportSessionsServer) error {
+	conn := s.getConn(stream.Context())
+	defer conn.Close()
+
+	res := &ImportSessionsResponse{}
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(res)
+		}
+		if err != nil {
+			return err
+		}
+		res.Received++
+
+		if in.Session == nil {
+			in.Session = &ExportedSession{}
+		}
+		outcome, err := s.importSession(stream.Context(), conn, in.Session, in.Policy)
+		s.recordImport(stream.Context(), in.Session, err)
+		if err != nil {
+			res.Failed++
+			if len(res.Errors) < maxImportErrors {
+				res.Errors = append(res.Errors, &ImportError{Id: in.Session.Id, Error: status.Convert(err).Message()})
+			}
+			continue
+		}
+		switch outcome {
+		case importCreated:
+			res.Created++
+		case importOverwritten:
+			res.Overwritten++
+		case importMerged:
+			res.Merged++
+		case importSkipped:
+			res.Skipped++
+		}
+	}
+}
+
+type importOutcome int
+
+const (
+	importCreated importOutcome = iota
+	importOverwritten
+	importMerged
+	importSkipped
+)
+
+// validateImport checks the imported session, an empty id gets a new one
+func validateImport(in *ExportedSession) error {
+	if in.Id == "" {
+		in.Id = uuid.New().String()
+	} else if !validSessionID(in.Id) {
+		return fmt.Errorf("Invalid session id %s", in.Id)
+	}
+	if in.Ttl < 0 {
+		return fmt.Errorf("Invalid ttl %d", in.Ttl)
+	}
+	for key := range in.Values {
+		if isMetaField(key) {
+			return fmt.Errorf("Key %s is reserved", key)
+		}
+	}
+	return nil
+}
+
+// importValueArgs returns the HSET arguments of the values sealed by the data key of the session
+func importValueArgs(in *ExportedSession, dataKey *dataKey) (redis.Args, error) {
+	keys := make([]string, 0, len(in.Values))
+	for key := range in.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	args := redis.Args{in.Id}
+	for _, key := range keys {
+		value, err := dataKey.seal(key, proto.MarshalTextString(in.Values[key]))
+		if err != nil {
+			return nil, err
+		}
+		args = args.Add(key, value)
+	}
+	return args, nil
+}
+
+// importAttempts limits the retries of an import whose session is changed meanwhile
+const importAttempts = 3
+
+// errImportConflict aborts the transaction of an import when the watched session changed
+var errImportConflict = errors.New("Session changed during the import")
+
+func (s *GrpcRedisImplServer) importSession(ctx context.Context, conn redis.Conn, in *ExportedSession, policy ConflictPolicy) (importOutcome, error) {
+	if err := validateImport(in); err != nil {
+		return 0, err
+	}
+	if err := authorizeNamespace(ctx, in.Namespace); err != nil {
+		return 0, err
+	}
+	keys := make([]string, 0, len(in.Values))
+	for key := range in.Values {
+		keys = append(keys, key)
+	}
+	if err := authorizeKeys(ctx, keys); err != nil {
+		return 0, err
+	}
+	for attempt := 1; ; attempt++ {
+		outcome, err := s.importWatched(ctx, conn, in, policy)
+		if err != errImportConflict || attempt == importAttempts {
+			return outcome, err
+		}
+	}
+}
+
+// importWatched applies the conflict policy while the session is watched, so a session
+// created or changed before the EXEC aborts the import with errImportConflict
+func (s *GrpcRedisImplServer) importWatched(ctx context.Context, conn redis.Conn, in *ExportedSession, policy ConflictPolicy) (importOutcome, error) {
+	if _, err := conn.Do("WATCH", in.Id); err != nil {
+		return 0, err
+	}
+	// the connection is shared by the imports of the stream
+	defer conn.Do("UNWATCH")
+
+	exists, err := redis.Bool(conn.Do("EXISTS", in.Id))
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		// the existing session may belong to another namespace
+		if err := authorizeSession(ctx, conn, in.Id); err != nil {
+			return 0, err
+		}
+	}
+
+	if exists && policy == ConflictPolicy_CONFLICT_SKIP {
+		return importSkipped, nil
+	}
+	if exists && policy == ConflictPolicy_CONFLICT_MERGE {
+		return importMerged, s.mergeImport(conn, in)
+	}
+
+	wrapped, err := s.values.newDataKey(in.Id)
+	if err != nil {
+		return 0, err
+	}
+	dataKey, err := s.values.dataKey(in.Id, wrapped)
+	if err != nil {
+		return 0, err
+	}
+	args, err := importValueArgs(in, dataKey)
+	if err != nil {
+		return 0, err
+	}
+	createdAt := in.CreatedAt
+	if createdAt == 0 {
+		createdAt = time.Now().Unix()
+	}
+	remaining := in.RemainingTtl
+	if remaining == 0 {
+		remaining = in.Ttl
+	}
+	meta := redis.Args{in.Id,
+		ttlField, strconv.FormatInt(in.Ttl, 10),
+		createdField, createdAt,
+		namespaceField, in.Namespace}
+	if wrapped != "" {
+		meta = meta.Add(keyField, wrapped)
+	}
+
+	tx := newTransaction(conn)
+	tx.send("DEL", in.Id, expiryKeyPrefix+in.Id)
+	tx.send("HSET", meta...)
+	if len(in.Values) > 0 {
+		tx.send("HSET", args...)
+	}
+	if remaining > 0 {
+		tx.send("EXPIRE", in.Id, remaining)
+		tx.check(sendExpiryShadow(conn, in.Id, in.Namespace, remaining))
+	}
+	ev := &SessionEvent{
+		Type:      SessionEventType_SESSION_CREATED,
+		Id:        in.Id,
+		Ttl:       in.Ttl,
+		Namespace: in.Namespace,
+	}
+	tx.check(s.sendEvent(conn, ev))
+	if err := tx.exec(); err != nil {
+		return 0, err
+	}
+	countEvent(ev)
+
+	if exists {
+		return importOverwritten, nil
+	}
+	return importCreated, nil
+}
+
+// mergeImport adds the values to the existing session
+func (s *GrpcRedisImplServer) mergeImport(conn redis.Conn, in *ExportedSession) error {
+	if len(in.Values) == 0 {
+		return nil
+	}
+	dataKey, err := s.sessionDataKey(conn, in.Id, "")
+	if err != nil {
+		return err
+	}
+	args, err := importValueArgs(in, dataKey)
+	if err != nil {
+		return err
+	}
+	namespace, err := sessionNamespace(conn, in.Id)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(in.Values))
+	for key := range in.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tx := newTransaction(conn)
+	tx.send("HSET", args...)
+	ev := &SessionEvent{
+		Type:      SessionEventType_VALUES_CHANGED,
+		Id:        in.Id,
+		Keys:      keys,
+		Values:    in.Values,
+		Namespace: namespace,
+	}
+	tx.check(s.sendEvent(conn, ev))
+	if err := tx.exec(); err != nil {
+		return err
+	}
+	countEvent(ev)
+	return nil
+}
+
+// transaction queues the commands of a MULTI/EXEC block and keeps the first error
+type transaction struct {
+	conn redis.Conn
+	err  error
+}
+
+func newTransaction(conn redis.Conn) *transaction {
+	tx := &transaction{conn: conn}
+	tx.send("MULTI")
+	return tx
+}
+
+func (tx *transaction) send(cmd string, args ...interface{}) {
+	tx.check(tx.conn.Send(cmd, args...))
+}
+
+// check keeps the error of queueing a command
+func (tx *transaction) check(err error) {
+	if tx.err == nil {
+		tx.err = err
+	}
+}
+
+// exec runs the block, it returns the first error of the queueing or of the replies of the commands
+func (tx *transaction) exec() error {
+	if tx.err != nil {
+		tx.conn.Do("DISCARD")
+		return tx.err
+	}
+	reply, err := tx.conn.Do("EXEC")
+	if err != nil {
+		return err
+	}
+	if reply == nil {
+		// a watched key changed
+		return errImportConflict
+	}
+	replies, err := redis.Values(reply, nil)
+	if err != nil {
+		return err
+	}
+	for _, reply := range replies {
+		if err, ok := reply.(redis.Error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordImport writes the audit record of an imported session
+func (s *GrpcRedisImplServer) recordImport(ctx context.Context, in *ExportedSession, err error) {
+	if s.audit == nil {
+		return
+	}
+	keys := make([]string, 0, len(in.Values))
+	for key := range in.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	s.record(ctx, importSessionsMethod, in.Id, keys, in.Values, err)
+}
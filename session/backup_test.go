@@ -0,0 +1,116 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+	st "github.com/golang/protobuf/ptypes/struct"
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestValidateImport(t *testing.T) {
+	tests := []struct {
+		in    *ExportedSession
+		valid bool
+	}{
+		{&ExportedSession{Id: "8f60aaef-a0bd-4c55-ab49-00c4ed5a4091", Ttl: 10}, true},
+		{&ExportedSession{}, true},
+		{&ExportedSession{Id: "foo"}, false},
+		{&ExportedSession{Id: "8F60AAEF-A0BD-4C55-AB49-00C4ED5A4091"}, false},
+		{&ExportedSession{Id: "urn:uuid:8f60aaef-a0bd-4c55-ab49-00c4ed5a4091"}, false},
+		{&ExportedSession{Ttl: -1}, false},
+		{&ExportedSession{Values: map[string]*st.Value{ttlField: {}}}, false},
+	}
+	for i, tt := range tests {
+		err := validateImport(tt.in)
+		if (err == nil) != tt.valid {
+			t.Errorf("%d: Got error %v", i, err)
+		}
+		if err == nil && tt.in.Id == "" {
+			t.Errorf("%d: No id assigned", i)
+		}
+	}
+}
+
+func TestImportValueArgs(t *testing.T) {
+	in := &ExportedSession{Id: "id", Values: map[string]*st.Value{
+		"b": {Kind: &st.Value_NumberValue{NumberValue: 15}},
+		"a": {Kind: &st.Value_BoolValue{BoolValue: true}},
+	}}
+	args, err := importValueArgs(in, nil)
+	if err != nil || len(args) != 5 || args[0] != "id" || args[1] != "a" || args[3] != "b" {
+		t.Fatalf("Got args %v, %v", args, err)
+	}
+	var v st.Value
+	if err := proto.UnmarshalText(args[4].(string), &v); err != nil || v.GetNumberValue() != 15 {
+		t.Errorf("Got value %v, %v", args[4], err)
+	}
+}
+
+func TestTransactionReturnsCommandError(t *testing.T) {
+	mr, pool := newTestRedis(t)
+	mr.Set("a", "string")
+	conn := pool.Get()
+	defer conn.Close()
+
+	tx := newTransaction(conn)
+	tx.send("SET", "b", "1")
+	tx.send("HSET", "a", "key", "value")
+	err := tx.exec()
+	if err == nil || !strings.Contains(err.Error(), "WRONGTYPE") {
+		t.Errorf("Got error %v, want the error of the HSET", err)
+	}
+	if got, _ := mr.Get("b"); got != "1" {
+		t.Errorf("Got b %q, the other commands are run", got)
+	}
+
+	tx = newTransaction(conn)
+	tx.send("SET", "c", "1")
+	if err := tx.exec(); err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+}
+
+// raceConn runs the race once, after the EXISTS check of the import
+type raceConn struct {
+	redis.Conn
+	race func()
+}
+
+func (c *raceConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	reply, err := c.Conn.Do(cmd, args...)
+	if cmd == "EXISTS" && c.race != nil {
+		c.race()
+		c.race = nil
+	}
+	return reply, err
+}
+
+func TestImportSessionSkipsConcurrentCreate(t *testing.T) {
+	mr, pool := newTestRedis(t)
+	s := &GrpcRedisImplServer{RedisPool: pool}
+	conn := pool.Get()
+	defer conn.Close()
+	// the session is created by another client between the check and the write
+	racing := &raceConn{Conn: conn, race: func() { mr.HSet(testID, namespaceField, "shop", "user", "live") }}
+
+	in := &ExportedSession{Id: testID, Namespace: "shop", Values: map[string]*st.Value{
+		"user": {Kind: &st.Value_StringValue{StringValue: "imported"}},
+	}}
+	outcome, err := s.importSession(context.Background(), racing, in, ConflictPolicy_CONFLICT_SKIP)
+	if err != nil || outcome != importSkipped {
+		t.Errorf("Got %v, %v, want skipped", outcome, err)
+	}
+	if got := mr.HGet(testID, "user"); got != "live" {
+		t.Errorf("Got user %q, the live session is overwritten", got)
+	}
+
+	// the watch does not abort the next import of the stream
+	other := &ExportedSession{Id: "1c2d3e4f-a0bd-4c55-ab49-00c4ed5a4091", Namespace: "shop"}
+	mr.HSet(testID, "user", "changed")
+	if outcome, err := s.importSession(context.Background(), conn, other, ConflictPolicy_CONFLICT_SKIP); err != nil || outcome != importCreated {
+		t.Errorf("Got %v, %v, want created", outcome, err)
+	}
+}
@@ -0,0 +1,245 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// The prefixes of the encrypted fields in the session hash
+const (
+	// encryptedPrefix marks a value sealed by a master key, enc:<kid>:<base64 nonce+ciphertext>,
+	// the wrapped data keys and the values written before the data keys look like this
+	encryptedPrefix = "enc:"
+	// envelopePrefix marks a value sealed by the data key of its session, env:<base64 nonce+ciphertext>
+	envelopePrefix = "env:"
+)
+
+// dataKeySize is the length of the AES-256 data keys of the sessions
+const dataKeySize = 32
+
+// valueCipher encrypts the stored session values with envelope encryption.
+// Every session has its own AES-GCM data key which encrypts its values, the data key
+// is stored in the session hash wrapped by a master key (key encryption key) of the
+// configuration, so rotating the master key only rewraps the data keys.
+// The session id and the key of the field are authenticated with a value, so it cannot
+// be moved to another field or session. Without master keys the values are stored in plain text.
+type valueCipher struct {
+	keys map[string]cipher.AEAD
+	// current is the kid of the master key of the new data keys, the other keys only unwrap
+	current string
+}
+
+// loadValueCipher parses the keys of the env value and of the file,
+// the first key encrypts the new values
+func loadValueCipher(spec, path string) (*valueCipher, error) {
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		spec = strings.Join([]string{spec, strings.Replace(string(data), "\n", ",", -1)}, ",")
+	}
+	return newValueCipher(spec)
+}
+
+// newValueCipher parses the kid=base64key list, the keys are 16, 24 or 32 bytes long
+func newValueCipher(spec string) (*valueCipher, error) {
+	c := &valueCipher{keys: make(map[string]cipher.AEAD)}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || strings.HasPrefix(item, "#") {
+			continue
+		}
+		i := strings.Index(item, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("Invalid encryption key %q, want kid=base64key", item)
+		}
+		kid := item[:i]
+		if strings.ContainsAny(kid, ": ") {
+			return nil, fmt.Errorf("Invalid encryption key id %q", kid)
+		}
+		key, err := base64.StdEncoding.DecodeString(item[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid encryption key %s: %s", kid, err)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid encryption key %s: %s", kid, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		if c.current == "" {
+			c.current = kid
+		}
+		c.keys[kid] = aead
+	}
+	return c, nil
+}
+
+// enabled reports whether the values are encrypted
+func (c *valueCipher) enabled() bool {
+	return c != nil && c.current != ""
+}
+
+func additionalData(id, key string) []byte {
+	return []byte(id + "\x00" + key)
+}
+
+// sealNonce encrypts the plain text with a random nonce prepended to the result
+func sealNonce(aead cipher.AEAD, plain, ad []byte) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, ad)), nil
+}
+
+// openNonce decrypts the result of sealNonce
+func openNonce(aead cipher.AEAD, text string, ad []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(text)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, errors.New("Invalid encrypted value")
+	}
+	nonce := sealed[:aead.NonceSize()]
+	return aead.Open(nil, nonce, sealed[aead.NonceSize():], ad)
+}
+
+// sealMaster encrypts the data with the current master key, without keys it is returned as it is.
+// It seals the data keys, and the events and audit records which live outside the session.
+func (c *valueCipher) sealMaster(plain, ad []byte) (string, error) {
+	if !c.enabled() {
+		return string(plain), nil
+	}
+	sealed, err := sealNonce(c.keys[c.current], plain, ad)
+	if err != nil {
+		return "", err
+	}
+	return encryptedPrefix + c.current + ":" + sealed, nil
+}
+
+// openMaster decrypts the result of sealMaster, the data without the prefix is returned as it is
+func (c *valueCipher) openMaster(stored string, ad []byte) ([]byte, error) {
+	if !strings.HasPrefix(stored, encryptedPrefix) {
+		return []byte(stored), nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(stored, encryptedPrefix), ":", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("Invalid encrypted value")
+	}
+	var aead cipher.AEAD
+	if c != nil {
+		aead = c.keys[parts[0]]
+	}
+	if aead == nil {
+		return nil, fmt.Errorf("Unknown encryption key %s", parts[0])
+	}
+	return openNonce(aead, parts[1], ad)
+}
+
+func keyAdditionalData(id string) []byte {
+	return []byte("key\x00" + id)
+}
+
+// newDataKey returns a new data key of the session wrapped by the current master key,
+// empty without keys
+func (c *valueCipher) newDataKey(id string) (string, error) {
+	if !c.enabled() {
+		return "", nil
+	}
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return c.sealMaster(key, keyAdditionalData(id))
+}
+
+// dataKey unwraps the data key of the session, nil when the session has none
+func (c *valueCipher) dataKey(id, wrapped string) (*dataKey, error) {
+	if wrapped == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(wrapped, encryptedPrefix) {
+		return nil, errors.New("Invalid data key")
+	}
+	key, err := c.openMaster(wrapped, keyAdditionalData(id))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unwrap the data key: %s", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &dataKey{id: id, aead: aead}, nil
+}
+
+// staleKey reports whether the data key is not wrapped by the current master key
+func (c *valueCipher) staleKey(wrapped string) bool {
+	return c.enabled() && !strings.HasPrefix(wrapped, encryptedPrefix+c.current+":")
+}
+
+// rewrap wraps the data key by the current master key
+func (c *valueCipher) rewrap(id, wrapped string) (string, error) {
+	key, err := c.openMaster(wrapped, keyAdditionalData(id))
+	if err != nil {
+		return "", fmt.Errorf("Failed to unwrap the data key: %s", err)
+	}
+	return c.sealMaster(key, keyAdditionalData(id))
+}
+
+// dataKey encrypts the values of one session, nil stores them in plain text
+type dataKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// seal encrypts the value of the field with the data key of the session
+func (k *dataKey) seal(key, value string) (string, error) {
+	if k == nil {
+		return value, nil
+	}
+	sealed, err := sealNonce(k.aead, []byte(value), additionalData(k.id, key))
+	if err != nil {
+		return "", err
+	}
+	return envelopePrefix + sealed, nil
+}
+
+// open decrypts the stored value of the field. The values sealed by a master key before
+// the data keys are still read, plain text values are returned as they are.
+func (c *valueCipher) open(k *dataKey, id, key, stored string) (string, error) {
+	switch {
+	case strings.HasPrefix(stored, envelopePrefix):
+		if k == nil {
+			return "", fmt.Errorf("Missing data key of %s", key)
+		}
+		plain, err := openNonce(k.aead, strings.TrimPrefix(stored, envelopePrefix), additionalData(id, key))
+		if err != nil {
+			return "", fmt.Errorf("Failed to decrypt %s: %s", key, err)
+		}
+		return string(plain), nil
+	case strings.HasPrefix(stored, encryptedPrefix):
+		plain, err := c.openMaster(stored, additionalData(id, key))
+		if err != nil {
+			return "", fmt.Errorf("Failed to decrypt %s: %s", key, err)
+		}
+		return string(plain), nil
+	}
+	return stored, nil
+}
+
+// stale reports whether the stored value is not encrypted with the data key of its session
+func (c *valueCipher) stale(stored string) bool {
+	return c.enabled() && !strings.HasPrefix(stored, envelopePrefix)
+}
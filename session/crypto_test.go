@@ -0,0 +1,194 @@
+package session
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	st "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValueCipher(t *testing.T) {
+	old, _ := newValueCipher("k1=" + testKey('a'))
+	c, err := newValueCipher("k2=" + testKey('b') + ",k1=" + testKey('a'))
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	wrapped, err := c.newDataKey(testID)
+	if err != nil || !strings.HasPrefix(wrapped, "enc:k2:") {
+		t.Fatalf("Got data key %q, %v", wrapped, err)
+	}
+	key, err := c.dataKey(testID, wrapped)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	sealed, err := key.seal("name", `string_value: "foo"`)
+	if err != nil || !strings.HasPrefix(sealed, "env:") || strings.Contains(sealed, "foo") {
+		t.Fatalf("Got sealed %q, %v", sealed, err)
+	}
+	// the values sealed by a master key before the data keys
+	legacy, _ := old.sealMaster([]byte(`string_value: "foo"`), additionalData(testID, "name"))
+	otherWrapped, _ := c.newDataKey(testID)
+	otherKey, _ := c.dataKey(testID, otherWrapped)
+
+	tests := []struct {
+		key     *dataKey
+		id, fld string
+		stored  string
+		want    string
+	}{
+		{key, testID, "name", sealed, `string_value: "foo"`},
+		{nil, testID, "name", legacy, `string_value: "foo"`},
+		{nil, testID, "name", `number_value: 15`, `number_value: 15`},
+		{key, testID, "other", sealed, ""},
+		{key, "other", "name", sealed, ""},
+		{otherKey, testID, "name", sealed, ""},
+		{nil, testID, "name", sealed, ""},
+		{nil, testID, "name", "enc:k3:" + strings.TrimPrefix(legacy, "enc:k1:"), ""},
+		{nil, testID, "name", "enc:k2", ""},
+		{key, testID, "name", "env:!!", ""},
+	}
+	for i, tt := range tests {
+		got, err := c.open(tt.key, tt.id, tt.fld, tt.stored)
+		if got != tt.want || (tt.want == "" && err == nil) {
+			t.Errorf("%d: Got %q, %v", i, got, err)
+		}
+	}
+
+	if c.stale(sealed) || !c.stale(legacy) || !c.stale("number_value: 15") {
+		t.Errorf("Stale values are not detected")
+	}
+	if _, err := c.dataKey("other", wrapped); err == nil {
+		t.Errorf("Data key of another session is unwrapped")
+	}
+
+	oldWrapped, _ := old.newDataKey(testID)
+	if !c.staleKey(oldWrapped) || c.staleKey(wrapped) {
+		t.Errorf("Stale data keys are not detected")
+	}
+	rewrapped, err := c.rewrap(testID, oldWrapped)
+	if err != nil || !strings.HasPrefix(rewrapped, "enc:k2:") {
+		t.Fatalf("Got rewrapped %q, %v", rewrapped, err)
+	}
+	oldKey, _ := old.dataKey(testID, oldWrapped)
+	newKey, _ := c.dataKey(testID, rewrapped)
+	oldSealed, _ := oldKey.seal("name", "number_value: 15")
+	if got, err := c.open(newKey, testID, "name", oldSealed); err != nil || got != "number_value: 15" {
+		t.Errorf("Rewrapped data key got %q, %v", got, err)
+	}
+
+	var none *valueCipher
+	if wrapped, _ := none.newDataKey(testID); wrapped != "" || none.stale("number_value: 15") {
+		t.Errorf("Got data key %q without keys", wrapped)
+	}
+	var plain *dataKey
+	if got, _ := plain.seal("name", "number_value: 15"); got != "number_value: 15" {
+		t.Errorf("Got %q without data key", got)
+	}
+}
+
+func TestLoadValueCipher(t *testing.T) {
+	f, err := ioutil.TempFile("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("# rotated keys\nk2=" + testKey('b') + "\nk1=" + testKey('a') + "\n")
+	f.Close()
+
+	c, err := loadValueCipher("", f.Name())
+	if err != nil || c.current != "k2" || len(c.keys) != 2 {
+		t.Errorf("Got %v, %v", c, err)
+	}
+
+	for _, spec := range []string{"k1", "k:1=" + testKey('a'), "k1=!!", "k1=" + base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := newValueCipher(spec); err == nil {
+			t.Errorf("%q: Got no error", spec)
+		}
+	}
+}
+
+func TestParseStoredSessionEncrypted(t *testing.T) {
+	c, _ := newValueCipher("k1=" + testKey('a'))
+	wrapped, _ := c.newDataKey(testID)
+	key, _ := c.dataKey(testID, wrapped)
+	sealed, _ := key.seal("visits", "number_value: 3")
+	legacy, _ := c.sealMaster([]byte("string_value: \"bar\""), additionalData(testID, "legacy"))
+	fields := map[string]string{ttlField: "10", namespaceField: "shop", keyField: wrapped,
+		"visits": sealed, "legacy": legacy, "plain": "bool_value: true"}
+
+	stored, err := parseStoredSession(testID, fields, c)
+	if err != nil || stored.Values["visits"].GetNumberValue() != 3 || stored.Values["legacy"].GetStringValue() != "bar" ||
+		!stored.Values["plain"].GetBoolValue() || len(stored.Values) != 3 {
+		t.Errorf("Got %v, %v", stored, err)
+	}
+	if _, err := parseStoredSession(testID, fields, nil); err == nil {
+		t.Errorf("Encrypted value is parsed without keys")
+	}
+
+	s := &GrpcRedisImplServer{values: c}
+	args, err := s.reencryptArgs(testID, fields)
+	if err != nil || len(args) != 6 || args[0] != "legacy" || args[3] != "plain" || !strings.HasPrefix(args[5], "env:") {
+		t.Errorf("Got args %v, %v", args, err)
+	}
+
+	// a session without a data key gets one, with a new master key it is rewrapped
+	rotated, _ := newValueCipher("k2=" + testKey('b') + ",k1=" + testKey('a'))
+	s = &GrpcRedisImplServer{values: rotated}
+	args, err = s.reencryptArgs(testID, map[string]string{ttlField: "10", "plain": "bool_value: true"})
+	if err != nil || len(args) != 6 || args[0] != keyField || args[1] != "" || !strings.HasPrefix(args[2], "enc:k2:") {
+		t.Errorf("Got args %v, %v", args, err)
+	}
+	args, err = s.reencryptArgs(testID, map[string]string{ttlField: "10", keyField: wrapped, "visits": sealed})
+	if err != nil || len(args) != 3 || args[0] != keyField || !strings.HasPrefix(args[2], "enc:k2:") {
+		t.Errorf("Got args %v, %v", args, err)
+	}
+}
+
+func TestEventsAreSealed(t *testing.T) {
+	c, _ := newValueCipher("k1=" + testKey('a'))
+	ev := &SessionEvent{Type: SessionEventType_VALUES_CHANGED, Id: testID, Keys: []string{"name"},
+		Values: map[string]*st.Value{"name": {Kind: &st.Value_StringValue{StringValue: "secret"}}}}
+
+	data, err := encodeEvent(c, ev)
+	if err != nil || strings.Contains(string(data), "secret") {
+		t.Fatalf("Got %q, %v", data, err)
+	}
+	got, err := parseEvent(c, data)
+	if err != nil || got.Values["name"].GetStringValue() != "secret" {
+		t.Errorf("Got %v, %v", got, err)
+	}
+	if _, err := parseEvent(nil, data); err == nil {
+		t.Errorf("Sealed event is parsed without keys")
+	}
+}
+
+func TestSessionDataKey(t *testing.T) {
+	_, pool := newTestRedis(t)
+	c, _ := newValueCipher("k1=" + testKey('a'))
+	s := &GrpcRedisImplServer{values: c, tokens: &tokenCodec{}}
+	conn := pool.Get()
+	defer conn.Close()
+
+	if _, err := s.sessionDataKey(conn, testID, ""); status.Code(err) != codes.NotFound {
+		t.Errorf("Got %v of a missing session", err)
+	}
+	conn.Do("HSET", testID, ttlField, "0")
+	first, err := s.sessionDataKey(conn, testID, "")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	second, err := s.sessionDataKey(conn, testID, "")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	sealed, _ := first.seal("name", "number_value: 1")
+	if got, err := c.open(second, testID, "name", sealed); err != nil || got != "number_value: 1" {
+		t.Errorf("The writers got different data keys: %q, %v", got, err)
+	}
+}
@@ -0,0 +1,490 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/logging"
+)
+
+const (
+	// eventStream is the redis stream of the session lifecycle events
+	eventStream = "dsession:events"
+	// expiryKeyPrefix + session id shadows a session with ttl and outlives it, it keeps the namespace
+	// of the expired session and elects the replica which records the expiry
+	expiryKeyPrefix = "dsession:expiry:"
+	// expiryMargin is how long the shadow key outlives the session, in seconds,
+	// the expiries missed by every replica are recovered by the sweep within this time
+	expiryMargin = 3600
+	// expiryQueue is the number of expiry notifications waiting for the expiry worker,
+	// the ones which do not fit are recorded by the sweep
+	expiryQueue = 1024
+	// expirySweepInterval is how often the orphaned shadow keys are looked for
+	expirySweepInterval = time.Minute
+	// eventBlock is how long one read of the event stream waits for new entries
+	eventBlock = 5 * time.Second
+	// eventReadCount is the maximum number of entries of one read of the event stream
+	eventReadCount = 100
+)
+
+// claimExpiryScript deletes the shadow key and returns its value, so only the first caller gets it
+var claimExpiryScript = redis.NewScript(1, `
+local ns = redis.call('GET', KEYS[1])
+if ns then
+	redis.call('DEL', KEYS[1])
+end
+return ns
+`)
+
+// sweepExpiryScript claims the shadow key of a session which no longer exists and whose ttl elapsed,
+// it returns the namespace and the remaining ttl of the shadow key in milliseconds.
+// The shadow keys of the deleted sessions, which outlive the margin, are left to expire.
+var sweepExpiryScript = redis.NewScript(2, `
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	return false
+end
+local pttl = redis.call('PTTL', KEYS[1])
+if pttl < 0 or pttl > tonumber(ARGV[1]) then
+	return false
+end
+local ns = redis.call('GET', KEYS[1])
+redis.call('DEL', KEYS[1])
+return {ns, pttl}
+`)
+
+func nowMillis() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+// sendEvent queues the publishing of the event to the watchers and to the event stream,
+// the caller counts it after the flush
+func (s *GrpcRedisImplServer) sendEvent(conn redis.Conn, ev *SessionEvent) error {
+	ev.Time = nowMillis()
+	data, err := encodeEvent(s.values, ev)
+	if err != nil {
+		return err
+	}
+	if err := conn.Send("PUBLISH", watchChannelPrefix+ev.Id, data); err != nil {
+		return err
+	}
+	return s.sendStreamEvent(conn, data)
+}
+
+func (s *GrpcRedisImplServer) sendStreamEvent(conn redis.Conn, data []byte) error {
+	args := redis.Args{}.Add(eventStream)
+	if s.eventsMaxLen > 0 {
+		args = args.Add("MAXLEN", "~", s.eventsMaxLen)
+	}
+	return conn.Send("XADD", args.Add("*", "event", data)...)
+}
+
+// signedEvent returns a copy of the event with the signed token of the session id,
+// the raw ids stay inside the service
+func (s *GrpcRedisImplServer) signedEvent(ev *SessionEvent) *SessionEvent {
+	signed := *ev
+	signed.Id = s.tokens.sign(ev.Id)
+	return &signed
+}
+
+// sendExpiryShadow queues the update of the shadow key of the session
+func sendExpiryShadow(conn redis.Conn, id, namespace string, ttl int64) error {
+	if ttl <= 0 {
+		return conn.Send("DEL", expiryKeyPrefix+id)
+	}
+	return conn.Send("SET", expiryKeyPrefix+id, namespace, "EX", ttl+expiryMargin)
+}
+
+// sessionNamespace returns the namespace of the session, empty if it does not exist
+func sessionNamespace(conn redis.Conn, id string) (string, error) {
+	namespace, err := redis.String(conn.Do("HGET", id, namespaceField))
+	if err == redis.ErrNil {
+		return "", nil
+	}
+	return namespace, err
+}
+
+// queueExpiry hands the expiry notification to the expiry worker, so the pub/sub
+// receive loop does not wait for redis, the ones which do not fit are left to the sweep
+func (s *GrpcRedisImplServer) queueExpiry(ev *SessionEvent) {
+	select {
+	case s.expiries <- &SessionEvent{Type: ev.Type, Id: ev.Id, Time: ev.Time}:
+	default:
+	}
+}
+
+// recordExpiries runs the expiry worker and the sweep until the drain
+func (s *GrpcRedisImplServer) recordExpiries() {
+	sweep := time.NewTicker(expirySweepInterval)
+	defer sweep.Stop()
+	for {
+		select {
+		case ev := <-s.expiries:
+			s.recordExpiry(ev)
+		case <-sweep.C:
+			if err := s.sweepExpiries(); err != nil {
+				logging.Error(context.Background(), "Failed to sweep the expiries", "error", err)
+			}
+		case <-s.draining:
+			return
+		}
+	}
+}
+
+// recordExpiry completes the expiry event with the namespace of the session,
+// and the replica which claims the shadow key writes it into the event stream
+func (s *GrpcRedisImplServer) recordExpiry(ev *SessionEvent) {
+	conn := s.RedisPool.Get()
+	defer conn.Close()
+
+	namespace, err := redis.String(claimExpiryScript.Do(conn, expiryKeyPrefix+ev.Id))
+	if err == redis.ErrNil {
+		return
+	}
+	if err != nil {
+		logging.Error(context.Background(), "Failed to claim the expiry", "session", logging.SessionID(ev.Id), "error", err)
+		return
+	}
+	ev.Namespace = namespace
+	if err := s.writeExpiry(conn, ev, false); err != nil {
+		logging.Error(context.Background(), "Failed to record the expiry", "session", logging.SessionID(ev.Id), "error", err)
+	}
+}
+
+// writeExpiry writes the claimed expiry into the event stream, the swept ones are
+// published to the watchers too, as their notification was missed
+func (s *GrpcRedisImplServer) writeExpiry(conn redis.Conn, ev *SessionEvent, publish bool) error {
+	data, err := encodeEvent(s.values, ev)
+	if err != nil {
+		return err
+	}
+	if publish {
+		if err := conn.Send("PUBLISH", watchChannelPrefix+ev.Id, data); err != nil {
+			return err
+		}
+	}
+	if err := s.sendStreamEvent(conn, data); err != nil {
+		return err
+	}
+	if err := runPipeline(conn); err != nil {
+		return err
+	}
+	countEvent(ev)
+	return nil
+}
+
+// sweepExpiries records the expiries of the shadow keys whose session is gone,
+// which were missed while no replica received the expiry notifications
+func (s *GrpcRedisImplServer) sweepExpiries() error {
+	conn := s.RedisPool.Get()
+	defer conn.Close()
+
+	cursor := "0"
+	for {
+		reply, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", expiryKeyPrefix+"*", "COUNT", 1000))
+		if err != nil {
+			return err
+		}
+		if len(reply) != 2 {
+			return errors.New("Unexpected SCAN reply")
+		}
+		if cursor, err = redis.String(reply[0], nil); err != nil {
+			return err
+		}
+		keys, err := redis.Strings(reply[1], nil)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			id := strings.TrimPrefix(key, expiryKeyPrefix)
+			claimed, err := redis.Values(sweepExpiryScript.Do(conn, key, id, expiryMargin*1000))
+			if err == redis.ErrNil {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			var namespace string
+			var remaining int64
+			if _, err := redis.Scan(claimed, &namespace, &remaining); err != nil {
+				return err
+			}
+			// the session expired when the shadow key had the margin left
+			ev := &SessionEvent{
+				Type:      SessionEventType_SESSION_EXPIRED,
+				Id:        id,
+				Namespace: namespace,
+				Time:      nowMillis() - (expiryMargin*1000 - remaining),
+			}
+			if err := s.writeExpiry(conn, ev, true); err != nil {
+				return err
+			}
+		}
+		if cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// StartEvents starts listening to the session events and the expiry notifications of redis,
+// and the recording of the expiries, without it only the watched sessions are followed
+func (s *GrpcRedisImplServer) StartEvents() {
+	s.watchers.start()
+	go s.recordExpiries()
+}
+
+// SubscribeEvents streams the session lifecycle events of every session.
+// Inside a consumer group the entries are delivered until they are acknowledged with AckEvents,
+// so a consumer resumes with its pending entries after a reconnect.
+func (s *GrpcRedisImplServer) SubscribeEvents(in *SubscribeEventsMessage, stream DSessionAdminService_SubscribeEventsServer) error {
+	ctx := stream.Context()
+	if err := authorizeAllNamespaces(ctx); err != nil {
+		return err
+	}
+	conn := s.getConn(ctx)
+	defer conn.Close()
+
+	grouped := in.Group != ""
+	if grouped && in.Consumer == "" {
+		return status.Error(codes.InvalidArgument, "Consumer is required with a group")
+	}
+
+	types := make(map[SessionEventType]bool)
+	for _, t := range in.Types {
+		types[t] = true
+	}
+
+	var lastID string
+	if grouped {
+		start := in.StartId
+		if start == "" {
+			start = "$"
+		}
+		if err := createEventGroup(conn, in.Group, start); err != nil {
+			return err
+		}
+		// the pending entries of the consumer come first
+		lastID = "0"
+	} else {
+		lastID = in.StartId
+		if lastID == "" || lastID == "$" {
+			var err error
+			if lastID, err = lastStreamID(conn, eventStream); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the blocking read ends within eventBlock, so the stream notices the drain in time
+	for ctx.Err() == nil {
+		if s.isDraining() {
+			return drainedError
+		}
+		entries, err := readEvents(conn, in.Group, in.Consumer, lastID)
+		if err != nil {
+			return err
+		}
+		if grouped && lastID != ">" && len(entries) == 0 {
+			lastID = ">"
+			continue
+		}
+
+		for _, entry := range entries {
+			if !grouped || lastID != ">" {
+				lastID = entry.ID
+			}
+
+			ev := s.decodeEvent(entry)
+			if ev != nil && (len(types) == 0 || types[ev.Type]) {
+				// the keys and values which the caller may not read are dropped
+				ev = visibleEvent(ctx, ev)
+			} else {
+				ev = nil
+			}
+			if ev == nil {
+				// the skipped entries of a group are acknowledged, they are not redelivered
+				if grouped {
+					if _, err := conn.Do("XACK", eventStream, in.Group, entry.ID); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			if err := stream.Send(&EventRecord{StreamId: entry.ID, Event: s.signedEvent(ev)}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return ctx.Err()
+}
+
+// readEvents reads the event stream after lastID, blocking for a while when there are no entries.
+// With a group lastID ">" reads the new entries, any other id reads the pending entries of the consumer.
+func readEvents(conn redis.Conn, group, consumer, lastID string) ([]streamEntry, error) {
+	var reply interface{}
+	var err error
+	if group != "" {
+		reply, err = redis.DoWithTimeout(conn, eventBlock+time.Second, "XREADGROUP", "GROUP", group, consumer,
+			"COUNT", eventReadCount, "BLOCK", int64(eventBlock/time.Millisecond), "STREAMS", eventStream, lastID)
+	} else {
+		reply, err = redis.DoWithTimeout(conn, eventBlock+time.Second, "XREAD",
+			"COUNT", eventReadCount, "BLOCK", int64(eventBlock/time.Millisecond), "STREAMS", eventStream, lastID)
+	}
+	return parseStreamEntries(reply, err)
+}
+
+// createEventGroup creates the consumer group of the event stream if it does not exist yet
+func createEventGroup(conn redis.Conn, group, start string) error {
+	_, err := conn.Do("XGROUP", "CREATE", eventStream, group, start, "MKSTREAM")
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// eventAdditionalData is authenticated with the sealed events
+var eventAdditionalData = []byte("event")
+
+// encodeEvent returns the message of the event on the pub/sub channels and in the event stream.
+// It carries the values of the session, so it is sealed by the master key of the values.
+func encodeEvent(values *valueCipher, ev *SessionEvent) ([]byte, error) {
+	data, err := proto.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := values.sealMaster(data, eventAdditionalData)
+	return []byte(sealed), err
+}
+
+// parseEvent opens and decodes the message of encodeEvent
+func parseEvent(values *valueCipher, data []byte) (*SessionEvent, error) {
+	plain, err := values.openMaster(string(data), eventAdditionalData)
+	if err != nil {
+		return nil, err
+	}
+	ev := &SessionEvent{}
+	if err := proto.Unmarshal(plain, ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+// decodeEvent returns the event of the stream entry, nil if the entry is deleted or invalid
+func (s *GrpcRedisImplServer) decodeEvent(entry streamEntry) *SessionEvent {
+	data, ok := entry.Fields["event"]
+	if !ok {
+		return nil
+	}
+	ev, err := parseEvent(s.values, []byte(data))
+	if err != nil {
+		logging.Warn(context.Background(), "Skipping invalid event entry", "event", entry.ID, "error", err)
+		return nil
+	}
+	return ev
+}
+
+// AckEvents acknowledges the processed entries of a consumer group
+func (s *GrpcRedisImplServer) AckEvents(ctx context.Context, in *AckEventsMessage) (*SuccessMessage, error) {
+	if err := authorizeAllNamespaces(ctx); err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	if in.Group == "" {
+		return &SuccessMessage{Successfull: false}, status.Error(codes.InvalidArgument, "Group is required")
+	}
+	if len(in.StreamIds) == 0 {
+		return &SuccessMessage{Successfull: true}, nil
+	}
+	conn := s.getConn(ctx)
+	defer conn.Close()
+
+	_, err := conn.Do("XACK", redis.Args{}.Add(eventStream, in.Group).AddFlat(in.StreamIds)...)
+	if err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+
+	return &SuccessMessage{Successfull: true}, nil
+}
+
+// streamEntry is one entry of a redis stream
+type streamEntry struct {
+	ID     string
+	Fields map[string]string
+}
+
+// parseStreamEntries decodes the reply of XREAD and XREADGROUP, a timeout gives no entries
+func parseStreamEntries(reply interface{}, err error) ([]streamEntry, error) {
+	streams, err := redis.Values(reply, err)
+	if err == redis.ErrNil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []streamEntry
+	for _, stream := range streams {
+		parts, err := redis.Values(stream, nil)
+		if err != nil || len(parts) != 2 {
+			return nil, errors.New("Unexpected stream reply")
+		}
+		items, err := redis.Values(parts[1], nil)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parseStreamRange(items)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, parsed...)
+	}
+
+	return entries, nil
+}
+
+// parseStreamRange decodes a list of stream entries, as returned by XRANGE
+func parseStreamRange(items []interface{}) ([]streamEntry, error) {
+	entries := make([]streamEntry, 0, len(items))
+	for _, item := range items {
+		fields, err := redis.Values(item, nil)
+		if err != nil || len(fields) != 2 {
+			return nil, errors.New("Unexpected stream entry")
+		}
+		id, err := redis.String(fields[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		entry := streamEntry{ID: id}
+		// the entries deleted from the stream are still listed as pending without fields
+		if fields[1] != nil {
+			if entry.Fields, err = redis.StringMap(fields[1], nil); err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// lastStreamID returns the id of the newest entry of the stream
+func lastStreamID(conn redis.Conn, stream string) (string, error) {
+	items, err := redis.Values(conn.Do("XREVRANGE", stream, "+", "-", "COUNT", 1))
+	if err != nil {
+		return "", err
+	}
+	entries, err := parseStreamRange(items)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "0-0", nil
+	}
+	return entries[0].ID, nil
+}
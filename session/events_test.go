@@ -0,0 +1,204 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/gomodule/redigo/redis"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+
+	"github.com/hobord/dsession/auth"
+)
+
+// newTestRedis starts an in-memory redis which runs the lua scripts
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Pool) {
+	mr := miniredis.RunT(t)
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", mr.Addr()) }}
+	t.Cleanup(func() { pool.Close() })
+	return mr, pool
+}
+
+func TestParseStreamEntries(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{
+			[]byte(eventStream),
+			[]interface{}{
+				[]interface{}{[]byte("1-0"), []interface{}{[]byte("event"), []byte("a")}},
+				// deleted entry which is still pending
+				[]interface{}{[]byte("2-0"), nil},
+			},
+		},
+	}
+
+	entries, err := parseStreamEntries(reply, nil)
+	if err != nil {
+		t.Fatalf("parseStreamEntries got unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Got %d entries, want 2", len(entries))
+	}
+	if entries[0].ID != "1-0" || entries[0].Fields["event"] != "a" {
+		t.Errorf("Got %v", entries[0])
+	}
+	if entries[1].ID != "2-0" || entries[1].Fields != nil {
+		t.Errorf("Got %v", entries[1])
+	}
+}
+
+func TestParseStreamEntriesTimeout(t *testing.T) {
+	entries, err := parseStreamEntries(nil, nil)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Got %v, %v", entries, err)
+	}
+
+	_, err = parseStreamEntries(nil, redis.Error("NOGROUP"))
+	if err == nil {
+		t.Errorf("Expected the redis error")
+	}
+}
+
+func TestSweepExpiries(t *testing.T) {
+	mr, pool := newTestRedis(t)
+	s := &GrpcRedisImplServer{RedisPool: pool}
+
+	// expired 10s ago, the notification was missed
+	mr.Set(expiryKeyPrefix+"expired", "shop")
+	mr.SetTTL(expiryKeyPrefix+"expired", (expiryMargin-10)*time.Second)
+	// still alive
+	mr.HSet("alive", ttlField, "60")
+	mr.Set(expiryKeyPrefix+"alive", "shop")
+	mr.SetTTL(expiryKeyPrefix+"alive", (expiryMargin+60)*time.Second)
+	// deleted before its ttl elapsed
+	mr.Set(expiryKeyPrefix+"deleted", "shop")
+	mr.SetTTL(expiryKeyPrefix+"deleted", (expiryMargin+60)*time.Second)
+
+	if err := s.sweepExpiries(); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if mr.Exists(expiryKeyPrefix+"expired") || !mr.Exists(expiryKeyPrefix+"alive") || !mr.Exists(expiryKeyPrefix+"deleted") {
+		t.Errorf("Got shadow keys %v", mr.Keys())
+	}
+	entries, err := mr.Stream(eventStream)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Got stream %v, %v", entries, err)
+	}
+	ev := &SessionEvent{}
+	if err := proto.Unmarshal([]byte(entries[0].Values[1]), ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != SessionEventType_SESSION_EXPIRED || ev.Id != "expired" || ev.Namespace != "shop" {
+		t.Errorf("Got %v", ev)
+	}
+	if expired := nowMillis() - ev.Time; expired < 9000 || expired > 12000 {
+		t.Errorf("Got expiry %dms ago, want 10s", expired)
+	}
+
+	// the notification of the same expiry is not recorded again
+	s.recordExpiry(&SessionEvent{Type: SessionEventType_SESSION_EXPIRED, Id: "expired"})
+	if entries, _ := mr.Stream(eventStream); len(entries) != 1 {
+		t.Errorf("Got %d entries, the expiry is recorded twice", len(entries))
+	}
+}
+
+func TestWriteExpiryCountsWrittenEvents(t *testing.T) {
+	mr, pool := newTestRedis(t)
+	s := &GrpcRedisImplServer{RedisPool: pool}
+	conn := pool.Get()
+	defer conn.Close()
+	expired := sessionEvents.WithLabelValues(SessionEventType_SESSION_EXPIRED.String())
+	before := testutil.ToFloat64(expired)
+
+	mr.Set(eventStream, "broken")
+	if err := s.writeExpiry(conn, &SessionEvent{Type: SessionEventType_SESSION_EXPIRED, Id: "expired"}, true); err == nil {
+		t.Errorf("Got no error of the broken stream")
+	}
+	if got := testutil.ToFloat64(expired) - before; got != 0 {
+		t.Errorf("Failed write is counted: %v", got)
+	}
+
+	mr.Del(eventStream)
+	if err := s.writeExpiry(conn, &SessionEvent{Type: SessionEventType_SESSION_EXPIRED, Id: "expired"}, true); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if got := testutil.ToFloat64(expired) - before; got != 1 {
+		t.Errorf("Got %v counted events, want 1", got)
+	}
+}
+
+func TestQueueExpiryDoesNotBlock(t *testing.T) {
+	s := &GrpcRedisImplServer{expiries: make(chan *SessionEvent, 1)}
+	ev := &SessionEvent{Type: SessionEventType_SESSION_EXPIRED, Id: "a"}
+	s.queueExpiry(ev)
+	s.queueExpiry(&SessionEvent{Type: SessionEventType_SESSION_EXPIRED, Id: "b"})
+
+	queued := <-s.expiries
+	if queued.Id != "a" || queued == ev {
+		t.Errorf("Got %v, want a copy of the event", queued)
+	}
+	if len(s.expiries) != 0 {
+		t.Errorf("Got %d queued, the overflow is left to the sweep", len(s.expiries))
+	}
+}
+
+// eventRecordStream is a server stream of SubscribeEvents which ends after the first record
+type eventRecordStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	cancel  context.CancelFunc
+	records []*EventRecord
+}
+
+func (e *eventRecordStream) Context() context.Context { return e.ctx }
+func (e *eventRecordStream) Send(rec *EventRecord) error {
+	e.records = append(e.records, rec)
+	e.cancel()
+	return nil
+}
+
+func TestSubscribeEventsHidesKeys(t *testing.T) {
+	_, pool := newTestRedis(t)
+	s := &GrpcRedisImplServer{RedisPool: pool, tokens: &tokenCodec{}}
+	conn := pool.Get()
+	defer conn.Close()
+	for _, ev := range []*SessionEvent{
+		{Type: SessionEventType_VALUES_DELETED, Id: testID, Keys: []string{"auth_token"}},
+		{Type: SessionEventType_VALUES_DELETED, Id: testID, Keys: []string{"auth_token", "cart"}},
+	} {
+		data, _ := proto.Marshal(ev)
+		if _, err := conn.Do("XADD", eventStream, "*", "event", data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, err := auth.NewAuthenticator(&auth.Policy{
+		Clients:  []*auth.Client{{Name: "backend", Anonymous: true, Methods: []string{"*"}}},
+		KeyRules: []*auth.KeyRule{{Keys: []string{"auth_*"}, Private: true}},
+	})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	p, err := a.Authenticate(context.Background(), "/hobord.session.DSessionAdminService/SubscribeEvents")
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(auth.NewContext(context.Background(), p), 5*time.Second)
+	defer cancel()
+	stream := &eventRecordStream{ctx: ctx, cancel: cancel}
+
+	s.SubscribeEvents(&SubscribeEventsMessage{Group: "g", Consumer: "c", StartId: "0"}, stream)
+
+	if len(stream.records) != 1 || len(stream.records[0].Event.Keys) != 1 || stream.records[0].Event.Keys[0] != "cart" {
+		t.Fatalf("Got records %v", stream.records)
+	}
+	pending, err := redis.Values(conn.Do("XPENDING", eventStream, "g", "-", "+", 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 {
+		t.Errorf("Got %d pending entries, the hidden event is acknowledged", len(pending))
+	}
+}
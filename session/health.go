@@ -0,0 +1,89 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/hobord/dsession/logging"
+)
+
+// healthServices are reported by the health service, the empty name is the whole server
+var healthServices = []string{"", "hobord.session.DSessionService", "hobord.session.DSessionAdminService"}
+
+// pingTimeout limits the wait for the answer of a health check PING
+const pingTimeout = 2 * time.Second
+
+var errNotChecked = errors.New("Redis is not checked yet")
+
+// redisHealth is the result of the last PING of redis
+type redisHealth struct {
+	mu      sync.Mutex
+	checked bool
+	err     error
+}
+
+// StartHealthChecks pings redis in every interval and reports the result
+// as the serving status of the services in the health server, until the context is done
+func (s *GrpcRedisImplServer) StartHealthChecks(ctx context.Context, hs *health.Server, interval time.Duration) {
+	s.setHealth(hs, s.pingRedis())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.setHealth(hs, s.pingRedis())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *GrpcRedisImplServer) pingRedis() error {
+	conn := s.RedisPool.Get()
+	defer conn.Close()
+	_, err := redis.DoWithTimeout(conn, pingTimeout, "PING")
+	return err
+}
+
+func (s *GrpcRedisImplServer) setHealth(hs *health.Server, err error) {
+	s.health.mu.Lock()
+	changed := !s.health.checked || (s.health.err == nil) != (err == nil)
+	s.health.checked = true
+	s.health.err = err
+	s.health.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if err != nil {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+		logging.Error(context.Background(), "Redis is unavailable", "error", err)
+	} else {
+		logging.Info(context.Background(), "Redis is available")
+	}
+	for _, service := range healthServices {
+		hs.SetServingStatus(service, status)
+	}
+}
+
+// Ready returns the error of the last redis health check, nil when redis answered and the server is not draining
+func (s *GrpcRedisImplServer) Ready() error {
+	if s.isDraining() {
+		return errDraining
+	}
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	if !s.health.checked {
+		return errNotChecked
+	}
+	return s.health.err
+}
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// pingConn answers PING with the error
+type pingConn struct {
+	err error
+}
+
+func (c *pingConn) Close() error                                   { return nil }
+func (c *pingConn) Err() error                                     { return nil }
+func (c *pingConn) Do(string, ...interface{}) (interface{}, error) { return "PONG", c.err }
+func (c *pingConn) Send(string, ...interface{}) error              { return nil }
+func (c *pingConn) Flush() error                                   { return nil }
+func (c *pingConn) Receive() (interface{}, error)                  { return nil, nil }
+func (c *pingConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	return c.Do(cmd, args...)
+}
+func (c *pingConn) ReceiveWithTimeout(time.Duration) (interface{}, error) { return nil, nil }
+
+func TestHealth(t *testing.T) {
+	conn := &pingConn{err: errors.New("connection refused")}
+	s := &GrpcRedisImplServer{RedisPool: &redis.Pool{Dial: func() (redis.Conn, error) { return conn, nil }}}
+	hs := health.NewServer()
+	check := func() healthpb.HealthCheckResponse_ServingStatus {
+		res, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "hobord.session.DSessionService"})
+		if err != nil {
+			t.Fatalf("Got unexpected error: %v", err)
+		}
+		return res.Status
+	}
+
+	if err := s.Ready(); err == nil {
+		t.Errorf("Ready before the first check")
+	}
+	s.setHealth(hs, s.pingRedis())
+	if check() != healthpb.HealthCheckResponse_NOT_SERVING || s.Ready() == nil {
+		t.Errorf("Serving without redis")
+	}
+	conn.err = nil
+	s.setHealth(hs, s.pingRedis())
+	if check() != healthpb.HealthCheckResponse_SERVING || s.Ready() != nil {
+		t.Errorf("Not serving with redis")
+	}
+}
+
+func TestHealthChecksStop(t *testing.T) {
+	var pings int32
+	s := &GrpcRedisImplServer{RedisPool: &redis.Pool{Dial: func() (redis.Conn, error) {
+		atomic.AddInt32(&pings, 1)
+		return &pingConn{}, nil
+	}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.StartHealthChecks(ctx, health.NewServer(), 5*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if got := atomic.LoadInt32(&pings); got < 2 {
+		t.Errorf("Got %d pings", got)
+	}
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	stopped := atomic.LoadInt32(&pings)
+	time.Sleep(30 * time.Millisecond)
+	if got := atomic.LoadInt32(&pings); got != stopped {
+		t.Errorf("Got %d pings after the stop, want %d", got, stopped)
+	}
+}
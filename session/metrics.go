@@ -0,0 +1,23 @@
+package session
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	sessionEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dsession_session_events_total",
+		Help: "Number of the session lifecycle events by type, like SESSION_CREATED, SESSION_INVALIDATED and SESSION_EXPIRED.",
+	}, []string{"type"})
+	valueSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "dsession_value_size_bytes",
+		Help:    "Size of the session values written, after the encoding.",
+		Buckets: prometheus.ExponentialBuckets(16, 4, 10),
+	})
+)
+
+// countEvent counts the event once it is written to redis
+func countEvent(ev *SessionEvent) {
+	sessionEvents.WithLabelValues(ev.Type.String()).Inc()
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"errors"
 	fmt "fmt"
-	"log"
-	"os"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	proto "github.com/golang/protobuf/proto"
@@ -14,138 +14,238 @@ import (
 	"github.com/gomodule/redigo/redis"
 
 	uuid "github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/config"
+	"github.com/hobord/dsession/logging"
+	"github.com/hobord/dsession/tracing"
+)
+
+// Metadata fields stored next to the values in the session hash
+const (
+	ttlField       = "__TTL"
+	createdField   = "__CREATED"
+	namespaceField = "__NAMESPACE"
+	// keyField is the data key of the session wrapped by a master key
+	keyField = "__KEY"
 )
 
+// sessionKeyPattern matches the redis keys of the sessions (uuids)
+const sessionKeyPattern = "????????-????-????-????-????????????"
+
 // GrpcRedisImplServer is used to implement session.
 type GrpcRedisImplServer struct {
-	RedisPool *redis.Pool
+	RedisPool    *redis.Pool
+	watchers     *watchHub
+	eventsMaxLen int64
+	tokens       *tokenCodec
+	values       *valueCipher
+	audit        *auditLog
+	health       redisHealth
+	expiries     chan *SessionEvent
+	draining     chan struct{}
+	drainOnce    sync.Once
 }
 
-func newRedisPool(server, password string, maxIdle int, idleTimeout int) *redis.Pool {
-	return &redis.Pool{
-		MaxIdle:     maxIdle,
-		IdleTimeout: time.Second * time.Duration(idleTimeout),
-		Dial: func() (redis.Conn, error) {
-			c, err := redis.Dial("tcp", server)
-			if err != nil {
-				return nil, err
-			}
-			if password != "" {
-				if _, err := c.Do("AUTH", password); err != nil {
-					c.Close()
-					return nil, err
-				}
-			}
-			return c, err
-		},
-		TestOnBorrow: func(c redis.Conn, t time.Time) error {
-			_, err := c.Do("PING")
-			return err
-		},
-	}
+func isMetaField(key string) bool {
+	return key == ttlField || key == createdField || key == namespaceField || key == keyField
 }
 
-// CreateRedisImpl is create an instance of redis implementation of session grpc
-func CreateRedisImpl() *GrpcRedisImplServer {
-	var err error
-	rdHost := os.Getenv("REDIS_HOST")
-	if rdHost == "" {
-		rdHost = "localhost"
-	}
-	rdPort := os.Getenv("REDIS_PORT")
-	if rdPort == "" {
-		rdPort = "6379"
-	}
+// getConn returns a connection of the pool which records its commands in the trace of the call
+func (s *GrpcRedisImplServer) getConn(ctx context.Context) redis.Conn {
+	return tracing.RedisConn(ctx, s.RedisPool.Get())
+}
 
-	rdDbEnv := os.Getenv("REDIS_DB")
-	if rdDbEnv == "" {
-		rdDbEnv = "0"
+// CreateRedisImpl is create an instance of redis implementation of session grpc,
+// configured by the environment variables
+func CreateRedisImpl() *GrpcRedisImplServer {
+	cfg, err := config.FromEnv()
+	if err != nil {
+		logging.Fatal("Failed to read the configuration", "error", err)
 	}
-	rdDb, err := strconv.Atoi(rdDbEnv)
+	impl, err := NewRedisImpl(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect parse redis-server DB (%s)", rdDbEnv)
+		logging.Fatal("Failed to create the session service", "error", err)
 	}
+	return impl
+}
 
-	password := os.Getenv("REDIS_PASSWORD")
-
-	maxIdleEnv := os.Getenv("REDIS_MAXIDLE")
-	if maxIdleEnv == "" {
-		maxIdleEnv = "3"
+// NewRedisImpl creates the redis implementation of session grpc by the redis and the sessions settings
+func NewRedisImpl(cfg *config.Config) (*GrpcRedisImplServer, error) {
+	redisOpts, err := redisOptionsFromConfig(cfg.Redis)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid redis connection settings: %s", err)
 	}
-	maxIdle, err := strconv.Atoi(maxIdleEnv)
+	tokens, err := newTokenCodec(cfg.Sessions.TokenKeys, cfg.Sessions.TokenRequired)
 	if err != nil {
-		log.Fatalf("Failed to connect parse redis-server DB (%s)", rdDbEnv)
+		return nil, fmt.Errorf("Invalid session token keys: %s", err)
 	}
-
-	maxTimeOutEnv := os.Getenv("REDIS_MAXTIMEOUT")
-	if maxTimeOutEnv == "" {
-		maxTimeOutEnv = "240"
+	values, err := loadValueCipher(cfg.Sessions.ValueEncryptionKeys, cfg.Sessions.ValueEncryptionKeysFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load the value encryption keys: %s", err)
 	}
-	maxTimeOut, err := strconv.Atoi(maxTimeOutEnv)
+	redisPool, err := newRedisPool(redisOpts)
 	if err != nil {
-		log.Fatalf("Failed to connect parse redis-server DB (%s)", rdDbEnv)
+		return nil, fmt.Errorf("Failed to configure the redis connection: %s", err)
 	}
-
-	rediserver := rdHost + ":" + rdPort
-	// Redigo Client
-	redisPool := newRedisPool(rediserver, password, maxIdle, maxTimeOut)
-	log.Printf("Connecting to Redis (%s) DB=%d Success...", rediserver, rdDb)
+	logging.Info(context.Background(), "Connecting to Redis", "address", redisOpts.Address, "db", redisOpts.DB, "tls", redisOpts.TLS)
 
 	impl := &GrpcRedisImplServer{
-		RedisPool: redisPool,
+		RedisPool:    redisPool,
+		watchers:     newWatchHub(redisPool, redisOpts.DB),
+		eventsMaxLen: cfg.Sessions.EventsMaxLen,
+		tokens:       tokens,
+		values:       values,
+		expiries:     make(chan *SessionEvent, expiryQueue),
+		draining:     make(chan struct{}),
 	}
-	return impl
+	impl.watchers.onExpired = impl.queueExpiry
+	impl.watchers.values = values
+	impl.watchers.configureNotifications = cfg.Redis.ConfigureNotifications
+	return impl, nil
 }
 
 // CreateSession is create a new empty session
 func (s *GrpcRedisImplServer) CreateSession(ctx context.Context, in *CreateSessionMessage) (*SessionResponse, error) {
-	var err error
-	conn := s.RedisPool.Get()
+	err := authorizeNamespace(ctx, in.Namespace)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	conn := s.getConn(ctx)
 	defer conn.Close()
 	uuid := uuid.New()
+	ttlstr := "0"
+	if in.Ttl > 0 {
+		ttlstr = fmt.Sprintf("%d", in.Ttl)
+	}
+	args := redis.Args{uuid.String(),
+		ttlField, ttlstr,
+		createdField, time.Now().Unix(),
+		namespaceField, in.Namespace}
+	wrapped, err := s.values.newDataKey(uuid.String())
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	if wrapped != "" {
+		args = args.Add(keyField, wrapped)
+	}
+	err = conn.Send("HSET", args...)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
 	if in.Ttl > 0 {
-		ttlstr := fmt.Sprintf("%d", in.Ttl)
-		err = s.addValueToSession(conn, uuid.String(), "__TTL", ttlstr)
-		if err != nil {
-			return &SessionResponse{}, err
-		}
 		conn.Send("EXPIRE", uuid.String(), ttlstr)
-	} else {
-		err = s.addValueToSession(conn, uuid.String(), "__TTL", "0")
-		if err != nil {
-			return &SessionResponse{}, err
-		}
+		sendExpiryShadow(conn, uuid.String(), in.Namespace, in.Ttl)
+	}
+	ev := &SessionEvent{
+		Type:      SessionEventType_SESSION_CREATED,
+		Id:        uuid.String(),
+		Ttl:       in.Ttl,
+		Namespace: in.Namespace,
+	}
+	err = s.sendEvent(conn, ev)
+	if err != nil {
+		return &SessionResponse{}, err
 	}
 
 	err = conn.Flush()
 	if err != nil {
 		return &SessionResponse{}, err
 	}
+	countEvent(ev)
 	var values map[string]*st.Value
-	return &SessionResponse{Id: uuid.String(), Values: values}, nil
+	return &SessionResponse{Id: s.tokens.sign(uuid.String()), Values: values}, nil
 }
 
 func (s *GrpcRedisImplServer) addValueToSession(conn redis.Conn, id, key, value string) error {
-	if key != "__TTL" {
-		res, err := redis.StringMap(conn.Do("HGETALL", id))
+	if isMetaField(key) {
+		return fmt.Errorf("Key %s is reserved", key)
+	}
+	res, err := redis.StringMap(conn.Do("HGETALL", id))
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		return errors.New("Session already go on...")
+	}
+	dataKey, err := s.sessionDataKey(conn, id, res[keyField])
+	if err != nil {
+		return err
+	}
+	value, err = dataKey.seal(key, value)
+	if err != nil {
+		return err
+	}
+	valueSize.Observe(float64(len(value)))
+	return conn.Send("HSET", id, key, value)
+}
+
+// dataKeyScript stores the data key ARGV[1] into the field ARGV[2] of the session KEYS[1]
+// unless it has one, and returns the data key of the session, nil if the session does not exist
+var dataKeyScript = redis.NewScript(1, `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+redis.call('HSETNX', KEYS[1], ARGV[2], ARGV[1])
+return redis.call('HGET', KEYS[1], ARGV[2])
+`)
+
+// sessionDataKey returns the data key of the session, wrapped is its stored form when it is known.
+// The sessions written before the data keys get one, the concurrent writers agree on it.
+func (s *GrpcRedisImplServer) sessionDataKey(conn redis.Conn, id, wrapped string) (*dataKey, error) {
+	if !s.values.enabled() {
+		return nil, nil
+	}
+	if wrapped == "" {
+		created, err := s.values.newDataKey(id)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		wrapped, err = redis.String(dataKeyScript.Do(conn, id, created, keyField))
+		if err == redis.ErrNil {
+			return nil, status.Errorf(codes.NotFound, "Session %s not found", s.tokens.sign(id))
 		}
-		if len(res) == 0 {
-			return errors.New("Session already go on...")
+		if err != nil {
+			return nil, err
 		}
 	}
-	return conn.Send("HSET", id, key, value)
+	return s.values.dataKey(id, wrapped)
 }
 
 // AddValueToSession is add value into the existing session
 func (s *GrpcRedisImplServer) AddValueToSession(ctx context.Context, in *AddValueToSessionMessage) (*SessionResponse, error) {
-	var err error
-	conn := s.RedisPool.Get()
+	id, err := s.tokens.verify(in.Id)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	conn := s.getConn(ctx)
 	defer conn.Close()
+	if err := authorizeSession(ctx, conn, id); err != nil {
+		return &SessionResponse{}, err
+	}
+
+	if err := authorizeKeys(ctx, []string{in.Key}); err != nil {
+		return &SessionResponse{}, err
+	}
 
 	data := proto.MarshalTextString(in.Value)
-	err = s.addValueToSession(conn, in.Id, in.Key, data)
+	err = s.addValueToSession(conn, id, in.Key, data)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	namespace, err := sessionNamespace(conn, id)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	ev := &SessionEvent{
+		Type:      SessionEventType_VALUES_CHANGED,
+		Id:        id,
+		Keys:      []string{in.Key},
+		Values:    map[string]*st.Value{in.Key: in.Value},
+		Namespace: namespace,
+	}
+	err = s.sendEvent(conn, ev)
 	if err != nil {
 		return &SessionResponse{}, err
 	}
@@ -153,129 +253,422 @@ func (s *GrpcRedisImplServer) AddValueToSession(ctx context.Context, in *AddValu
 	if err != nil {
 		return &SessionResponse{}, err
 	}
+	countEvent(ev)
 
-	session, err := s.getValuesBySessionID(conn, in.Id)
+	session, err := s.getValuesBySessionID(conn, id)
 	if err != nil {
 		return session, err
 	}
+	session.Values = visibleValues(ctx, session.Values)
 
 	return session, nil
 }
 
 // AddValuesToSession is add multiple values into the session
 func (s *GrpcRedisImplServer) AddValuesToSession(ctx context.Context, in *AddValuesToSessionMessage) (*SessionResponse, error) {
-	conn := s.RedisPool.Get()
+	id, err := s.tokens.verify(in.Id)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	conn := s.getConn(ctx)
 	defer conn.Close()
+	if err := authorizeSession(ctx, conn, id); err != nil {
+		return &SessionResponse{}, err
+	}
 	var values map[string]*st.Value
 
+	keys := make([]string, 0, len(in.Values))
+	for key := range in.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if err := authorizeKeys(ctx, keys); err != nil {
+		return &SessionResponse{}, err
+	}
 	for key, val := range in.Values {
 		data := proto.MarshalTextString(val)
-		err := s.addValueToSession(conn, in.Id, key, data)
+		err := s.addValueToSession(conn, id, key, data)
 		if err != nil {
 			return &SessionResponse{Id: "", Values: values}, err
 		}
 	}
-	err := conn.Flush()
+	namespace, err := sessionNamespace(conn, id)
 	if err != nil {
 		return &SessionResponse{}, err
 	}
+	ev := &SessionEvent{
+		Type:      SessionEventType_VALUES_CHANGED,
+		Id:        id,
+		Keys:      keys,
+		Values:    in.Values,
+		Namespace: namespace,
+	}
+	err = s.sendEvent(conn, ev)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	err = conn.Flush()
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	countEvent(ev)
 
-	session, err := s.getValuesBySessionID(conn, in.Id)
+	session, err := s.getValuesBySessionID(conn, id)
 	if err != nil {
 		return session, err
 	}
+	session.Values = visibleValues(ctx, session.Values)
 
 	return session, nil
 }
 
 // GetSession return the session by id
 func (s *GrpcRedisImplServer) GetSession(ctx context.Context, in *GetSessionMessage) (*SessionResponse, error) {
-	conn := s.RedisPool.Get()
+	id, err := s.tokens.verify(in.Id)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	conn := s.getConn(ctx)
 	defer conn.Close()
-	session, err := s.getValuesBySessionID(conn, in.Id)
+	if err := authorizeSession(ctx, conn, id); err != nil {
+		return &SessionResponse{}, err
+	}
+	session, err := s.getValuesBySessionID(conn, id)
 	if err != nil {
 		return &SessionResponse{}, err
 	}
+	session.Values = visibleValues(ctx, session.Values)
 
 	return session, nil
 }
 
+// maxBatchSize limits the number of ids of one GetSessions call
+const maxBatchSize = 1000
+
+// GetSessions return multiple sessions by id, reading them in one redis pipeline
+func (s *GrpcRedisImplServer) GetSessions(ctx context.Context, in *GetSessionsMessage) (*SessionsResponse, error) {
+	if len(in.Ids) > maxBatchSize {
+		return &SessionsResponse{}, status.Errorf(codes.InvalidArgument, "Too many ids: %d > %d", len(in.Ids), maxBatchSize)
+	}
+	response := &SessionsResponse{Results: make([]*SessionResult, 0, len(in.Ids))}
+	var pending []*SessionResult
+	var ids []string
+	for _, token := range in.Ids {
+		result := &SessionResult{Id: token}
+		response.Results = append(response.Results, result)
+		id, err := s.tokens.verify(token)
+		if err != nil {
+			result.Code = int32(codes.InvalidArgument)
+			result.Error = status.Convert(err).Message()
+			continue
+		}
+		pending = append(pending, result)
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return response, nil
+	}
+
+	conn := s.getConn(ctx)
+	defer conn.Close()
+
+	for _, id := range ids {
+		if err := conn.Send("HGETALL", id); err != nil {
+			return &SessionsResponse{}, err
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		return &SessionsResponse{}, err
+	}
+
+	for i, id := range ids {
+		result := pending[i]
+
+		fields, err := redis.StringMap(conn.Receive())
+		if err != nil {
+			if _, ok := err.(redis.Error); !ok {
+				return &SessionsResponse{}, err
+			}
+			result.Code = int32(codes.Internal)
+			result.Error = err.Error()
+			continue
+		}
+		if len(fields) == 0 {
+			result.Code = int32(codes.NotFound)
+			result.Error = "Session not found"
+			continue
+		}
+
+		stored, err := parseStoredSession(id, fields, s.values)
+		if err != nil {
+			result.Code = int32(codes.DataLoss)
+			result.Error = err.Error()
+			continue
+		}
+		if err := authorizeNamespace(ctx, stored.Namespace); err != nil {
+			result.Code = int32(codes.PermissionDenied)
+			result.Error = status.Convert(err).Message()
+			continue
+		}
+		result.Session = &SessionResponse{Id: s.tokens.sign(id), Values: visibleValues(ctx, stored.Values)}
+	}
+
+	return response, nil
+}
+
 // InvalidateSession is delete the session
 func (s *GrpcRedisImplServer) InvalidateSession(ctx context.Context, in *InvalidateSessionMessage) (*SuccessMessage, error) {
-	conn := s.RedisPool.Get()
+	id, err := s.tokens.verify(in.Id)
+	if err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	conn := s.getConn(ctx)
 	defer conn.Close()
-	err := conn.Send("DEL", in.Id)
+	if err := authorizeSession(ctx, conn, id); err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	namespace, err := sessionNamespace(conn, id)
+	if err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	err = conn.Send("DEL", id, expiryKeyPrefix+id)
+	if err != nil {
+		logging.Error(ctx, "Failed to delete the session", "session", logging.SessionID(id), "error", err)
+		return &SuccessMessage{Successfull: false}, err
+	}
+	ev := &SessionEvent{Type: SessionEventType_SESSION_INVALIDATED, Id: id, Namespace: namespace}
+	err = s.sendEvent(conn, ev)
 	if err != nil {
-		fmt.Printf("Something went wrong: %s", err)
 		return &SuccessMessage{Successfull: false}, err
 	}
 	err = conn.Flush()
 	if err != nil {
 		return &SuccessMessage{Successfull: false}, err
 	}
+	countEvent(ev)
 
 	return &SuccessMessage{Successfull: true}, nil
 }
 
 func (s *GrpcRedisImplServer) invalidateSessionValue(conn redis.Conn, id string, key string) error {
-	return conn.Send("HDEL ", id, key)
+	return conn.Send("HDEL", id, key)
 }
 
 // InvalidateSessionValue is remove one key from the session
 func (s *GrpcRedisImplServer) InvalidateSessionValue(ctx context.Context, in *InvalidateSessionValueMessage) (*SuccessMessage, error) {
-	conn := s.RedisPool.Get()
+	id, err := s.tokens.verify(in.Id)
+	if err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	conn := s.getConn(ctx)
 	defer conn.Close()
-	err := s.invalidateSessionValue(conn, in.Id, in.Key)
+	if err := authorizeSession(ctx, conn, id); err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	namespace, err := sessionNamespace(conn, id)
+	if err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	if err := authorizeKeys(ctx, []string{in.Key}); err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	err = s.invalidateSessionValue(conn, id, in.Key)
+	if err != nil {
+		logging.Error(ctx, "Failed to delete the session value", "session", logging.SessionID(id), "error", err)
+		return &SuccessMessage{Successfull: false}, err
+	}
+	ev := &SessionEvent{
+		Type:      SessionEventType_VALUES_DELETED,
+		Id:        id,
+		Keys:      []string{in.Key},
+		Namespace: namespace,
+	}
+	err = s.sendEvent(conn, ev)
 	if err != nil {
-		fmt.Printf("Something went wrong: %s", err)
 		return &SuccessMessage{Successfull: false}, err
 	}
 	err = conn.Flush()
 	if err != nil {
 		return &SuccessMessage{Successfull: false}, err
 	}
+	countEvent(ev)
 
 	return &SuccessMessage{Successfull: true}, nil
 }
 
 // InvalidateSessionValues is remove multiple keys from the session
 func (s *GrpcRedisImplServer) InvalidateSessionValues(ctx context.Context, in *InvalidateSessionValuesMessage) (*SuccessMessage, error) {
-	conn := s.RedisPool.Get()
+	id, err := s.tokens.verify(in.Id)
+	if err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	conn := s.getConn(ctx)
 	defer conn.Close()
+	if err := authorizeSession(ctx, conn, id); err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	namespace, err := sessionNamespace(conn, id)
+	if err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	if err := authorizeKeys(ctx, in.Keys); err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
 	for _, key := range in.Keys {
-		err := s.invalidateSessionValue(conn, in.Id, key)
+		err := s.invalidateSessionValue(conn, id, key)
 		if err != nil {
 			return &SuccessMessage{Successfull: false}, err
 		}
 	}
-	err := conn.Flush()
+	ev := &SessionEvent{
+		Type:      SessionEventType_VALUES_DELETED,
+		Id:        id,
+		Keys:      in.Keys,
+		Namespace: namespace,
+	}
+	err = s.sendEvent(conn, ev)
+	if err != nil {
+		return &SuccessMessage{Successfull: false}, err
+	}
+	err = conn.Flush()
 	if err != nil {
 		return &SuccessMessage{Successfull: false}, err
 	}
+	countEvent(ev)
 
 	return &SuccessMessage{Successfull: true}, nil
 }
 
+// RenewSession sets the expiration of the session again, with a new ttl if it is given
+func (s *GrpcRedisImplServer) RenewSession(ctx context.Context, in *RenewSessionMessage) (*SessionResponse, error) {
+	id, err := s.tokens.verify(in.Id)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	conn := s.getConn(ctx)
+	defer conn.Close()
+	if err := authorizeSession(ctx, conn, id); err != nil {
+		return &SessionResponse{}, err
+	}
+
+	session, err := s.getValuesBySessionID(conn, id)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	storedTTL, err := redis.Int64(conn.Do("HGET", id, ttlField))
+	if err == redis.ErrNil {
+		return &SessionResponse{}, status.Errorf(codes.NotFound, "Session %s not found", in.Id)
+	}
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	ttl := in.Ttl
+	if ttl <= 0 {
+		ttl = storedTTL
+	}
+
+	namespace, err := sessionNamespace(conn, id)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+
+	if ttl > 0 {
+		conn.Send("HSET", id, ttlField, ttl)
+		conn.Send("EXPIRE", id, ttl)
+	} else {
+		conn.Send("PERSIST", id)
+	}
+	sendExpiryShadow(conn, id, namespace, ttl)
+	ev := &SessionEvent{Type: SessionEventType_TTL_RENEWED, Id: id, Ttl: ttl, Namespace: namespace}
+	err = s.sendEvent(conn, ev)
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	err = conn.Flush()
+	if err != nil {
+		return &SessionResponse{}, err
+	}
+	countEvent(ev)
+	session.Values = visibleValues(ctx, session.Values)
+
+	return session, nil
+}
+
 func (s *GrpcRedisImplServer) getValuesBySessionID(conn redis.Conn, id string) (*SessionResponse, error) {
-	response := &SessionResponse{Id: id, Values: make(map[string]*st.Value)}
+	response := &SessionResponse{Id: s.tokens.sign(id), Values: make(map[string]*st.Value)}
 
 	res, err := conn.Do("HGETALL", id)
-	values, err := redis.StringMap(res, err)
+	fields, err := redis.StringMap(res, err)
 	if err != nil {
 		return response, err
 	}
 
-	for key, hval := range values {
-		if key != "__TTL" {
+	stored, err := parseStoredSession(id, fields, s.values)
+	if err != nil {
+		return response, err
+	}
+	response.Values = stored.Values
+
+	return response, nil
+}
+
+// storedSession is the decoded form of a session hash
+type storedSession struct {
+	ID        string
+	Namespace string
+	CreatedAt int64
+	TTL       int64
+	Values    map[string]*st.Value
+}
+
+// summary returns the listing of the session with its signed token, remaining is the TTL of the key
+func (stored *storedSession) summary(tokens *tokenCodec, remaining int64) *SessionSummary {
+	keys := make([]string, 0, len(stored.Values))
+	for key := range stored.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return &SessionSummary{
+		Id:           tokens.sign(stored.ID),
+		Namespace:    stored.Namespace,
+		CreatedAt:    stored.CreatedAt,
+		Ttl:          stored.TTL,
+		RemainingTtl: remaining,
+		Keys:         keys,
+	}
+}
+
+func parseStoredSession(id string, fields map[string]string, values *valueCipher) (*storedSession, error) {
+	stored := &storedSession{ID: id, Values: make(map[string]*st.Value)}
+	dataKey, err := values.dataKey(id, fields[keyField])
+	if err != nil {
+		return stored, err
+	}
+
+	for key, hval := range fields {
+		switch key {
+		case ttlField:
+			stored.TTL, _ = strconv.ParseInt(hval, 10, 64)
+		case createdField:
+			stored.CreatedAt, _ = strconv.ParseInt(hval, 10, 64)
+		case namespaceField:
+			stored.Namespace = hval
+		case keyField:
+			// the data key is unwrapped above
+		default:
+			text, err := values.open(dataKey, id, key, hval)
+			if err != nil {
+				return stored, err
+			}
 			val := st.Value{}
-			err := proto.UnmarshalText(hval, &val)
+			err = proto.UnmarshalText(text, &val)
 			if err != nil {
-				return response, err
+				return stored, err
 			}
 
-			response.Values[key] = &val
+			stored.Values[key] = &val
 		}
 	}
 
-	return response, nil
+	return stored, nil
 }
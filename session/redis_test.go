@@ -1,8 +1,15 @@
 package session
 
 import (
+	"context"
 	"reflect"
+	"strings"
 	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/auth"
 	// "github.com/gomodule/redigo/redis"
 	// "github.com/rafaeljusto/redigomock"
 )
@@ -25,6 +32,62 @@ func TestCreateRedisImpl(t *testing.T) {
 	}
 }
 
+func TestGetSessions(t *testing.T) {
+	mr, pool := newTestRedis(t)
+	codec, _ := newTokenCodec("k1="+testKey('a'), true)
+	s := &GrpcRedisImplServer{RedisPool: pool, tokens: codec}
+	const (
+		missing = "1c2d3e4f-a0bd-4c55-ab49-00c4ed5a4091"
+		corrupt = "2d3e4f5a-a0bd-4c55-ab49-00c4ed5a4091"
+		blog    = "3e4f5a6b-a0bd-4c55-ab49-00c4ed5a4091"
+	)
+	mr.HSet(testID, namespaceField, "shop", ttlField, "60", "foo", "number_value:15")
+	mr.HSet(corrupt, namespaceField, "shop", ttlField, "60", "foo", "{{{")
+	mr.HSet(blog, namespaceField, "blog", ttlField, "60")
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Client: &auth.Client{Name: "shop", Namespaces: []string{"shop"}}})
+
+	tests := []struct {
+		name  string
+		token string
+		code  codes.Code
+	}{
+		{"found", codec.sign(testID), codes.OK},
+		{"raw id", testID, codes.InvalidArgument},
+		{"tampered", strings.Replace(codec.sign(testID), "8f60", "9f60", 1), codes.InvalidArgument},
+		{"missing", codec.sign(missing), codes.NotFound},
+		{"corrupt", codec.sign(corrupt), codes.DataLoss},
+		{"other namespace", codec.sign(blog), codes.PermissionDenied},
+	}
+	var tokens []string
+	for _, tt := range tests {
+		tokens = append(tokens, tt.token)
+	}
+	res, err := s.GetSessions(ctx, &GetSessionsMessage{Ids: tokens})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if len(res.Results) != len(tests) {
+		t.Fatalf("Got %d results", len(res.Results))
+	}
+	for i, tt := range tests {
+		result := res.Results[i]
+		if result.Id != tt.token || codes.Code(result.Code) != tt.code {
+			t.Errorf("%s: Got %s %v %s", tt.name, result.Id, codes.Code(result.Code), result.Error)
+		}
+		if (result.Session != nil) != (tt.code == codes.OK) {
+			t.Errorf("%s: Got session %v", tt.name, result.Session)
+		}
+	}
+	if found := res.Results[0].Session; found.Id != codec.sign(testID) || found.Values["foo"].GetNumberValue() != 15 {
+		t.Errorf("Got session %v", found)
+	}
+
+	_, err = s.GetSessions(ctx, &GetSessionsMessage{Ids: make([]string, maxBatchSize+1)})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Got error %v for too many ids", err)
+	}
+}
+
 // func TestCreateSession(t *testing.T) {
 // 	mockPool := &redis.Pool{
 // 		// Other pool configuration not shown in this example.
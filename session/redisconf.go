@@ -0,0 +1,199 @@
+package session
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/hobord/dsession/config"
+	"github.com/hobord/dsession/metrics"
+)
+
+// redisOptions configures the connections of the redis pool
+type redisOptions struct {
+	Address  string
+	Username string
+	Password string
+	DB       int
+
+	TLS           bool
+	TLSCAFile     string
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSServerName string
+	TLSSkipVerify bool
+
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+
+	MaxIdle     int
+	IdleTimeout time.Duration
+}
+
+// redisOptionsFromConfig reads the connection settings, the url is the base
+// and the other settings override its parts when they are set
+func redisOptionsFromConfig(c config.Redis) (*redisOptions, error) {
+	o := &redisOptions{
+		Address:        "localhost:6379",
+		ConnectTimeout: time.Duration(c.ConnectTimeout),
+		ReadTimeout:    time.Duration(c.ReadTimeout),
+		WriteTimeout:   time.Duration(c.WriteTimeout),
+		MaxIdle:        c.MaxIdle,
+		IdleTimeout:    time.Duration(c.IdleTimeout),
+	}
+	if c.URL != "" {
+		if err := parseRedisURL(c.URL, o); err != nil {
+			return nil, err
+		}
+	}
+
+	if host, port := c.Host, c.Port; host != "" || port != "" {
+		defHost, defPort, _ := net.SplitHostPort(o.Address)
+		if host == "" {
+			host = defHost
+		}
+		if port == "" {
+			port = defPort
+		}
+		o.Address = net.JoinHostPort(host, port)
+	}
+	if c.Username != "" {
+		o.Username = c.Username
+	}
+	if c.Password != "" {
+		o.Password = c.Password
+	}
+	if c.DB != 0 {
+		o.DB = c.DB
+	}
+
+	o.TLSCAFile = c.TLSCAFile
+	o.TLSCertFile = c.TLSCertFile
+	o.TLSKeyFile = c.TLSKeyFile
+	o.TLSServerName = c.TLSServerName
+	o.TLSSkipVerify = c.TLSInsecureSkipVerify
+	if c.TLS || o.TLSCAFile != "" || o.TLSCertFile != "" {
+		o.TLS = true
+	}
+	return o, nil
+}
+
+// parseRedisURL reads redis://[[user]:password@]host[:port][/db], rediss:// enables TLS
+func parseRedisURL(raw string, o *redisOptions) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("Invalid redis url: %s", err)
+	}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		o.TLS = true
+	default:
+		return fmt.Errorf("Invalid redis url scheme %q", u.Scheme)
+	}
+
+	host, port := u.Hostname(), u.Port()
+	if host == "" {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "6379"
+	}
+	o.Address = net.JoinHostPort(host, port)
+
+	if u.User != nil {
+		o.Username = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			o.Password = password
+		}
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if o.DB, err = strconv.Atoi(db); err != nil {
+			return fmt.Errorf("Invalid redis url database %q", db)
+		}
+	}
+	return nil
+}
+
+// tlsConfig returns the client TLS configuration of the options
+func (o *redisOptions) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{ServerName: o.TLSServerName, InsecureSkipVerify: o.TLSSkipVerify}
+	if config.ServerName == "" {
+		config.ServerName, _, _ = net.SplitHostPort(o.Address)
+	}
+	if o.TLSCAFile != "" {
+		pem, err := ioutil.ReadFile(o.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificate found in %s", o.TLSCAFile)
+		}
+	}
+	if o.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// dialOptions returns the redigo options of the connections
+func (o *redisOptions) dialOptions() ([]redis.DialOption, error) {
+	opts := []redis.DialOption{
+		redis.DialConnectTimeout(o.ConnectTimeout),
+		redis.DialReadTimeout(o.ReadTimeout),
+		redis.DialWriteTimeout(o.WriteTimeout),
+	}
+	if o.Password != "" {
+		opts = append(opts, redis.DialPassword(o.Password))
+		if o.Username != "" {
+			opts = append(opts, redis.DialUsername(o.Username))
+		}
+	}
+	if o.DB != 0 {
+		opts = append(opts, redis.DialDatabase(o.DB))
+	}
+	if o.TLS {
+		config, err := o.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, redis.DialUseTLS(true), redis.DialTLSConfig(config))
+	}
+	return opts, nil
+}
+
+func newRedisPool(o *redisOptions) (*redis.Pool, error) {
+	opts, err := o.dialOptions()
+	if err != nil {
+		return nil, err
+	}
+	return &redis.Pool{
+		MaxIdle:     o.MaxIdle,
+		IdleTimeout: o.IdleTimeout,
+		Dial: func() (redis.Conn, error) {
+			c, err := redis.Dial("tcp", o.Address, opts...)
+			if err != nil {
+				return nil, err
+			}
+			return metrics.InstrumentRedisConn(c), nil
+		},
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			_, err := c.Do("PING")
+			return err
+		},
+	}, nil
+}
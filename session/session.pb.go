@@ -25,6 +25,102 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type SessionEventType int32
+
+const (
+	SessionEventType_UNKNOWN_EVENT       SessionEventType = 0
+	SessionEventType_SESSION_CREATED     SessionEventType = 1
+	SessionEventType_VALUES_CHANGED      SessionEventType = 2
+	SessionEventType_VALUES_DELETED      SessionEventType = 3
+	SessionEventType_TTL_RENEWED         SessionEventType = 4
+	SessionEventType_SESSION_EXPIRED     SessionEventType = 5
+	SessionEventType_SESSION_INVALIDATED SessionEventType = 6
+)
+
+var SessionEventType_name = map[int32]string{
+	0: "UNKNOWN_EVENT",
+	1: "SESSION_CREATED",
+	2: "VALUES_CHANGED",
+	3: "VALUES_DELETED",
+	4: "TTL_RENEWED",
+	5: "SESSION_EXPIRED",
+	6: "SESSION_INVALIDATED",
+}
+
+var SessionEventType_value = map[string]int32{
+	"UNKNOWN_EVENT":       0,
+	"SESSION_CREATED":     1,
+	"VALUES_CHANGED":      2,
+	"VALUES_DELETED":      3,
+	"TTL_RENEWED":         4,
+	"SESSION_EXPIRED":     5,
+	"SESSION_INVALIDATED": 6,
+}
+
+func (x SessionEventType) String() string {
+	return proto.EnumName(SessionEventType_name, int32(x))
+}
+
+func (SessionEventType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{0}
+}
+
+type JobState int32
+
+const (
+	JobState_JOB_RUNNING JobState = 0
+	JobState_JOB_DONE    JobState = 1
+	JobState_JOB_FAILED  JobState = 2
+)
+
+var JobState_name = map[int32]string{
+	0: "JOB_RUNNING",
+	1: "JOB_DONE",
+	2: "JOB_FAILED",
+}
+
+var JobState_value = map[string]int32{
+	"JOB_RUNNING": 0,
+	"JOB_DONE":    1,
+	"JOB_FAILED":  2,
+}
+
+func (x JobState) String() string {
+	return proto.EnumName(JobState_name, int32(x))
+}
+
+func (JobState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{1}
+}
+
+type ConflictPolicy int32
+
+const (
+	ConflictPolicy_CONFLICT_SKIP      ConflictPolicy = 0
+	ConflictPolicy_CONFLICT_OVERWRITE ConflictPolicy = 1
+	ConflictPolicy_CONFLICT_MERGE     ConflictPolicy = 2
+)
+
+var ConflictPolicy_name = map[int32]string{
+	0: "CONFLICT_SKIP",
+	1: "CONFLICT_OVERWRITE",
+	2: "CONFLICT_MERGE",
+}
+
+var ConflictPolicy_value = map[string]int32{
+	"CONFLICT_SKIP":      0,
+	"CONFLICT_OVERWRITE": 1,
+	"CONFLICT_MERGE":     2,
+}
+
+func (x ConflictPolicy) String() string {
+	return proto.EnumName(ConflictPolicy_name, int32(x))
+}
+
+func (ConflictPolicy) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{2}
+}
+
 type SuccessMessage struct {
 	Successfull          bool     `protobuf:"varint,1,opt,name=Successfull,proto3" json:"Successfull,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -66,6 +162,7 @@ func (m *SuccessMessage) GetSuccessfull() bool {
 
 type CreateSessionMessage struct {
 	Ttl                  int64    `protobuf:"varint,1,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Namespace            string   `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -103,6 +200,13 @@ func (m *CreateSessionMessage) GetTtl() int64 {
 	return 0
 }
 
+func (m *CreateSessionMessage) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
 type GetSessionMessage struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -142,6 +246,45 @@ func (m *GetSessionMessage) GetId() string {
 	return ""
 }
 
+type GetSessionsMessage struct {
+	Ids                  []string `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetSessionsMessage) Reset()         { *m = GetSessionsMessage{} }
+func (m *GetSessionsMessage) String() string { return proto.CompactTextString(m) }
+func (*GetSessionsMessage) ProtoMessage()    {}
+func (*GetSessionsMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{3}
+}
+
+func (m *GetSessionsMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetSessionsMessage.Unmarshal(m, b)
+}
+func (m *GetSessionsMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetSessionsMessage.Marshal(b, m, deterministic)
+}
+func (m *GetSessionsMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetSessionsMessage.Merge(m, src)
+}
+func (m *GetSessionsMessage) XXX_Size() int {
+	return xxx_messageInfo_GetSessionsMessage.Size(m)
+}
+func (m *GetSessionsMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetSessionsMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetSessionsMessage proto.InternalMessageInfo
+
+func (m *GetSessionsMessage) GetIds() []string {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
 type AddValueToSessionMessage struct {
 	Id                   string         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Key                  string         `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
@@ -155,7 +298,7 @@ func (m *AddValueToSessionMessage) Reset()         { *m = AddValueToSessionMessa
 func (m *AddValueToSessionMessage) String() string { return proto.CompactTextString(m) }
 func (*AddValueToSessionMessage) ProtoMessage()    {}
 func (*AddValueToSessionMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3a6be1b361fa6f14, []int{3}
+	return fileDescriptor_3a6be1b361fa6f14, []int{4}
 }
 
 func (m *AddValueToSessionMessage) XXX_Unmarshal(b []byte) error {
@@ -209,7 +352,7 @@ func (m *AddValuesToSessionMessage) Reset()         { *m = AddValuesToSessionMes
 func (m *AddValuesToSessionMessage) String() string { return proto.CompactTextString(m) }
 func (*AddValuesToSessionMessage) ProtoMessage()    {}
 func (*AddValuesToSessionMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3a6be1b361fa6f14, []int{4}
+	return fileDescriptor_3a6be1b361fa6f14, []int{5}
 }
 
 func (m *AddValuesToSessionMessage) XXX_Unmarshal(b []byte) error {
@@ -256,7 +399,7 @@ func (m *SessionResponse) Reset()         { *m = SessionResponse{} }
 func (m *SessionResponse) String() string { return proto.CompactTextString(m) }
 func (*SessionResponse) ProtoMessage()    {}
 func (*SessionResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3a6be1b361fa6f14, []int{5}
+	return fileDescriptor_3a6be1b361fa6f14, []int{6}
 }
 
 func (m *SessionResponse) XXX_Unmarshal(b []byte) error {
@@ -291,6 +434,108 @@ func (m *SessionResponse) GetValues() map[string]*_struct.Value {
 	return nil
 }
 
+type SessionResult struct {
+	Id                   string           `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Session              *SessionResponse `protobuf:"bytes,2,opt,name=session,proto3" json:"session,omitempty"`
+	Code                 int32            `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	Error                string           `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *SessionResult) Reset()         { *m = SessionResult{} }
+func (m *SessionResult) String() string { return proto.CompactTextString(m) }
+func (*SessionResult) ProtoMessage()    {}
+func (*SessionResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{7}
+}
+
+func (m *SessionResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionResult.Unmarshal(m, b)
+}
+func (m *SessionResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionResult.Marshal(b, m, deterministic)
+}
+func (m *SessionResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionResult.Merge(m, src)
+}
+func (m *SessionResult) XXX_Size() int {
+	return xxx_messageInfo_SessionResult.Size(m)
+}
+func (m *SessionResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SessionResult proto.InternalMessageInfo
+
+func (m *SessionResult) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *SessionResult) GetSession() *SessionResponse {
+	if m != nil {
+		return m.Session
+	}
+	return nil
+}
+
+func (m *SessionResult) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *SessionResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type SessionsResponse struct {
+	Results              []*SessionResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *SessionsResponse) Reset()         { *m = SessionsResponse{} }
+func (m *SessionsResponse) String() string { return proto.CompactTextString(m) }
+func (*SessionsResponse) ProtoMessage()    {}
+func (*SessionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{8}
+}
+
+func (m *SessionsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionsResponse.Unmarshal(m, b)
+}
+func (m *SessionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionsResponse.Marshal(b, m, deterministic)
+}
+func (m *SessionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionsResponse.Merge(m, src)
+}
+func (m *SessionsResponse) XXX_Size() int {
+	return xxx_messageInfo_SessionsResponse.Size(m)
+}
+func (m *SessionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SessionsResponse proto.InternalMessageInfo
+
+func (m *SessionsResponse) GetResults() []*SessionResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
 type InvalidateSessionMessage struct {
 	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -302,7 +547,7 @@ func (m *InvalidateSessionMessage) Reset()         { *m = InvalidateSessionMessa
 func (m *InvalidateSessionMessage) String() string { return proto.CompactTextString(m) }
 func (*InvalidateSessionMessage) ProtoMessage()    {}
 func (*InvalidateSessionMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3a6be1b361fa6f14, []int{6}
+	return fileDescriptor_3a6be1b361fa6f14, []int{9}
 }
 
 func (m *InvalidateSessionMessage) XXX_Unmarshal(b []byte) error {
@@ -342,7 +587,7 @@ func (m *InvalidateSessionValueMessage) Reset()         { *m = InvalidateSession
 func (m *InvalidateSessionValueMessage) String() string { return proto.CompactTextString(m) }
 func (*InvalidateSessionValueMessage) ProtoMessage()    {}
 func (*InvalidateSessionValueMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3a6be1b361fa6f14, []int{7}
+	return fileDescriptor_3a6be1b361fa6f14, []int{10}
 }
 
 func (m *InvalidateSessionValueMessage) XXX_Unmarshal(b []byte) error {
@@ -389,7 +634,7 @@ func (m *InvalidateSessionValuesMessage) Reset()         { *m = InvalidateSessio
 func (m *InvalidateSessionValuesMessage) String() string { return proto.CompactTextString(m) }
 func (*InvalidateSessionValuesMessage) ProtoMessage()    {}
 func (*InvalidateSessionValuesMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_3a6be1b361fa6f14, []int{8}
+	return fileDescriptor_3a6be1b361fa6f14, []int{11}
 }
 
 func (m *InvalidateSessionValuesMessage) XXX_Unmarshal(b []byte) error {
@@ -424,348 +669,2432 @@ func (m *InvalidateSessionValuesMessage) GetKeys() []string {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*SuccessMessage)(nil), "hobord.session.SuccessMessage")
-	proto.RegisterType((*CreateSessionMessage)(nil), "hobord.session.CreateSessionMessage")
-	proto.RegisterType((*GetSessionMessage)(nil), "hobord.session.GetSessionMessage")
-	proto.RegisterType((*AddValueToSessionMessage)(nil), "hobord.session.AddValueToSessionMessage")
-	proto.RegisterType((*AddValuesToSessionMessage)(nil), "hobord.session.AddValuesToSessionMessage")
-	proto.RegisterMapType((map[string]*_struct.Value)(nil), "hobord.session.AddValuesToSessionMessage.ValuesEntry")
-	proto.RegisterType((*SessionResponse)(nil), "hobord.session.SessionResponse")
-	proto.RegisterMapType((map[string]*_struct.Value)(nil), "hobord.session.SessionResponse.ValuesEntry")
-	proto.RegisterType((*InvalidateSessionMessage)(nil), "hobord.session.InvalidateSessionMessage")
-	proto.RegisterType((*InvalidateSessionValueMessage)(nil), "hobord.session.InvalidateSessionValueMessage")
-	proto.RegisterType((*InvalidateSessionValuesMessage)(nil), "hobord.session.InvalidateSessionValuesMessage")
+type RenewSessionMessage struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ttl                  int64    `protobuf:"varint,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func init() { proto.RegisterFile("session.proto", fileDescriptor_3a6be1b361fa6f14) }
-
-var fileDescriptor_3a6be1b361fa6f14 = []byte{
-	// 474 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x54, 0x4f, 0x6f, 0xd3, 0x30,
-	0x1c, 0x5d, 0x12, 0x36, 0xe8, 0xaf, 0x5a, 0x47, 0x2d, 0x34, 0x42, 0x05, 0xa3, 0x18, 0x0e, 0xe1,
-	0x9f, 0x27, 0x15, 0x21, 0x21, 0x6e, 0x63, 0x43, 0x88, 0xc3, 0x0e, 0xb8, 0x08, 0x21, 0x2e, 0xd0,
-	0x24, 0x5e, 0x09, 0x8d, 0xe2, 0x29, 0x76, 0x2a, 0xf5, 0xa3, 0x71, 0xe1, 0x1b, 0xf0, 0x9d, 0x50,
-	0x9c, 0x04, 0x56, 0x3b, 0x69, 0x7d, 0xe2, 0x66, 0xfd, 0xf2, 0x7b, 0xef, 0xf5, 0xd9, 0xef, 0x15,
-	0xf6, 0x05, 0x13, 0x22, 0xe1, 0x19, 0xb9, 0xcc, 0xb9, 0xe4, 0x68, 0xf0, 0x9d, 0x87, 0x3c, 0x8f,
-	0x49, 0x3d, 0x1d, 0xdd, 0x9d, 0x73, 0x3e, 0x4f, 0xd9, 0xb1, 0xfa, 0x1a, 0x16, 0x17, 0xc7, 0x42,
-	0xe6, 0x45, 0x24, 0xab, 0x6d, 0x3c, 0x81, 0xc1, 0xb4, 0x88, 0x22, 0x26, 0xc4, 0x39, 0x13, 0x62,
-	0x36, 0x67, 0x68, 0x0c, 0xfd, 0x7a, 0x72, 0x51, 0xa4, 0xa9, 0xef, 0x8c, 0x9d, 0xe0, 0x06, 0xbd,
-	0x3a, 0xc2, 0x01, 0xdc, 0x3a, 0xcd, 0xd9, 0x4c, 0xb2, 0x69, 0x25, 0xd1, 0x20, 0x6f, 0x82, 0x27,
-	0x65, 0x85, 0xf0, 0x68, 0x79, 0xc4, 0x0f, 0x61, 0xf8, 0x8e, 0x49, 0x6d, 0x6d, 0x00, 0x6e, 0x12,
-	0xab, 0xad, 0x1e, 0x75, 0x93, 0x18, 0xff, 0x00, 0xff, 0x24, 0x8e, 0x3f, 0xcd, 0xd2, 0x82, 0x7d,
-	0xe4, 0x9b, 0x77, 0x4b, 0x89, 0x05, 0x5b, 0xf9, 0xae, 0x1a, 0x94, 0x47, 0xf4, 0x0c, 0x76, 0x97,
-	0x25, 0xd4, 0xf7, 0xc6, 0x4e, 0xd0, 0x9f, 0x1c, 0x92, 0xca, 0x2e, 0x69, 0xec, 0x12, 0x45, 0x4c,
-	0xab, 0x25, 0xfc, 0xdb, 0x81, 0x3b, 0x8d, 0x98, 0xd8, 0xaa, 0x76, 0x0e, 0x7b, 0x0a, 0x26, 0x7c,
-	0x6f, 0xec, 0x05, 0xfd, 0xc9, 0x4b, 0xb2, 0x7e, 0xb7, 0xa4, 0x93, 0xaa, 0x52, 0x15, 0x6f, 0x33,
-	0x99, 0xaf, 0x68, 0x4d, 0x32, 0xfa, 0x00, 0xfd, 0x2b, 0xe3, 0xc6, 0x8b, 0xd3, 0xe2, 0xc5, 0xb5,
-	0xf0, 0xf2, 0xda, 0x7d, 0xe5, 0xe0, 0x9f, 0x0e, 0x1c, 0xd4, 0xca, 0x94, 0x89, 0x4b, 0x9e, 0x09,
-	0xd3, 0xc5, 0xe9, 0x5f, 0x17, 0xae, 0x72, 0xf1, 0x54, 0x77, 0xa1, 0x11, 0xfc, 0xaf, 0xdf, 0xfe,
-	0x04, 0xfc, 0xf7, 0xd9, 0x72, 0x96, 0x26, 0xb1, 0x19, 0x25, 0x3d, 0x23, 0x27, 0x70, 0xcf, 0xd8,
-	0x55, 0x84, 0xd6, 0x41, 0xc1, 0x67, 0x70, 0xd4, 0x4e, 0x21, 0xba, 0x38, 0x10, 0x5c, 0x5b, 0xb0,
-	0x55, 0x75, 0x6d, 0x3d, 0xaa, 0xce, 0x93, 0x5f, 0xbb, 0x70, 0x70, 0x56, 0xa3, 0xa7, 0x2c, 0x5f,
-	0x26, 0x11, 0x43, 0x14, 0xe0, 0x5f, 0xca, 0xd1, 0x03, 0xfd, 0x7a, 0x8d, 0x06, 0x8c, 0xee, 0x6f,
-	0x79, 0x01, 0xbc, 0x83, 0x3e, 0xc3, 0xfe, 0x5a, 0xc7, 0xd0, 0x23, 0x1d, 0xd3, 0x56, 0x41, 0x1b,
-	0xe6, 0x6f, 0x30, 0x34, 0xea, 0x86, 0x82, 0xae, 0x64, 0xeb, 0xc1, 0xb6, 0x51, 0x08, 0x01, 0x99,
-	0xc5, 0x40, 0x8f, 0xad, 0xcb, 0x63, 0xa3, 0x91, 0xc0, 0x61, 0xfb, 0x6b, 0xa2, 0xe7, 0x3a, 0x78,
-	0x63, 0x70, 0x46, 0x47, 0x86, 0xd6, 0xda, 0xdf, 0x21, 0xde, 0x41, 0x0b, 0xb8, 0xdd, 0x11, 0x1c,
-	0x44, 0xec, 0xb4, 0x84, 0xbd, 0xd8, 0x57, 0x18, 0x1a, 0x1c, 0xe6, 0xeb, 0x74, 0xf5, 0x66, 0xbb,
-	0xc0, 0x9b, 0xde, 0x97, 0xeb, 0xf5, 0xb7, 0x70, 0x4f, 0x75, 0xf3, 0xc5, 0x9f, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0xc8, 0x9b, 0xf9, 0x25, 0x41, 0x06, 0x00, 0x00,
+func (m *RenewSessionMessage) Reset()         { *m = RenewSessionMessage{} }
+func (m *RenewSessionMessage) String() string { return proto.CompactTextString(m) }
+func (*RenewSessionMessage) ProtoMessage()    {}
+func (*RenewSessionMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{12}
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// DSessionServiceClient is the client API for DSessionService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type DSessionServiceClient interface {
-	GetSession(ctx context.Context, in *GetSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error)
-	CreateSession(ctx context.Context, in *CreateSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error)
-	AddValueToSession(ctx context.Context, in *AddValueToSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error)
-	AddValuesToSession(ctx context.Context, in *AddValuesToSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error)
-	InvalidateSessionValue(ctx context.Context, in *InvalidateSessionValueMessage, opts ...grpc.CallOption) (*SuccessMessage, error)
-	InvalidateSessionValues(ctx context.Context, in *InvalidateSessionValuesMessage, opts ...grpc.CallOption) (*SuccessMessage, error)
-	InvalidateSession(ctx context.Context, in *InvalidateSessionMessage, opts ...grpc.CallOption) (*SuccessMessage, error)
+func (m *RenewSessionMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RenewSessionMessage.Unmarshal(m, b)
 }
-
-type dSessionServiceClient struct {
-	cc *grpc.ClientConn
+func (m *RenewSessionMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RenewSessionMessage.Marshal(b, m, deterministic)
 }
-
-func NewDSessionServiceClient(cc *grpc.ClientConn) DSessionServiceClient {
-	return &dSessionServiceClient{cc}
+func (m *RenewSessionMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RenewSessionMessage.Merge(m, src)
 }
-
-func (c *dSessionServiceClient) GetSession(ctx context.Context, in *GetSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error) {
-	out := new(SessionResponse)
-	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/GetSession", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *RenewSessionMessage) XXX_Size() int {
+	return xxx_messageInfo_RenewSessionMessage.Size(m)
+}
+func (m *RenewSessionMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_RenewSessionMessage.DiscardUnknown(m)
 }
 
-func (c *dSessionServiceClient) CreateSession(ctx context.Context, in *CreateSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error) {
-	out := new(SessionResponse)
-	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/CreateSession", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_RenewSessionMessage proto.InternalMessageInfo
+
+func (m *RenewSessionMessage) GetId() string {
+	if m != nil {
+		return m.Id
 	}
-	return out, nil
+	return ""
 }
 
-func (c *dSessionServiceClient) AddValueToSession(ctx context.Context, in *AddValueToSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error) {
-	out := new(SessionResponse)
-	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/AddValueToSession", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *RenewSessionMessage) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
 	}
-	return out, nil
+	return 0
 }
 
-func (c *dSessionServiceClient) AddValuesToSession(ctx context.Context, in *AddValuesToSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error) {
-	out := new(SessionResponse)
-	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/AddValuesToSession", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type WatchSessionMessage struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (c *dSessionServiceClient) InvalidateSessionValue(ctx context.Context, in *InvalidateSessionValueMessage, opts ...grpc.CallOption) (*SuccessMessage, error) {
-	out := new(SuccessMessage)
-	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/InvalidateSessionValue", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *WatchSessionMessage) Reset()         { *m = WatchSessionMessage{} }
+func (m *WatchSessionMessage) String() string { return proto.CompactTextString(m) }
+func (*WatchSessionMessage) ProtoMessage()    {}
+func (*WatchSessionMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{13}
 }
 
-func (c *dSessionServiceClient) InvalidateSessionValues(ctx context.Context, in *InvalidateSessionValuesMessage, opts ...grpc.CallOption) (*SuccessMessage, error) {
-	out := new(SuccessMessage)
-	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/InvalidateSessionValues", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *WatchSessionMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchSessionMessage.Unmarshal(m, b)
+}
+func (m *WatchSessionMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchSessionMessage.Marshal(b, m, deterministic)
+}
+func (m *WatchSessionMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchSessionMessage.Merge(m, src)
+}
+func (m *WatchSessionMessage) XXX_Size() int {
+	return xxx_messageInfo_WatchSessionMessage.Size(m)
+}
+func (m *WatchSessionMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchSessionMessage.DiscardUnknown(m)
 }
 
-func (c *dSessionServiceClient) InvalidateSession(ctx context.Context, in *InvalidateSessionMessage, opts ...grpc.CallOption) (*SuccessMessage, error) {
-	out := new(SuccessMessage)
-	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/InvalidateSession", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_WatchSessionMessage proto.InternalMessageInfo
+
+func (m *WatchSessionMessage) GetId() string {
+	if m != nil {
+		return m.Id
 	}
-	return out, nil
+	return ""
 }
 
-// DSessionServiceServer is the server API for DSessionService service.
-type DSessionServiceServer interface {
-	GetSession(context.Context, *GetSessionMessage) (*SessionResponse, error)
-	CreateSession(context.Context, *CreateSessionMessage) (*SessionResponse, error)
-	AddValueToSession(context.Context, *AddValueToSessionMessage) (*SessionResponse, error)
-	AddValuesToSession(context.Context, *AddValuesToSessionMessage) (*SessionResponse, error)
-	InvalidateSessionValue(context.Context, *InvalidateSessionValueMessage) (*SuccessMessage, error)
-	InvalidateSessionValues(context.Context, *InvalidateSessionValuesMessage) (*SuccessMessage, error)
-	InvalidateSession(context.Context, *InvalidateSessionMessage) (*SuccessMessage, error)
+type SessionEvent struct {
+	Type                 SessionEventType          `protobuf:"varint,1,opt,name=type,proto3,enum=hobord.session.SessionEventType" json:"type,omitempty"`
+	Id                   string                    `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Keys                 []string                  `protobuf:"bytes,3,rep,name=keys,proto3" json:"keys,omitempty"`
+	Values               map[string]*_struct.Value `protobuf:"bytes,4,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Ttl                  int64                     `protobuf:"varint,5,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Time                 int64                     `protobuf:"varint,6,opt,name=time,proto3" json:"time,omitempty"`
+	Namespace            string                    `protobuf:"bytes,7,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
 }
 
-// UnimplementedDSessionServiceServer can be embedded to have forward compatible implementations.
-type UnimplementedDSessionServiceServer struct {
+func (m *SessionEvent) Reset()         { *m = SessionEvent{} }
+func (m *SessionEvent) String() string { return proto.CompactTextString(m) }
+func (*SessionEvent) ProtoMessage()    {}
+func (*SessionEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{14}
 }
 
-func (*UnimplementedDSessionServiceServer) GetSession(ctx context.Context, req *GetSessionMessage) (*SessionResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetSession not implemented")
+func (m *SessionEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionEvent.Unmarshal(m, b)
 }
-func (*UnimplementedDSessionServiceServer) CreateSession(ctx context.Context, req *CreateSessionMessage) (*SessionResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateSession not implemented")
+func (m *SessionEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionEvent.Marshal(b, m, deterministic)
 }
-func (*UnimplementedDSessionServiceServer) AddValueToSession(ctx context.Context, req *AddValueToSessionMessage) (*SessionResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method AddValueToSession not implemented")
+func (m *SessionEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionEvent.Merge(m, src)
 }
-func (*UnimplementedDSessionServiceServer) AddValuesToSession(ctx context.Context, req *AddValuesToSessionMessage) (*SessionResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method AddValuesToSession not implemented")
+func (m *SessionEvent) XXX_Size() int {
+	return xxx_messageInfo_SessionEvent.Size(m)
 }
-func (*UnimplementedDSessionServiceServer) InvalidateSessionValue(ctx context.Context, req *InvalidateSessionValueMessage) (*SuccessMessage, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method InvalidateSessionValue not implemented")
+func (m *SessionEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionEvent.DiscardUnknown(m)
 }
-func (*UnimplementedDSessionServiceServer) InvalidateSessionValues(ctx context.Context, req *InvalidateSessionValuesMessage) (*SuccessMessage, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method InvalidateSessionValues not implemented")
+
+var xxx_messageInfo_SessionEvent proto.InternalMessageInfo
+
+func (m *SessionEvent) GetType() SessionEventType {
+	if m != nil {
+		return m.Type
+	}
+	return SessionEventType_UNKNOWN_EVENT
+}
+
+func (m *SessionEvent) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *SessionEvent) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *SessionEvent) GetValues() map[string]*_struct.Value {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *SessionEvent) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+func (m *SessionEvent) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *SessionEvent) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+type SubscribeEventsMessage struct {
+	Group                string             `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Consumer             string             `protobuf:"bytes,2,opt,name=consumer,proto3" json:"consumer,omitempty"`
+	StartId              string             `protobuf:"bytes,3,opt,name=start_id,json=startId,proto3" json:"start_id,omitempty"`
+	Types                []SessionEventType `protobuf:"varint,4,rep,packed,name=types,proto3,enum=hobord.session.SessionEventType" json:"types,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *SubscribeEventsMessage) Reset()         { *m = SubscribeEventsMessage{} }
+func (m *SubscribeEventsMessage) String() string { return proto.CompactTextString(m) }
+func (*SubscribeEventsMessage) ProtoMessage()    {}
+func (*SubscribeEventsMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{15}
+}
+
+func (m *SubscribeEventsMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribeEventsMessage.Unmarshal(m, b)
+}
+func (m *SubscribeEventsMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribeEventsMessage.Marshal(b, m, deterministic)
+}
+func (m *SubscribeEventsMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeEventsMessage.Merge(m, src)
+}
+func (m *SubscribeEventsMessage) XXX_Size() int {
+	return xxx_messageInfo_SubscribeEventsMessage.Size(m)
+}
+func (m *SubscribeEventsMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeEventsMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribeEventsMessage proto.InternalMessageInfo
+
+func (m *SubscribeEventsMessage) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *SubscribeEventsMessage) GetConsumer() string {
+	if m != nil {
+		return m.Consumer
+	}
+	return ""
+}
+
+func (m *SubscribeEventsMessage) GetStartId() string {
+	if m != nil {
+		return m.StartId
+	}
+	return ""
+}
+
+func (m *SubscribeEventsMessage) GetTypes() []SessionEventType {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
+type EventRecord struct {
+	StreamId             string        `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	Event                *SessionEvent `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *EventRecord) Reset()         { *m = EventRecord{} }
+func (m *EventRecord) String() string { return proto.CompactTextString(m) }
+func (*EventRecord) ProtoMessage()    {}
+func (*EventRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{16}
+}
+
+func (m *EventRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EventRecord.Unmarshal(m, b)
+}
+func (m *EventRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EventRecord.Marshal(b, m, deterministic)
+}
+func (m *EventRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EventRecord.Merge(m, src)
+}
+func (m *EventRecord) XXX_Size() int {
+	return xxx_messageInfo_EventRecord.Size(m)
+}
+func (m *EventRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_EventRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EventRecord proto.InternalMessageInfo
+
+func (m *EventRecord) GetStreamId() string {
+	if m != nil {
+		return m.StreamId
+	}
+	return ""
+}
+
+func (m *EventRecord) GetEvent() *SessionEvent {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+type AckEventsMessage struct {
+	Group                string   `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	StreamIds            []string `protobuf:"bytes,2,rep,name=stream_ids,json=streamIds,proto3" json:"stream_ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AckEventsMessage) Reset()         { *m = AckEventsMessage{} }
+func (m *AckEventsMessage) String() string { return proto.CompactTextString(m) }
+func (*AckEventsMessage) ProtoMessage()    {}
+func (*AckEventsMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{17}
+}
+
+func (m *AckEventsMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AckEventsMessage.Unmarshal(m, b)
+}
+func (m *AckEventsMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AckEventsMessage.Marshal(b, m, deterministic)
+}
+func (m *AckEventsMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AckEventsMessage.Merge(m, src)
+}
+func (m *AckEventsMessage) XXX_Size() int {
+	return xxx_messageInfo_AckEventsMessage.Size(m)
+}
+func (m *AckEventsMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_AckEventsMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AckEventsMessage proto.InternalMessageInfo
+
+func (m *AckEventsMessage) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *AckEventsMessage) GetStreamIds() []string {
+	if m != nil {
+		return m.StreamIds
+	}
+	return nil
+}
+
+type SessionFilter struct {
+	Namespace            string                    `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	HasKeys              []string                  `protobuf:"bytes,2,rep,name=has_keys,json=hasKeys,proto3" json:"has_keys,omitempty"`
+	ValueEquals          map[string]*_struct.Value `protobuf:"bytes,3,rep,name=value_equals,json=valueEquals,proto3" json:"value_equals,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	CreatedBefore        int64                     `protobuf:"varint,4,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *SessionFilter) Reset()         { *m = SessionFilter{} }
+func (m *SessionFilter) String() string { return proto.CompactTextString(m) }
+func (*SessionFilter) ProtoMessage()    {}
+func (*SessionFilter) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{18}
+}
+
+func (m *SessionFilter) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionFilter.Unmarshal(m, b)
+}
+func (m *SessionFilter) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionFilter.Marshal(b, m, deterministic)
+}
+func (m *SessionFilter) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionFilter.Merge(m, src)
+}
+func (m *SessionFilter) XXX_Size() int {
+	return xxx_messageInfo_SessionFilter.Size(m)
+}
+func (m *SessionFilter) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionFilter.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SessionFilter proto.InternalMessageInfo
+
+func (m *SessionFilter) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *SessionFilter) GetHasKeys() []string {
+	if m != nil {
+		return m.HasKeys
+	}
+	return nil
+}
+
+func (m *SessionFilter) GetValueEquals() map[string]*_struct.Value {
+	if m != nil {
+		return m.ValueEquals
+	}
+	return nil
+}
+
+func (m *SessionFilter) GetCreatedBefore() int64 {
+	if m != nil {
+		return m.CreatedBefore
+	}
+	return 0
+}
+
+type BulkInvalidationMessage struct {
+	Filter               *SessionFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	DryRun               bool           `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *BulkInvalidationMessage) Reset()         { *m = BulkInvalidationMessage{} }
+func (m *BulkInvalidationMessage) String() string { return proto.CompactTextString(m) }
+func (*BulkInvalidationMessage) ProtoMessage()    {}
+func (*BulkInvalidationMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{19}
+}
+
+func (m *BulkInvalidationMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BulkInvalidationMessage.Unmarshal(m, b)
+}
+func (m *BulkInvalidationMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BulkInvalidationMessage.Marshal(b, m, deterministic)
+}
+func (m *BulkInvalidationMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkInvalidationMessage.Merge(m, src)
+}
+func (m *BulkInvalidationMessage) XXX_Size() int {
+	return xxx_messageInfo_BulkInvalidationMessage.Size(m)
+}
+func (m *BulkInvalidationMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkInvalidationMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BulkInvalidationMessage proto.InternalMessageInfo
+
+func (m *BulkInvalidationMessage) GetFilter() *SessionFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func (m *BulkInvalidationMessage) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type GetJobMessage struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetJobMessage) Reset()         { *m = GetJobMessage{} }
+func (m *GetJobMessage) String() string { return proto.CompactTextString(m) }
+func (*GetJobMessage) ProtoMessage()    {}
+func (*GetJobMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{20}
+}
+
+func (m *GetJobMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetJobMessage.Unmarshal(m, b)
+}
+func (m *GetJobMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetJobMessage.Marshal(b, m, deterministic)
+}
+func (m *GetJobMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetJobMessage.Merge(m, src)
+}
+func (m *GetJobMessage) XXX_Size() int {
+	return xxx_messageInfo_GetJobMessage.Size(m)
+}
+func (m *GetJobMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetJobMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetJobMessage proto.InternalMessageInfo
+
+func (m *GetJobMessage) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type JobResponse struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind                 string   `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	State                JobState `protobuf:"varint,3,opt,name=state,proto3,enum=hobord.session.JobState" json:"state,omitempty"`
+	DryRun               bool     `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Scanned              int64    `protobuf:"varint,5,opt,name=scanned,proto3" json:"scanned,omitempty"`
+	Matched              int64    `protobuf:"varint,6,opt,name=matched,proto3" json:"matched,omitempty"`
+	Deleted              int64    `protobuf:"varint,7,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Error                string   `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	StartedAt            int64    `protobuf:"varint,9,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt           int64    `protobuf:"varint,10,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Updated              int64    `protobuf:"varint,11,opt,name=updated,proto3" json:"updated,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JobResponse) Reset()         { *m = JobResponse{} }
+func (m *JobResponse) String() string { return proto.CompactTextString(m) }
+func (*JobResponse) ProtoMessage()    {}
+func (*JobResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{21}
+}
+
+func (m *JobResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JobResponse.Unmarshal(m, b)
+}
+func (m *JobResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JobResponse.Marshal(b, m, deterministic)
+}
+func (m *JobResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JobResponse.Merge(m, src)
+}
+func (m *JobResponse) XXX_Size() int {
+	return xxx_messageInfo_JobResponse.Size(m)
+}
+func (m *JobResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_JobResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JobResponse proto.InternalMessageInfo
+
+func (m *JobResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *JobResponse) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *JobResponse) GetState() JobState {
+	if m != nil {
+		return m.State
+	}
+	return JobState_JOB_RUNNING
+}
+
+func (m *JobResponse) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+func (m *JobResponse) GetScanned() int64 {
+	if m != nil {
+		return m.Scanned
+	}
+	return 0
+}
+
+func (m *JobResponse) GetMatched() int64 {
+	if m != nil {
+		return m.Matched
+	}
+	return 0
+}
+
+func (m *JobResponse) GetDeleted() int64 {
+	if m != nil {
+		return m.Deleted
+	}
+	return 0
+}
+
+func (m *JobResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *JobResponse) GetStartedAt() int64 {
+	if m != nil {
+		return m.StartedAt
+	}
+	return 0
+}
+
+func (m *JobResponse) GetFinishedAt() int64 {
+	if m != nil {
+		return m.FinishedAt
+	}
+	return 0
+}
+
+func (m *JobResponse) GetUpdated() int64 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
+type ListSessionsMessage struct {
+	Filter               *SessionFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Cursor               string         `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                int32          `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ListSessionsMessage) Reset()         { *m = ListSessionsMessage{} }
+func (m *ListSessionsMessage) String() string { return proto.CompactTextString(m) }
+func (*ListSessionsMessage) ProtoMessage()    {}
+func (*ListSessionsMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{22}
+}
+
+func (m *ListSessionsMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSessionsMessage.Unmarshal(m, b)
+}
+func (m *ListSessionsMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSessionsMessage.Marshal(b, m, deterministic)
+}
+func (m *ListSessionsMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSessionsMessage.Merge(m, src)
+}
+func (m *ListSessionsMessage) XXX_Size() int {
+	return xxx_messageInfo_ListSessionsMessage.Size(m)
+}
+func (m *ListSessionsMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSessionsMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSessionsMessage proto.InternalMessageInfo
+
+func (m *ListSessionsMessage) GetFilter() *SessionFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func (m *ListSessionsMessage) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *ListSessionsMessage) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type SessionSummary struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Namespace            string   `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	CreatedAt            int64    `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Ttl                  int64    `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	RemainingTtl         int64    `protobuf:"varint,5,opt,name=remaining_ttl,json=remainingTtl,proto3" json:"remaining_ttl,omitempty"`
+	Keys                 []string `protobuf:"bytes,6,rep,name=keys,proto3" json:"keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SessionSummary) Reset()         { *m = SessionSummary{} }
+func (m *SessionSummary) String() string { return proto.CompactTextString(m) }
+func (*SessionSummary) ProtoMessage()    {}
+func (*SessionSummary) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{23}
+}
+
+func (m *SessionSummary) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SessionSummary.Unmarshal(m, b)
+}
+func (m *SessionSummary) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SessionSummary.Marshal(b, m, deterministic)
+}
+func (m *SessionSummary) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SessionSummary.Merge(m, src)
+}
+func (m *SessionSummary) XXX_Size() int {
+	return xxx_messageInfo_SessionSummary.Size(m)
+}
+func (m *SessionSummary) XXX_DiscardUnknown() {
+	xxx_messageInfo_SessionSummary.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SessionSummary proto.InternalMessageInfo
+
+func (m *SessionSummary) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *SessionSummary) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *SessionSummary) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+func (m *SessionSummary) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+func (m *SessionSummary) GetRemainingTtl() int64 {
+	if m != nil {
+		return m.RemainingTtl
+	}
+	return 0
+}
+
+func (m *SessionSummary) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+type ListSessionsResponse struct {
+	Sessions             []*SessionSummary `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	Cursor               string            `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ListSessionsResponse) Reset()         { *m = ListSessionsResponse{} }
+func (m *ListSessionsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListSessionsResponse) ProtoMessage()    {}
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{24}
+}
+
+func (m *ListSessionsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSessionsResponse.Unmarshal(m, b)
+}
+func (m *ListSessionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSessionsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListSessionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSessionsResponse.Merge(m, src)
+}
+func (m *ListSessionsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListSessionsResponse.Size(m)
+}
+func (m *ListSessionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSessionsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSessionsResponse proto.InternalMessageInfo
+
+func (m *ListSessionsResponse) GetSessions() []*SessionSummary {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+func (m *ListSessionsResponse) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+type ExportSessionsMessage struct {
+	Filter               *SessionFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ExportSessionsMessage) Reset()         { *m = ExportSessionsMessage{} }
+func (m *ExportSessionsMessage) String() string { return proto.CompactTextString(m) }
+func (*ExportSessionsMessage) ProtoMessage()    {}
+func (*ExportSessionsMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{25}
+}
+
+func (m *ExportSessionsMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportSessionsMessage.Unmarshal(m, b)
+}
+func (m *ExportSessionsMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportSessionsMessage.Marshal(b, m, deterministic)
+}
+func (m *ExportSessionsMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportSessionsMessage.Merge(m, src)
+}
+func (m *ExportSessionsMessage) XXX_Size() int {
+	return xxx_messageInfo_ExportSessionsMessage.Size(m)
+}
+func (m *ExportSessionsMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportSessionsMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportSessionsMessage proto.InternalMessageInfo
+
+func (m *ExportSessionsMessage) GetFilter() *SessionFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+type ExportedSession struct {
+	Id                   string                    `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Namespace            string                    `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	CreatedAt            int64                     `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Ttl                  int64                     `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	RemainingTtl         int64                     `protobuf:"varint,5,opt,name=remaining_ttl,json=remainingTtl,proto3" json:"remaining_ttl,omitempty"`
+	Values               map[string]*_struct.Value `protobuf:"bytes,6,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *ExportedSession) Reset()         { *m = ExportedSession{} }
+func (m *ExportedSession) String() string { return proto.CompactTextString(m) }
+func (*ExportedSession) ProtoMessage()    {}
+func (*ExportedSession) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{26}
+}
+
+func (m *ExportedSession) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExportedSession.Unmarshal(m, b)
+}
+func (m *ExportedSession) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExportedSession.Marshal(b, m, deterministic)
+}
+func (m *ExportedSession) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExportedSession.Merge(m, src)
+}
+func (m *ExportedSession) XXX_Size() int {
+	return xxx_messageInfo_ExportedSession.Size(m)
+}
+func (m *ExportedSession) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExportedSession.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExportedSession proto.InternalMessageInfo
+
+func (m *ExportedSession) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ExportedSession) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *ExportedSession) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+func (m *ExportedSession) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+func (m *ExportedSession) GetRemainingTtl() int64 {
+	if m != nil {
+		return m.RemainingTtl
+	}
+	return 0
+}
+
+func (m *ExportedSession) GetValues() map[string]*_struct.Value {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type ImportSessionMessage struct {
+	Session              *ExportedSession `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	Policy               ConflictPolicy   `protobuf:"varint,2,opt,name=policy,proto3,enum=hobord.session.ConflictPolicy" json:"policy,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ImportSessionMessage) Reset()         { *m = ImportSessionMessage{} }
+func (m *ImportSessionMessage) String() string { return proto.CompactTextString(m) }
+func (*ImportSessionMessage) ProtoMessage()    {}
+func (*ImportSessionMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{27}
+}
+
+func (m *ImportSessionMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportSessionMessage.Unmarshal(m, b)
+}
+func (m *ImportSessionMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportSessionMessage.Marshal(b, m, deterministic)
+}
+func (m *ImportSessionMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportSessionMessage.Merge(m, src)
+}
+func (m *ImportSessionMessage) XXX_Size() int {
+	return xxx_messageInfo_ImportSessionMessage.Size(m)
+}
+func (m *ImportSessionMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportSessionMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportSessionMessage proto.InternalMessageInfo
+
+func (m *ImportSessionMessage) GetSession() *ExportedSession {
+	if m != nil {
+		return m.Session
+	}
+	return nil
+}
+
+func (m *ImportSessionMessage) GetPolicy() ConflictPolicy {
+	if m != nil {
+		return m.Policy
+	}
+	return ConflictPolicy_CONFLICT_SKIP
+}
+
+type ImportError struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImportError) Reset()         { *m = ImportError{} }
+func (m *ImportError) String() string { return proto.CompactTextString(m) }
+func (*ImportError) ProtoMessage()    {}
+func (*ImportError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{28}
+}
+
+func (m *ImportError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportError.Unmarshal(m, b)
+}
+func (m *ImportError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportError.Marshal(b, m, deterministic)
+}
+func (m *ImportError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportError.Merge(m, src)
+}
+func (m *ImportError) XXX_Size() int {
+	return xxx_messageInfo_ImportError.Size(m)
+}
+func (m *ImportError) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportError proto.InternalMessageInfo
+
+func (m *ImportError) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *ImportError) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type ImportSessionsResponse struct {
+	Received             int64          `protobuf:"varint,1,opt,name=received,proto3" json:"received,omitempty"`
+	Created              int64          `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Overwritten          int64          `protobuf:"varint,3,opt,name=overwritten,proto3" json:"overwritten,omitempty"`
+	Merged               int64          `protobuf:"varint,4,opt,name=merged,proto3" json:"merged,omitempty"`
+	Skipped              int64          `protobuf:"varint,5,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Failed               int64          `protobuf:"varint,6,opt,name=failed,proto3" json:"failed,omitempty"`
+	Errors               []*ImportError `protobuf:"bytes,7,rep,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ImportSessionsResponse) Reset()         { *m = ImportSessionsResponse{} }
+func (m *ImportSessionsResponse) String() string { return proto.CompactTextString(m) }
+func (*ImportSessionsResponse) ProtoMessage()    {}
+func (*ImportSessionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{29}
+}
+
+func (m *ImportSessionsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImportSessionsResponse.Unmarshal(m, b)
+}
+func (m *ImportSessionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImportSessionsResponse.Marshal(b, m, deterministic)
+}
+func (m *ImportSessionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImportSessionsResponse.Merge(m, src)
+}
+func (m *ImportSessionsResponse) XXX_Size() int {
+	return xxx_messageInfo_ImportSessionsResponse.Size(m)
+}
+func (m *ImportSessionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImportSessionsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImportSessionsResponse proto.InternalMessageInfo
+
+func (m *ImportSessionsResponse) GetReceived() int64 {
+	if m != nil {
+		return m.Received
+	}
+	return 0
+}
+
+func (m *ImportSessionsResponse) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *ImportSessionsResponse) GetOverwritten() int64 {
+	if m != nil {
+		return m.Overwritten
+	}
+	return 0
+}
+
+func (m *ImportSessionsResponse) GetMerged() int64 {
+	if m != nil {
+		return m.Merged
+	}
+	return 0
+}
+
+func (m *ImportSessionsResponse) GetSkipped() int64 {
+	if m != nil {
+		return m.Skipped
+	}
+	return 0
+}
+
+func (m *ImportSessionsResponse) GetFailed() int64 {
+	if m != nil {
+		return m.Failed
+	}
+	return 0
+}
+
+func (m *ImportSessionsResponse) GetErrors() []*ImportError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
+type ReencryptionMessage struct {
+	DryRun               bool     `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReencryptionMessage) Reset()         { *m = ReencryptionMessage{} }
+func (m *ReencryptionMessage) String() string { return proto.CompactTextString(m) }
+func (*ReencryptionMessage) ProtoMessage()    {}
+func (*ReencryptionMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{30}
+}
+
+func (m *ReencryptionMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReencryptionMessage.Unmarshal(m, b)
+}
+func (m *ReencryptionMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReencryptionMessage.Marshal(b, m, deterministic)
+}
+func (m *ReencryptionMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReencryptionMessage.Merge(m, src)
+}
+func (m *ReencryptionMessage) XXX_Size() int {
+	return xxx_messageInfo_ReencryptionMessage.Size(m)
+}
+func (m *ReencryptionMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReencryptionMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReencryptionMessage proto.InternalMessageInfo
+
+func (m *ReencryptionMessage) GetDryRun() bool {
+	if m != nil {
+		return m.DryRun
+	}
+	return false
+}
+
+type AuditRecord struct {
+	Id                   string                    `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Time                 int64                     `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	Client               string                    `protobuf:"bytes,3,opt,name=client,proto3" json:"client,omitempty"`
+	Subject              string                    `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	Via                  string                    `protobuf:"bytes,5,opt,name=via,proto3" json:"via,omitempty"`
+	Peer                 string                    `protobuf:"bytes,6,opt,name=peer,proto3" json:"peer,omitempty"`
+	Method               string                    `protobuf:"bytes,7,opt,name=method,proto3" json:"method,omitempty"`
+	SessionId            string                    `protobuf:"bytes,8,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Keys                 []string                  `protobuf:"bytes,9,rep,name=keys,proto3" json:"keys,omitempty"`
+	Values               map[string]*_struct.Value `protobuf:"bytes,10,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Code                 int32                     `protobuf:"varint,11,opt,name=code,proto3" json:"code,omitempty"`
+	Error                string                    `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *AuditRecord) Reset()         { *m = AuditRecord{} }
+func (m *AuditRecord) String() string { return proto.CompactTextString(m) }
+func (*AuditRecord) ProtoMessage()    {}
+func (*AuditRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{31}
+}
+
+func (m *AuditRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditRecord.Unmarshal(m, b)
+}
+func (m *AuditRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditRecord.Marshal(b, m, deterministic)
+}
+func (m *AuditRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditRecord.Merge(m, src)
+}
+func (m *AuditRecord) XXX_Size() int {
+	return xxx_messageInfo_AuditRecord.Size(m)
+}
+func (m *AuditRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditRecord proto.InternalMessageInfo
+
+func (m *AuditRecord) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *AuditRecord) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetVia() string {
+	if m != nil {
+		return m.Via
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetSessionId() string {
+	if m != nil {
+		return m.SessionId
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *AuditRecord) GetValues() map[string]*_struct.Value {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *AuditRecord) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+func (m *AuditRecord) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type AuditQueryMessage struct {
+	SessionId            string   `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Client               string   `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
+	Since                int64    `protobuf:"varint,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until                int64    `protobuf:"varint,4,opt,name=until,proto3" json:"until,omitempty"`
+	Cursor               string   `protobuf:"bytes,5,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit                int32    `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditQueryMessage) Reset()         { *m = AuditQueryMessage{} }
+func (m *AuditQueryMessage) String() string { return proto.CompactTextString(m) }
+func (*AuditQueryMessage) ProtoMessage()    {}
+func (*AuditQueryMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{32}
+}
+
+func (m *AuditQueryMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditQueryMessage.Unmarshal(m, b)
+}
+func (m *AuditQueryMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditQueryMessage.Marshal(b, m, deterministic)
+}
+func (m *AuditQueryMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditQueryMessage.Merge(m, src)
+}
+func (m *AuditQueryMessage) XXX_Size() int {
+	return xxx_messageInfo_AuditQueryMessage.Size(m)
+}
+func (m *AuditQueryMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditQueryMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditQueryMessage proto.InternalMessageInfo
+
+func (m *AuditQueryMessage) GetSessionId() string {
+	if m != nil {
+		return m.SessionId
+	}
+	return ""
+}
+
+func (m *AuditQueryMessage) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *AuditQueryMessage) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *AuditQueryMessage) GetUntil() int64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
+func (m *AuditQueryMessage) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *AuditQueryMessage) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type AuditLogResponse struct {
+	Records              []*AuditRecord `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	Cursor               string         `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *AuditLogResponse) Reset()         { *m = AuditLogResponse{} }
+func (m *AuditLogResponse) String() string { return proto.CompactTextString(m) }
+func (*AuditLogResponse) ProtoMessage()    {}
+func (*AuditLogResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_3a6be1b361fa6f14, []int{33}
+}
+
+func (m *AuditLogResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditLogResponse.Unmarshal(m, b)
+}
+func (m *AuditLogResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditLogResponse.Marshal(b, m, deterministic)
+}
+func (m *AuditLogResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditLogResponse.Merge(m, src)
+}
+func (m *AuditLogResponse) XXX_Size() int {
+	return xxx_messageInfo_AuditLogResponse.Size(m)
+}
+func (m *AuditLogResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditLogResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditLogResponse proto.InternalMessageInfo
+
+func (m *AuditLogResponse) GetRecords() []*AuditRecord {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+func (m *AuditLogResponse) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterEnum("hobord.session.SessionEventType", SessionEventType_name, SessionEventType_value)
+	proto.RegisterEnum("hobord.session.JobState", JobState_name, JobState_value)
+	proto.RegisterEnum("hobord.session.ConflictPolicy", ConflictPolicy_name, ConflictPolicy_value)
+	proto.RegisterType((*SuccessMessage)(nil), "hobord.session.SuccessMessage")
+	proto.RegisterType((*CreateSessionMessage)(nil), "hobord.session.CreateSessionMessage")
+	proto.RegisterType((*GetSessionMessage)(nil), "hobord.session.GetSessionMessage")
+	proto.RegisterType((*GetSessionsMessage)(nil), "hobord.session.GetSessionsMessage")
+	proto.RegisterType((*AddValueToSessionMessage)(nil), "hobord.session.AddValueToSessionMessage")
+	proto.RegisterType((*AddValuesToSessionMessage)(nil), "hobord.session.AddValuesToSessionMessage")
+	proto.RegisterMapType((map[string]*_struct.Value)(nil), "hobord.session.AddValuesToSessionMessage.ValuesEntry")
+	proto.RegisterType((*SessionResponse)(nil), "hobord.session.SessionResponse")
+	proto.RegisterMapType((map[string]*_struct.Value)(nil), "hobord.session.SessionResponse.ValuesEntry")
+	proto.RegisterType((*SessionResult)(nil), "hobord.session.SessionResult")
+	proto.RegisterType((*SessionsResponse)(nil), "hobord.session.SessionsResponse")
+	proto.RegisterType((*InvalidateSessionMessage)(nil), "hobord.session.InvalidateSessionMessage")
+	proto.RegisterType((*InvalidateSessionValueMessage)(nil), "hobord.session.InvalidateSessionValueMessage")
+	proto.RegisterType((*InvalidateSessionValuesMessage)(nil), "hobord.session.InvalidateSessionValuesMessage")
+	proto.RegisterType((*RenewSessionMessage)(nil), "hobord.session.RenewSessionMessage")
+	proto.RegisterType((*WatchSessionMessage)(nil), "hobord.session.WatchSessionMessage")
+	proto.RegisterType((*SessionEvent)(nil), "hobord.session.SessionEvent")
+	proto.RegisterMapType((map[string]*_struct.Value)(nil), "hobord.session.SessionEvent.ValuesEntry")
+	proto.RegisterType((*SubscribeEventsMessage)(nil), "hobord.session.SubscribeEventsMessage")
+	proto.RegisterType((*EventRecord)(nil), "hobord.session.EventRecord")
+	proto.RegisterType((*AckEventsMessage)(nil), "hobord.session.AckEventsMessage")
+	proto.RegisterType((*SessionFilter)(nil), "hobord.session.SessionFilter")
+	proto.RegisterMapType((map[string]*_struct.Value)(nil), "hobord.session.SessionFilter.ValueEqualsEntry")
+	proto.RegisterType((*BulkInvalidationMessage)(nil), "hobord.session.BulkInvalidationMessage")
+	proto.RegisterType((*GetJobMessage)(nil), "hobord.session.GetJobMessage")
+	proto.RegisterType((*JobResponse)(nil), "hobord.session.JobResponse")
+	proto.RegisterType((*ListSessionsMessage)(nil), "hobord.session.ListSessionsMessage")
+	proto.RegisterType((*SessionSummary)(nil), "hobord.session.SessionSummary")
+	proto.RegisterType((*ListSessionsResponse)(nil), "hobord.session.ListSessionsResponse")
+	proto.RegisterType((*ExportSessionsMessage)(nil), "hobord.session.ExportSessionsMessage")
+	proto.RegisterType((*ExportedSession)(nil), "hobord.session.ExportedSession")
+	proto.RegisterMapType((map[string]*_struct.Value)(nil), "hobord.session.ExportedSession.ValuesEntry")
+	proto.RegisterType((*ImportSessionMessage)(nil), "hobord.session.ImportSessionMessage")
+	proto.RegisterType((*ImportError)(nil), "hobord.session.ImportError")
+	proto.RegisterType((*ImportSessionsResponse)(nil), "hobord.session.ImportSessionsResponse")
+	proto.RegisterType((*ReencryptionMessage)(nil), "hobord.session.ReencryptionMessage")
+	proto.RegisterType((*AuditRecord)(nil), "hobord.session.AuditRecord")
+	proto.RegisterMapType((map[string]*_struct.Value)(nil), "hobord.session.AuditRecord.ValuesEntry")
+	proto.RegisterType((*AuditQueryMessage)(nil), "hobord.session.AuditQueryMessage")
+	proto.RegisterType((*AuditLogResponse)(nil), "hobord.session.AuditLogResponse")
+}
+
+func init() { proto.RegisterFile("session.proto", fileDescriptor_3a6be1b361fa6f14) }
+
+var fileDescriptor_3a6be1b361fa6f14 = []byte{
+	// 2028 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x38, 0xcd, 0x73, 0x1b, 0x49,
+	0xf5, 0x1e, 0xc9, 0x92, 0xa5, 0x27, 0x5b, 0x96, 0xdb, 0x5e, 0x47, 0xab, 0x24, 0x1b, 0xff, 0x26,
+	0x9b, 0xfc, 0x4c, 0x00, 0x2d, 0xe5, 0x90, 0x5d, 0x76, 0x2f, 0xa0, 0x58, 0x63, 0xa3, 0xc4, 0x91,
+	0x93, 0x96, 0x62, 0x6f, 0x2d, 0x14, 0x62, 0x34, 0xd3, 0xb6, 0x27, 0x96, 0x66, 0xc4, 0xf4, 0x8c,
+	0x17, 0x71, 0xe6, 0xc2, 0x95, 0x23, 0x55, 0x1c, 0xa8, 0xe2, 0xc0, 0x85, 0x3b, 0x54, 0x71, 0xa1,
+	0x8a, 0x2b, 0x7f, 0x0b, 0xff, 0x02, 0xd5, 0x1f, 0xf3, 0xa1, 0x99, 0x91, 0x2c, 0xa8, 0xdd, 0x2d,
+	0x6e, 0xf3, 0xde, 0xbc, 0x7e, 0xaf, 0xdf, 0x47, 0xbf, 0x2f, 0xd8, 0xa0, 0x84, 0x52, 0xcb, 0xb1,
+	0x9b, 0x13, 0xd7, 0xf1, 0x1c, 0x54, 0xbd, 0x72, 0x86, 0x8e, 0x6b, 0x36, 0x25, 0xb6, 0x71, 0xef,
+	0xd2, 0x71, 0x2e, 0x47, 0xe4, 0x23, 0xfe, 0x77, 0xe8, 0x5f, 0x7c, 0x44, 0x3d, 0xd7, 0x37, 0x3c,
+	0x41, 0xad, 0x1e, 0x40, 0xb5, 0xe7, 0x1b, 0x06, 0xa1, 0xf4, 0x15, 0xa1, 0x54, 0xbf, 0x24, 0x68,
+	0x0f, 0x2a, 0x12, 0x73, 0xe1, 0x8f, 0x46, 0x75, 0x65, 0x4f, 0xd9, 0x2f, 0xe1, 0x38, 0x4a, 0x3d,
+	0x82, 0x9d, 0x43, 0x97, 0xe8, 0x1e, 0xe9, 0x09, 0x11, 0xc1, 0xc9, 0x1a, 0xe4, 0x3d, 0x4f, 0x9c,
+	0xc8, 0x63, 0xf6, 0x89, 0xee, 0x41, 0xd9, 0xd6, 0xc7, 0x84, 0x4e, 0x74, 0x83, 0xd4, 0x73, 0x7b,
+	0xca, 0x7e, 0x19, 0x47, 0x08, 0xf5, 0x21, 0x6c, 0x1d, 0x13, 0x2f, 0xc1, 0xa4, 0x0a, 0x39, 0xcb,
+	0xe4, 0x3c, 0xca, 0x38, 0x67, 0x99, 0xea, 0x63, 0x40, 0x11, 0x11, 0x8d, 0x89, 0xb2, 0x4c, 0x5a,
+	0x57, 0xf6, 0xf2, 0xfb, 0x65, 0xcc, 0x3e, 0xd5, 0x77, 0x50, 0x6f, 0x99, 0xe6, 0x99, 0x3e, 0xf2,
+	0x49, 0xdf, 0x59, 0xcc, 0x93, 0x9d, 0xbe, 0x26, 0x53, 0x79, 0x21, 0xf6, 0x89, 0xbe, 0x03, 0x85,
+	0x1b, 0x76, 0xb4, 0x9e, 0xdf, 0x53, 0xf6, 0x2b, 0x07, 0xbb, 0x4d, 0x61, 0xb4, 0x66, 0x60, 0xb4,
+	0x26, 0x67, 0x8c, 0x05, 0x91, 0xfa, 0x4f, 0x05, 0xde, 0x0f, 0x84, 0xd1, 0x5b, 0xa5, 0xbd, 0x82,
+	0x22, 0x3f, 0x46, 0xeb, 0xf9, 0xbd, 0xfc, 0x7e, 0xe5, 0xe0, 0x59, 0x73, 0xd6, 0x43, 0xcd, 0xb9,
+	0xac, 0x84, 0x54, 0xaa, 0xd9, 0x9e, 0x3b, 0xc5, 0x92, 0x49, 0xe3, 0x0d, 0x54, 0x62, 0xe8, 0x40,
+	0x17, 0x25, 0x43, 0x97, 0xdc, 0x12, 0xba, 0x7c, 0x96, 0xfb, 0x81, 0xa2, 0xfe, 0x55, 0x81, 0x4d,
+	0x29, 0x19, 0x13, 0x3a, 0x71, 0x6c, 0x9a, 0xd6, 0xe2, 0x30, 0xd4, 0x22, 0xc7, 0xb5, 0xf8, 0x76,
+	0x52, 0x8b, 0x04, 0x83, 0x6f, 0xea, 0xee, 0xbf, 0x56, 0x60, 0x23, 0x12, 0xed, 0x8f, 0xbc, 0xd4,
+	0xcd, 0x3f, 0x85, 0x35, 0x79, 0x47, 0xc9, 0xf5, 0xc1, 0x2d, 0x57, 0xc7, 0x01, 0x3d, 0x42, 0xb0,
+	0x6a, 0x38, 0xa6, 0x88, 0x8a, 0x02, 0xe6, 0xdf, 0x68, 0x07, 0x0a, 0xc4, 0x75, 0x1d, 0xb7, 0xbe,
+	0xca, 0x25, 0x08, 0x40, 0x7d, 0x09, 0x35, 0xc9, 0x85, 0x86, 0x26, 0xfc, 0x04, 0xd6, 0x5c, 0x7e,
+	0x25, 0x11, 0xa8, 0x95, 0x83, 0xfb, 0xf3, 0x05, 0xfb, 0x23, 0x0f, 0x07, 0xd4, 0xea, 0x13, 0xa8,
+	0x77, 0xec, 0x1b, 0x7d, 0x64, 0x99, 0xe9, 0x47, 0x96, 0x7c, 0x1f, 0x2d, 0xb8, 0x9f, 0xa2, 0xe5,
+	0x46, 0x5a, 0x3a, 0xf8, 0xd5, 0x36, 0x7c, 0x90, 0xcd, 0x82, 0xce, 0xe3, 0x81, 0x60, 0xf5, 0x9a,
+	0x4c, 0x45, 0x28, 0x94, 0x31, 0xff, 0x56, 0x3f, 0x81, 0x6d, 0x4c, 0x6c, 0xf2, 0xe5, 0xed, 0x6f,
+	0x8f, 0x25, 0x89, 0x5c, 0x98, 0x24, 0xd4, 0x47, 0xb0, 0x7d, 0xae, 0x7b, 0xc6, 0xd5, 0x2d, 0x8a,
+	0xfe, 0x3d, 0x07, 0xeb, 0x92, 0x44, 0xbb, 0x21, 0xb6, 0x87, 0xbe, 0x0f, 0xab, 0xde, 0x74, 0x42,
+	0x38, 0x49, 0xf5, 0x60, 0x6f, 0x8e, 0x6d, 0x39, 0x6d, 0x7f, 0x3a, 0x21, 0x98, 0x53, 0x4b, 0xb6,
+	0xb9, 0x94, 0x2a, 0xf9, 0x48, 0x15, 0xf4, 0xa3, 0x30, 0xd6, 0x57, 0xb9, 0xdf, 0xf6, 0x17, 0xf1,
+	0xce, 0x0a, 0xf4, 0x40, 0xcb, 0x42, 0x94, 0x0a, 0x11, 0xac, 0x7a, 0xd6, 0x98, 0xd4, 0x8b, 0x1c,
+	0xc5, 0xbf, 0x67, 0xd3, 0xe3, 0x5a, 0x22, 0x3d, 0x7e, 0x1d, 0x8f, 0xe5, 0xf7, 0x0a, 0xec, 0xf6,
+	0xfc, 0x21, 0x35, 0x5c, 0x6b, 0x48, 0xf8, 0xed, 0x43, 0x17, 0xef, 0x40, 0xe1, 0xd2, 0x75, 0xfc,
+	0x89, 0x14, 0x20, 0x00, 0xd4, 0x80, 0x92, 0xe1, 0xd8, 0xd4, 0x1f, 0x13, 0x57, 0xda, 0x2c, 0x84,
+	0xd1, 0xfb, 0x50, 0xa2, 0x9e, 0xee, 0x7a, 0x03, 0xcb, 0xe4, 0x0f, 0xa4, 0x8c, 0xd7, 0x38, 0xdc,
+	0x31, 0xd1, 0xc7, 0x50, 0x60, 0xc6, 0x16, 0xf6, 0x5b, 0xc6, 0x37, 0x82, 0x5c, 0xfd, 0x19, 0x54,
+	0x38, 0x0e, 0x13, 0xc3, 0x71, 0x4d, 0x74, 0x17, 0xca, 0xd4, 0x73, 0x89, 0x3e, 0x1e, 0x84, 0x91,
+	0x50, 0x12, 0x88, 0x8e, 0x89, 0x0e, 0xa0, 0x40, 0x18, 0xad, 0xd4, 0xfe, 0xde, 0x22, 0x19, 0x58,
+	0x90, 0xaa, 0xc7, 0x50, 0x6b, 0x19, 0xd7, 0xcb, 0x28, 0x7e, 0x1f, 0x20, 0x14, 0x1d, 0xc4, 0x79,
+	0x39, 0x90, 0x4d, 0xd5, 0xdf, 0xe5, 0xc2, 0xac, 0x73, 0x64, 0x8d, 0x3c, 0xe2, 0xce, 0xfa, 0x52,
+	0x49, 0xf8, 0x92, 0xd9, 0xea, 0x4a, 0xa7, 0x83, 0xd8, 0xa3, 0x59, 0xbb, 0xd2, 0xe9, 0x4b, 0x16,
+	0x6c, 0x6f, 0x60, 0x9d, 0x3b, 0x68, 0x40, 0x7e, 0xe1, 0xeb, 0xa3, 0xa0, 0x48, 0x34, 0xe7, 0xa8,
+	0x23, 0xa4, 0x09, 0xcf, 0x6a, 0xfc, 0x80, 0x08, 0xbc, 0xca, 0x4d, 0x84, 0x41, 0x8f, 0xa0, 0x6a,
+	0xf0, 0x02, 0x6d, 0x0e, 0x86, 0xe4, 0xc2, 0x71, 0x09, 0xcf, 0x55, 0x79, 0xbc, 0x21, 0xb1, 0xcf,
+	0x39, 0xb2, 0x71, 0x06, 0xb5, 0x24, 0x9f, 0xaf, 0x24, 0xca, 0x2c, 0xb8, 0xf3, 0xdc, 0x1f, 0x5d,
+	0x87, 0x39, 0x25, 0xf6, 0xa8, 0x9f, 0x41, 0xf1, 0x82, 0x6b, 0xc0, 0x25, 0xcc, 0xcf, 0x88, 0x42,
+	0x4d, 0x2c, 0x89, 0xd1, 0x1d, 0x58, 0x33, 0xdd, 0xe9, 0xc0, 0xf5, 0x45, 0x0a, 0x2f, 0xe1, 0xa2,
+	0xe9, 0x4e, 0xb1, 0x6f, 0xab, 0x0f, 0x60, 0xe3, 0x98, 0x78, 0x2f, 0x9c, 0xe1, 0xbc, 0xac, 0xf1,
+	0x97, 0x1c, 0x54, 0x5e, 0x38, 0xc3, 0xb9, 0x65, 0x8d, 0x3d, 0x7f, 0xcb, 0x0e, 0x12, 0x02, 0xff,
+	0x46, 0x4d, 0x28, 0x50, 0x4f, 0xf7, 0x44, 0xda, 0xaf, 0x1e, 0xd4, 0x93, 0x77, 0x7c, 0xe1, 0x0c,
+	0x7b, 0xec, 0x3f, 0x16, 0x64, 0xf1, 0xdb, 0xad, 0xc6, 0x6f, 0x87, 0xea, 0xb0, 0x46, 0x0d, 0xdd,
+	0xb6, 0x89, 0x29, 0x33, 0x41, 0x00, 0xb2, 0x3f, 0x63, 0x96, 0xf3, 0x88, 0x29, 0x13, 0x42, 0x00,
+	0xb2, 0x3f, 0x26, 0x19, 0x11, 0x8f, 0x98, 0x3c, 0x23, 0xe4, 0x71, 0x00, 0x46, 0x85, 0xa7, 0x14,
+	0x2b, 0x3c, 0x22, 0x50, 0x75, 0x97, 0xf9, 0x5a, 0xf7, 0xea, 0x65, 0x7e, 0xa4, 0x2c, 0x31, 0x2d,
+	0x0f, 0x3d, 0x80, 0xca, 0x85, 0x65, 0x5b, 0xf4, 0x4a, 0xfc, 0x07, 0xfe, 0x1f, 0x02, 0x54, 0xcb,
+	0x63, 0xf2, 0xfc, 0x09, 0x4b, 0xfc, 0x66, 0xbd, 0x22, 0xe4, 0x49, 0x50, 0xfd, 0x15, 0x6c, 0x9f,
+	0x58, 0x34, 0xd5, 0x7a, 0xfd, 0x97, 0x2e, 0xdc, 0x85, 0xa2, 0xe1, 0xbb, 0xd4, 0x09, 0xf2, 0x88,
+	0x84, 0x98, 0x56, 0x23, 0x6b, 0x6c, 0x79, 0xb2, 0xc6, 0x0a, 0x40, 0xfd, 0x93, 0x02, 0x55, 0xc9,
+	0xa7, 0xe7, 0x8f, 0xc7, 0xba, 0x3b, 0x4d, 0x79, 0x6e, 0x61, 0x6f, 0xc9, 0xcc, 0x12, 0x3c, 0x01,
+	0x5d, 0xf0, 0xce, 0xe3, 0xb2, 0xc4, 0xb4, 0xbc, 0x20, 0x3f, 0xaf, 0x46, 0xf9, 0xf9, 0x21, 0x6c,
+	0xb8, 0x64, 0xac, 0x5b, 0xb6, 0x65, 0x5f, 0x0e, 0xa2, 0xdc, 0xbd, 0x1e, 0x22, 0xfb, 0x22, 0x89,
+	0xf3, 0x27, 0x5c, 0x8c, 0xd5, 0xbd, 0x77, 0xb0, 0x13, 0x37, 0x53, 0x18, 0x69, 0x9f, 0x41, 0x49,
+	0x5a, 0x24, 0x28, 0xff, 0x1f, 0xcc, 0xb1, 0x94, 0xd4, 0x10, 0x87, 0xf4, 0xf3, 0x8c, 0xa5, 0x76,
+	0xe1, 0x3d, 0xed, 0x97, 0x13, 0xc7, 0xfd, 0x8a, 0x9c, 0xa2, 0xfe, 0x39, 0x07, 0x9b, 0x82, 0x21,
+	0x31, 0x25, 0xc5, 0xff, 0x84, 0x9d, 0xa3, 0x66, 0xb3, 0x98, 0xdd, 0x6c, 0x26, 0x2e, 0xfd, 0x4d,
+	0x35, 0x9b, 0xbf, 0x51, 0x60, 0xa7, 0x33, 0x8e, 0x39, 0x20, 0xb0, 0x7f, 0xac, 0xc7, 0x54, 0xb2,
+	0x7b, 0xcc, 0xc4, 0x8d, 0xa3, 0x1e, 0xf3, 0x63, 0x28, 0x4e, 0x9c, 0x91, 0x65, 0x88, 0x96, 0xac,
+	0x9a, 0x8e, 0x92, 0x43, 0xc7, 0xbe, 0x18, 0x59, 0x86, 0xf7, 0x9a, 0x53, 0x61, 0x49, 0xad, 0x3e,
+	0x85, 0x8a, 0xb8, 0x8a, 0xc6, 0xf3, 0x40, 0xd2, 0x6d, 0x61, 0xb6, 0xc8, 0xc5, 0xdb, 0xd4, 0x7f,
+	0x29, 0xb0, 0x3b, 0xa3, 0x40, 0x14, 0xaf, 0x0d, 0x28, 0xb9, 0xc4, 0x20, 0xd6, 0x0d, 0x31, 0xe5,
+	0x08, 0x17, 0xc2, 0x2c, 0x49, 0x48, 0x9f, 0xca, 0xc6, 0x2d, 0x00, 0xd9, 0xb4, 0xe8, 0xdc, 0x10,
+	0xf7, 0x4b, 0xd7, 0xf2, 0x3c, 0x62, 0xcb, 0x00, 0x88, 0xa3, 0x58, 0x2c, 0x8f, 0x89, 0x7b, 0x49,
+	0x4c, 0x19, 0x05, 0x12, 0x62, 0x3c, 0xe9, 0xb5, 0x35, 0x99, 0xc4, 0x92, 0xa3, 0x00, 0xd9, 0x89,
+	0x0b, 0xdd, 0x1a, 0x85, 0xb9, 0x51, 0x42, 0xe8, 0x29, 0x14, 0xb9, 0x16, 0xb4, 0xbe, 0xc6, 0xa3,
+	0xe2, 0x6e, 0xd2, 0x52, 0x31, 0x7b, 0x60, 0x49, 0xaa, 0x36, 0x59, 0x5b, 0x4a, 0x6c, 0xc3, 0x9d,
+	0x4e, 0xe2, 0x85, 0x28, 0x96, 0xb3, 0x95, 0x99, 0x8a, 0xf2, 0xdb, 0x3c, 0x54, 0x5a, 0xbe, 0x69,
+	0x05, 0x3d, 0x48, 0x46, 0xc1, 0xe0, 0x7d, 0x5c, 0x2e, 0xd6, 0xc7, 0xb1, 0xe7, 0x3a, 0xb2, 0x58,
+	0x2f, 0x92, 0x97, 0xcf, 0x95, 0x43, 0x5c, 0x45, 0x7f, 0xf8, 0x8e, 0x18, 0x9e, 0x1c, 0x16, 0x02,
+	0x90, 0x05, 0xe3, 0x8d, 0xa5, 0x73, 0xc5, 0xcb, 0x98, 0x7d, 0x32, 0xbe, 0x13, 0x42, 0x5c, 0xae,
+	0x72, 0x19, 0xf3, 0x6f, 0x61, 0x3a, 0xef, 0xca, 0x31, 0x65, 0x73, 0x28, 0x21, 0x9e, 0xf3, 0x85,
+	0xca, 0xac, 0x31, 0x12, 0xe5, 0xa0, 0x2c, 0x31, 0x9d, 0xa8, 0xa5, 0x2d, 0xc7, 0x5a, 0xda, 0x1f,
+	0x86, 0x2f, 0x0a, 0xb8, 0xed, 0xfe, 0x3f, 0x35, 0x84, 0x46, 0x3a, 0x67, 0x76, 0xb4, 0xc1, 0x28,
+	0x54, 0xc9, 0x1a, 0x85, 0xd6, 0x63, 0x31, 0xf6, 0x75, 0xbc, 0xbb, 0x3f, 0x2a, 0xb0, 0xc5, 0x2f,
+	0xf8, 0xc6, 0x27, 0xee, 0x34, 0xf0, 0xe1, 0xac, 0x19, 0x94, 0xa4, 0x19, 0x22, 0xaf, 0xe4, 0x66,
+	0xbc, 0xb2, 0x03, 0x05, 0x6a, 0xd9, 0x06, 0x91, 0xc1, 0x2a, 0x00, 0x86, 0xf5, 0x6d, 0xcf, 0x0a,
+	0x72, 0x95, 0x00, 0x62, 0x89, 0xb8, 0x90, 0x5d, 0xb5, 0x8a, 0xf1, 0xaa, 0xa5, 0x43, 0x8d, 0xdf,
+	0xf2, 0xc4, 0xb9, 0x0c, 0x9f, 0xd5, 0x33, 0x36, 0x04, 0x32, 0xab, 0x06, 0x55, 0xe0, 0xee, 0x02,
+	0xcb, 0xe3, 0x80, 0x76, 0x5e, 0x05, 0x78, 0xf2, 0x07, 0x25, 0x1c, 0x34, 0xc3, 0xee, 0x19, 0x6d,
+	0xc1, 0xc6, 0xdb, 0xee, 0xcb, 0xee, 0xe9, 0x79, 0x77, 0xa0, 0x9d, 0x69, 0xdd, 0x7e, 0x6d, 0x05,
+	0x6d, 0xc3, 0x66, 0x4f, 0xeb, 0xf5, 0x3a, 0xa7, 0xdd, 0xc1, 0x21, 0xd6, 0x5a, 0x7d, 0xad, 0x5d,
+	0x53, 0x10, 0x82, 0xea, 0x59, 0xeb, 0xe4, 0xad, 0xd6, 0x1b, 0x1c, 0xfe, 0xb8, 0xd5, 0x3d, 0xd6,
+	0xda, 0xb5, 0x5c, 0x0c, 0xd7, 0xd6, 0x4e, 0x34, 0x46, 0x97, 0x47, 0x9b, 0x50, 0xe9, 0xf7, 0x4f,
+	0x06, 0x58, 0xeb, 0x6a, 0xe7, 0x5a, 0xbb, 0xb6, 0x1a, 0xe7, 0xa6, 0x7d, 0xfe, 0xba, 0x83, 0xb5,
+	0x76, 0xad, 0x80, 0xee, 0xc0, 0x76, 0x80, 0xec, 0x74, 0xcf, 0x5a, 0x27, 0x9d, 0x36, 0x17, 0x53,
+	0x7c, 0xf2, 0x29, 0x94, 0x82, 0x16, 0x89, 0xb1, 0x7a, 0x71, 0xfa, 0x7c, 0x80, 0xdf, 0x76, 0xbb,
+	0x9d, 0xee, 0x71, 0x6d, 0x05, 0xad, 0x43, 0x89, 0x21, 0xda, 0xa7, 0x5d, 0xad, 0xa6, 0xa0, 0x2a,
+	0x00, 0x83, 0x8e, 0x5a, 0x9d, 0x13, 0x76, 0x9b, 0x27, 0xa7, 0x50, 0x9d, 0x4d, 0x77, 0x4c, 0xb7,
+	0xc3, 0xd3, 0xee, 0xd1, 0x49, 0xe7, 0xb0, 0x3f, 0xe8, 0xbd, 0xec, 0xbc, 0xae, 0xad, 0xa0, 0x5d,
+	0x40, 0x21, 0xea, 0xf4, 0x4c, 0xc3, 0xe7, 0xb8, 0xd3, 0xd7, 0x84, 0x7a, 0x21, 0xfe, 0x95, 0x86,
+	0x8f, 0xb5, 0x5a, 0xee, 0xe0, 0x1f, 0x6b, 0xb0, 0xd9, 0x0e, 0xea, 0x2c, 0x71, 0x6f, 0x2c, 0x83,
+	0x20, 0x0c, 0x10, 0xad, 0x94, 0xd0, 0xff, 0x25, 0xfd, 0x91, 0xda, 0x49, 0x35, 0x6e, 0x5b, 0x18,
+	0xa8, 0x2b, 0xe8, 0x2d, 0x54, 0xa2, 0x73, 0x14, 0xa9, 0xf3, 0x99, 0x06, 0x35, 0xbb, 0x31, 0x6f,
+	0x2a, 0xa2, 0x31, 0xb6, 0x9f, 0xc3, 0xc6, 0xcc, 0xaa, 0x0d, 0x7d, 0x98, 0xaa, 0x0e, 0x19, 0x9b,
+	0xb8, 0x65, 0x2e, 0xfc, 0x73, 0xd8, 0x4a, 0xed, 0xcb, 0xd0, 0xfe, 0xbc, 0xd5, 0x54, 0xdf, 0xf9,
+	0xcf, 0x25, 0x0c, 0x01, 0xa5, 0x37, 0x5b, 0xe8, 0x5b, 0x4b, 0x6f, 0xbf, 0x96, 0x91, 0x61, 0xc1,
+	0x6e, 0xf6, 0xea, 0x02, 0x7d, 0x37, 0x55, 0x1c, 0x16, 0x6d, 0x49, 0x1a, 0xe9, 0xde, 0x6c, 0x66,
+	0x2b, 0xaa, 0xae, 0xa0, 0x6b, 0xb8, 0x93, 0xcd, 0x82, 0xa2, 0xe6, 0x72, 0xb2, 0xe8, 0xf2, 0xc2,
+	0x06, 0xb0, 0x95, 0xe2, 0x91, 0xf6, 0xce, 0xbc, 0x25, 0xd1, 0x12, 0x02, 0xce, 0x60, 0x3d, 0xbe,
+	0xad, 0x41, 0x0f, 0x93, 0x27, 0x32, 0x76, 0x39, 0xcb, 0xbd, 0x83, 0xf5, 0xf8, 0x32, 0x27, 0xcd,
+	0x37, 0x63, 0xd5, 0xd3, 0x58, 0x38, 0xbb, 0xab, 0x2b, 0xdf, 0x53, 0x0e, 0xfe, 0x56, 0x84, 0x9d,
+	0xe0, 0x19, 0xb7, 0xcc, 0xb1, 0x15, 0xbe, 0xe5, 0x01, 0xbc, 0xd7, 0x63, 0xc3, 0x4e, 0x72, 0xe0,
+	0x44, 0xa9, 0x02, 0x37, 0x67, 0x24, 0x6d, 0xdc, 0xcd, 0x18, 0xef, 0x62, 0x0a, 0x1d, 0x41, 0x51,
+	0x4c, 0x98, 0xe8, 0x7e, 0xc6, 0x9b, 0x8e, 0x26, 0xcf, 0xdb, 0xf8, 0x7c, 0x01, 0x9b, 0x89, 0xcd,
+	0x0b, 0x7a, 0x9c, 0xf6, 0x52, 0xd6, 0x6a, 0x26, 0xcd, 0x39, 0xb6, 0x23, 0x61, 0xd6, 0x41, 0xa7,
+	0x50, 0x0e, 0xd7, 0x1a, 0x28, 0x95, 0x56, 0x92, 0x1b, 0x8f, 0x25, 0xa2, 0xe3, 0x27, 0xb0, 0x1e,
+	0x9f, 0x69, 0xd2, 0x5e, 0xcc, 0x18, 0x0c, 0x1b, 0x1f, 0x2e, 0x22, 0x8a, 0x59, 0xe2, 0xa7, 0x50,
+	0x9d, 0x1d, 0x62, 0xd0, 0xa3, 0xec, 0x66, 0x39, 0x29, 0xe0, 0xb6, 0x9e, 0x9a, 0xdb, 0x62, 0x08,
+	0xd5, 0xd9, 0x06, 0x37, 0x9d, 0x32, 0xb3, 0x3a, 0xf8, 0xc6, 0xe3, 0x85, 0x54, 0xb1, 0xfb, 0xef,
+	0x2b, 0xe8, 0x1c, 0xb6, 0x78, 0xd0, 0xc5, 0x1b, 0xcb, 0xac, 0x17, 0x94, 0x6a, 0x3b, 0x6f, 0x0b,
+	0x92, 0x33, 0xd8, 0xe0, 0x1d, 0x4e, 0xd0, 0x45, 0xa4, 0x8b, 0x53, 0xaa, 0x0b, 0x6a, 0xec, 0x65,
+	0x92, 0xc4, 0x5a, 0x10, 0x75, 0xe5, 0x79, 0xf9, 0x8b, 0x60, 0xdc, 0x18, 0x16, 0x79, 0x97, 0xf5,
+	0xf4, 0xdf, 0x03, 0x00, 0x9a, 0x70, 0x54, 0x36, 0x36, 0x1a, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// DSessionServiceClient is the client API for DSessionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DSessionServiceClient interface {
+	GetSession(ctx context.Context, in *GetSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error)
+	GetSessions(ctx context.Context, in *GetSessionsMessage, opts ...grpc.CallOption) (*SessionsResponse, error)
+	CreateSession(ctx context.Context, in *CreateSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error)
+	AddValueToSession(ctx context.Context, in *AddValueToSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error)
+	AddValuesToSession(ctx context.Context, in *AddValuesToSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error)
+	InvalidateSessionValue(ctx context.Context, in *InvalidateSessionValueMessage, opts ...grpc.CallOption) (*SuccessMessage, error)
+	InvalidateSessionValues(ctx context.Context, in *InvalidateSessionValuesMessage, opts ...grpc.CallOption) (*SuccessMessage, error)
+	InvalidateSession(ctx context.Context, in *InvalidateSessionMessage, opts ...grpc.CallOption) (*SuccessMessage, error)
+	RenewSession(ctx context.Context, in *RenewSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error)
+	WatchSession(ctx context.Context, in *WatchSessionMessage, opts ...grpc.CallOption) (DSessionService_WatchSessionClient, error)
+}
+
+type dSessionServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDSessionServiceClient(cc *grpc.ClientConn) DSessionServiceClient {
+	return &dSessionServiceClient{cc}
+}
+
+func (c *dSessionServiceClient) GetSession(ctx context.Context, in *GetSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error) {
+	out := new(SessionResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/GetSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionServiceClient) GetSessions(ctx context.Context, in *GetSessionsMessage, opts ...grpc.CallOption) (*SessionsResponse, error) {
+	out := new(SessionsResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/GetSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionServiceClient) CreateSession(ctx context.Context, in *CreateSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error) {
+	out := new(SessionResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/CreateSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionServiceClient) AddValueToSession(ctx context.Context, in *AddValueToSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error) {
+	out := new(SessionResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/AddValueToSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionServiceClient) AddValuesToSession(ctx context.Context, in *AddValuesToSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error) {
+	out := new(SessionResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/AddValuesToSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionServiceClient) InvalidateSessionValue(ctx context.Context, in *InvalidateSessionValueMessage, opts ...grpc.CallOption) (*SuccessMessage, error) {
+	out := new(SuccessMessage)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/InvalidateSessionValue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionServiceClient) InvalidateSessionValues(ctx context.Context, in *InvalidateSessionValuesMessage, opts ...grpc.CallOption) (*SuccessMessage, error) {
+	out := new(SuccessMessage)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/InvalidateSessionValues", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionServiceClient) InvalidateSession(ctx context.Context, in *InvalidateSessionMessage, opts ...grpc.CallOption) (*SuccessMessage, error) {
+	out := new(SuccessMessage)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/InvalidateSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionServiceClient) RenewSession(ctx context.Context, in *RenewSessionMessage, opts ...grpc.CallOption) (*SessionResponse, error) {
+	out := new(SessionResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionService/RenewSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionServiceClient) WatchSession(ctx context.Context, in *WatchSessionMessage, opts ...grpc.CallOption) (DSessionService_WatchSessionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DSessionService_serviceDesc.Streams[0], "/hobord.session.DSessionService/WatchSession", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dSessionServiceWatchSessionClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DSessionService_WatchSessionClient interface {
+	Recv() (*SessionEvent, error)
+	grpc.ClientStream
+}
+
+type dSessionServiceWatchSessionClient struct {
+	grpc.ClientStream
+}
+
+func (x *dSessionServiceWatchSessionClient) Recv() (*SessionEvent, error) {
+	m := new(SessionEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DSessionServiceServer is the server API for DSessionService service.
+type DSessionServiceServer interface {
+	GetSession(context.Context, *GetSessionMessage) (*SessionResponse, error)
+	GetSessions(context.Context, *GetSessionsMessage) (*SessionsResponse, error)
+	CreateSession(context.Context, *CreateSessionMessage) (*SessionResponse, error)
+	AddValueToSession(context.Context, *AddValueToSessionMessage) (*SessionResponse, error)
+	AddValuesToSession(context.Context, *AddValuesToSessionMessage) (*SessionResponse, error)
+	InvalidateSessionValue(context.Context, *InvalidateSessionValueMessage) (*SuccessMessage, error)
+	InvalidateSessionValues(context.Context, *InvalidateSessionValuesMessage) (*SuccessMessage, error)
+	InvalidateSession(context.Context, *InvalidateSessionMessage) (*SuccessMessage, error)
+	RenewSession(context.Context, *RenewSessionMessage) (*SessionResponse, error)
+	WatchSession(*WatchSessionMessage, DSessionService_WatchSessionServer) error
+}
+
+// UnimplementedDSessionServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedDSessionServiceServer struct {
+}
+
+func (*UnimplementedDSessionServiceServer) GetSession(ctx context.Context, req *GetSessionMessage) (*SessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSession not implemented")
+}
+func (*UnimplementedDSessionServiceServer) GetSessions(ctx context.Context, req *GetSessionsMessage) (*SessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSessions not implemented")
+}
+func (*UnimplementedDSessionServiceServer) CreateSession(ctx context.Context, req *CreateSessionMessage) (*SessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSession not implemented")
+}
+func (*UnimplementedDSessionServiceServer) AddValueToSession(ctx context.Context, req *AddValueToSessionMessage) (*SessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddValueToSession not implemented")
+}
+func (*UnimplementedDSessionServiceServer) AddValuesToSession(ctx context.Context, req *AddValuesToSessionMessage) (*SessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddValuesToSession not implemented")
+}
+func (*UnimplementedDSessionServiceServer) InvalidateSessionValue(ctx context.Context, req *InvalidateSessionValueMessage) (*SuccessMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InvalidateSessionValue not implemented")
+}
+func (*UnimplementedDSessionServiceServer) InvalidateSessionValues(ctx context.Context, req *InvalidateSessionValuesMessage) (*SuccessMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InvalidateSessionValues not implemented")
 }
 func (*UnimplementedDSessionServiceServer) InvalidateSession(ctx context.Context, req *InvalidateSessionMessage) (*SuccessMessage, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InvalidateSession not implemented")
 }
+func (*UnimplementedDSessionServiceServer) RenewSession(ctx context.Context, req *RenewSessionMessage) (*SessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewSession not implemented")
+}
+func (*UnimplementedDSessionServiceServer) WatchSession(req *WatchSessionMessage, srv DSessionService_WatchSessionServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSession not implemented")
+}
 
 func RegisterDSessionServiceServer(s *grpc.Server, srv DSessionServiceServer) {
 	s.RegisterService(&_DSessionService_serviceDesc, srv)
 }
 
-func _DSessionService_GetSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetSessionMessage)
+func _DSessionService_GetSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSessionMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSessionServiceServer).GetSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hobord.session.DSessionService/GetSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSessionServiceServer).GetSession(ctx, req.(*GetSessionMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSessionService_GetSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSessionsMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSessionServiceServer).GetSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hobord.session.DSessionService/GetSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSessionServiceServer).GetSessions(ctx, req.(*GetSessionsMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSessionService_CreateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSessionMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSessionServiceServer).CreateSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hobord.session.DSessionService/CreateSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSessionServiceServer).CreateSession(ctx, req.(*CreateSessionMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSessionService_AddValueToSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddValueToSessionMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSessionServiceServer).AddValueToSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hobord.session.DSessionService/AddValueToSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSessionServiceServer).AddValueToSession(ctx, req.(*AddValueToSessionMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSessionService_AddValuesToSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddValuesToSessionMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSessionServiceServer).AddValuesToSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hobord.session.DSessionService/AddValuesToSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSessionServiceServer).AddValuesToSession(ctx, req.(*AddValuesToSessionMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSessionService_InvalidateSessionValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvalidateSessionValueMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSessionServiceServer).InvalidateSessionValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hobord.session.DSessionService/InvalidateSessionValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSessionServiceServer).InvalidateSessionValue(ctx, req.(*InvalidateSessionValueMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSessionService_InvalidateSessionValues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvalidateSessionValuesMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSessionServiceServer).InvalidateSessionValues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hobord.session.DSessionService/InvalidateSessionValues",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSessionServiceServer).InvalidateSessionValues(ctx, req.(*InvalidateSessionValuesMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSessionService_InvalidateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvalidateSessionMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DSessionServiceServer).InvalidateSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hobord.session.DSessionService/InvalidateSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DSessionServiceServer).InvalidateSession(ctx, req.(*InvalidateSessionMessage))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DSessionService_RenewSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewSessionMessage)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DSessionServiceServer).GetSession(ctx, in)
+		return srv.(DSessionServiceServer).RenewSession(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/hobord.session.DSessionService/GetSession",
+		FullMethod: "/hobord.session.DSessionService/RenewSession",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DSessionServiceServer).GetSession(ctx, req.(*GetSessionMessage))
+		return srv.(DSessionServiceServer).RenewSession(ctx, req.(*RenewSessionMessage))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DSessionService_CreateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateSessionMessage)
+func _DSessionService_WatchSession_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSessionMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DSessionServiceServer).WatchSession(m, &dSessionServiceWatchSessionServer{stream})
+}
+
+type DSessionService_WatchSessionServer interface {
+	Send(*SessionEvent) error
+	grpc.ServerStream
+}
+
+type dSessionServiceWatchSessionServer struct {
+	grpc.ServerStream
+}
+
+func (x *dSessionServiceWatchSessionServer) Send(m *SessionEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _DSessionService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "hobord.session.DSessionService",
+	HandlerType: (*DSessionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSession",
+			Handler:    _DSessionService_GetSession_Handler,
+		},
+		{
+			MethodName: "GetSessions",
+			Handler:    _DSessionService_GetSessions_Handler,
+		},
+		{
+			MethodName: "CreateSession",
+			Handler:    _DSessionService_CreateSession_Handler,
+		},
+		{
+			MethodName: "AddValueToSession",
+			Handler:    _DSessionService_AddValueToSession_Handler,
+		},
+		{
+			MethodName: "AddValuesToSession",
+			Handler:    _DSessionService_AddValuesToSession_Handler,
+		},
+		{
+			MethodName: "InvalidateSessionValue",
+			Handler:    _DSessionService_InvalidateSessionValue_Handler,
+		},
+		{
+			MethodName: "InvalidateSessionValues",
+			Handler:    _DSessionService_InvalidateSessionValues_Handler,
+		},
+		{
+			MethodName: "InvalidateSession",
+			Handler:    _DSessionService_InvalidateSession_Handler,
+		},
+		{
+			MethodName: "RenewSession",
+			Handler:    _DSessionService_RenewSession_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchSession",
+			Handler:       _DSessionService_WatchSession_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "session.proto",
+}
+
+// DSessionAdminServiceClient is the client API for DSessionAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DSessionAdminServiceClient interface {
+	StartBulkInvalidation(ctx context.Context, in *BulkInvalidationMessage, opts ...grpc.CallOption) (*JobResponse, error)
+	GetJob(ctx context.Context, in *GetJobMessage, opts ...grpc.CallOption) (*JobResponse, error)
+	SubscribeEvents(ctx context.Context, in *SubscribeEventsMessage, opts ...grpc.CallOption) (DSessionAdminService_SubscribeEventsClient, error)
+	AckEvents(ctx context.Context, in *AckEventsMessage, opts ...grpc.CallOption) (*SuccessMessage, error)
+	ListSessions(ctx context.Context, in *ListSessionsMessage, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	ExportSessions(ctx context.Context, in *ExportSessionsMessage, opts ...grpc.CallOption) (DSessionAdminService_ExportSessionsClient, error)
+	ImportSessions(ctx context.Context, opts ...grpc.CallOption) (DSessionAdminService_ImportSessionsClient, error)
+	StartReencryption(ctx context.Context, in *ReencryptionMessage, opts ...grpc.CallOption) (*JobResponse, error)
+	QueryAuditLog(ctx context.Context, in *AuditQueryMessage, opts ...grpc.CallOption) (*AuditLogResponse, error)
+}
+
+type dSessionAdminServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDSessionAdminServiceClient(cc *grpc.ClientConn) DSessionAdminServiceClient {
+	return &dSessionAdminServiceClient{cc}
+}
+
+func (c *dSessionAdminServiceClient) StartBulkInvalidation(ctx context.Context, in *BulkInvalidationMessage, opts ...grpc.CallOption) (*JobResponse, error) {
+	out := new(JobResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionAdminService/StartBulkInvalidation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionAdminServiceClient) GetJob(ctx context.Context, in *GetJobMessage, opts ...grpc.CallOption) (*JobResponse, error) {
+	out := new(JobResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionAdminService/GetJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionAdminServiceClient) SubscribeEvents(ctx context.Context, in *SubscribeEventsMessage, opts ...grpc.CallOption) (DSessionAdminService_SubscribeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DSessionAdminService_serviceDesc.Streams[0], "/hobord.session.DSessionAdminService/SubscribeEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dSessionAdminServiceSubscribeEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DSessionAdminService_SubscribeEventsClient interface {
+	Recv() (*EventRecord, error)
+	grpc.ClientStream
+}
+
+type dSessionAdminServiceSubscribeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *dSessionAdminServiceSubscribeEventsClient) Recv() (*EventRecord, error) {
+	m := new(EventRecord)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dSessionAdminServiceClient) AckEvents(ctx context.Context, in *AckEventsMessage, opts ...grpc.CallOption) (*SuccessMessage, error) {
+	out := new(SuccessMessage)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionAdminService/AckEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionAdminServiceClient) ListSessions(ctx context.Context, in *ListSessionsMessage, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionAdminService/ListSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionAdminServiceClient) ExportSessions(ctx context.Context, in *ExportSessionsMessage, opts ...grpc.CallOption) (DSessionAdminService_ExportSessionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DSessionAdminService_serviceDesc.Streams[1], "/hobord.session.DSessionAdminService/ExportSessions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dSessionAdminServiceExportSessionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DSessionAdminService_ExportSessionsClient interface {
+	Recv() (*ExportedSession, error)
+	grpc.ClientStream
+}
+
+type dSessionAdminServiceExportSessionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *dSessionAdminServiceExportSessionsClient) Recv() (*ExportedSession, error) {
+	m := new(ExportedSession)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dSessionAdminServiceClient) ImportSessions(ctx context.Context, opts ...grpc.CallOption) (DSessionAdminService_ImportSessionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_DSessionAdminService_serviceDesc.Streams[2], "/hobord.session.DSessionAdminService/ImportSessions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dSessionAdminServiceImportSessionsClient{stream}
+	return x, nil
+}
+
+type DSessionAdminService_ImportSessionsClient interface {
+	Send(*ImportSessionMessage) error
+	CloseAndRecv() (*ImportSessionsResponse, error)
+	grpc.ClientStream
+}
+
+type dSessionAdminServiceImportSessionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *dSessionAdminServiceImportSessionsClient) Send(m *ImportSessionMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dSessionAdminServiceImportSessionsClient) CloseAndRecv() (*ImportSessionsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportSessionsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dSessionAdminServiceClient) StartReencryption(ctx context.Context, in *ReencryptionMessage, opts ...grpc.CallOption) (*JobResponse, error) {
+	out := new(JobResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionAdminService/StartReencryption", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dSessionAdminServiceClient) QueryAuditLog(ctx context.Context, in *AuditQueryMessage, opts ...grpc.CallOption) (*AuditLogResponse, error) {
+	out := new(AuditLogResponse)
+	err := c.cc.Invoke(ctx, "/hobord.session.DSessionAdminService/QueryAuditLog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DSessionAdminServiceServer is the server API for DSessionAdminService service.
+type DSessionAdminServiceServer interface {
+	StartBulkInvalidation(context.Context, *BulkInvalidationMessage) (*JobResponse, error)
+	GetJob(context.Context, *GetJobMessage) (*JobResponse, error)
+	SubscribeEvents(*SubscribeEventsMessage, DSessionAdminService_SubscribeEventsServer) error
+	AckEvents(context.Context, *AckEventsMessage) (*SuccessMessage, error)
+	ListSessions(context.Context, *ListSessionsMessage) (*ListSessionsResponse, error)
+	ExportSessions(*ExportSessionsMessage, DSessionAdminService_ExportSessionsServer) error
+	ImportSessions(DSessionAdminService_ImportSessionsServer) error
+	StartReencryption(context.Context, *ReencryptionMessage) (*JobResponse, error)
+	QueryAuditLog(context.Context, *AuditQueryMessage) (*AuditLogResponse, error)
+}
+
+// UnimplementedDSessionAdminServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedDSessionAdminServiceServer struct {
+}
+
+func (*UnimplementedDSessionAdminServiceServer) StartBulkInvalidation(ctx context.Context, req *BulkInvalidationMessage) (*JobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartBulkInvalidation not implemented")
+}
+func (*UnimplementedDSessionAdminServiceServer) GetJob(ctx context.Context, req *GetJobMessage) (*JobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (*UnimplementedDSessionAdminServiceServer) SubscribeEvents(req *SubscribeEventsMessage, srv DSessionAdminService_SubscribeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (*UnimplementedDSessionAdminServiceServer) AckEvents(ctx context.Context, req *AckEventsMessage) (*SuccessMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AckEvents not implemented")
+}
+func (*UnimplementedDSessionAdminServiceServer) ListSessions(ctx context.Context, req *ListSessionsMessage) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (*UnimplementedDSessionAdminServiceServer) ExportSessions(req *ExportSessionsMessage, srv DSessionAdminService_ExportSessionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportSessions not implemented")
+}
+func (*UnimplementedDSessionAdminServiceServer) ImportSessions(srv DSessionAdminService_ImportSessionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportSessions not implemented")
+}
+func (*UnimplementedDSessionAdminServiceServer) StartReencryption(ctx context.Context, req *ReencryptionMessage) (*JobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartReencryption not implemented")
+}
+func (*UnimplementedDSessionAdminServiceServer) QueryAuditLog(ctx context.Context, req *AuditQueryMessage) (*AuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryAuditLog not implemented")
+}
+
+func RegisterDSessionAdminServiceServer(s *grpc.Server, srv DSessionAdminServiceServer) {
+	s.RegisterService(&_DSessionAdminService_serviceDesc, srv)
+}
+
+func _DSessionAdminService_StartBulkInvalidation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkInvalidationMessage)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DSessionServiceServer).CreateSession(ctx, in)
+		return srv.(DSessionAdminServiceServer).StartBulkInvalidation(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/hobord.session.DSessionService/CreateSession",
+		FullMethod: "/hobord.session.DSessionAdminService/StartBulkInvalidation",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DSessionServiceServer).CreateSession(ctx, req.(*CreateSessionMessage))
+		return srv.(DSessionAdminServiceServer).StartBulkInvalidation(ctx, req.(*BulkInvalidationMessage))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DSessionService_AddValueToSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AddValueToSessionMessage)
+func _DSessionAdminService_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobMessage)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DSessionServiceServer).AddValueToSession(ctx, in)
+		return srv.(DSessionAdminServiceServer).GetJob(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/hobord.session.DSessionService/AddValueToSession",
+		FullMethod: "/hobord.session.DSessionAdminService/GetJob",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DSessionServiceServer).AddValueToSession(ctx, req.(*AddValueToSessionMessage))
+		return srv.(DSessionAdminServiceServer).GetJob(ctx, req.(*GetJobMessage))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DSessionService_AddValuesToSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AddValuesToSessionMessage)
+func _DSessionAdminService_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeEventsMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DSessionAdminServiceServer).SubscribeEvents(m, &dSessionAdminServiceSubscribeEventsServer{stream})
+}
+
+type DSessionAdminService_SubscribeEventsServer interface {
+	Send(*EventRecord) error
+	grpc.ServerStream
+}
+
+type dSessionAdminServiceSubscribeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *dSessionAdminServiceSubscribeEventsServer) Send(m *EventRecord) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DSessionAdminService_AckEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckEventsMessage)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DSessionServiceServer).AddValuesToSession(ctx, in)
+		return srv.(DSessionAdminServiceServer).AckEvents(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/hobord.session.DSessionService/AddValuesToSession",
+		FullMethod: "/hobord.session.DSessionAdminService/AckEvents",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DSessionServiceServer).AddValuesToSession(ctx, req.(*AddValuesToSessionMessage))
+		return srv.(DSessionAdminServiceServer).AckEvents(ctx, req.(*AckEventsMessage))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DSessionService_InvalidateSessionValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(InvalidateSessionValueMessage)
+func _DSessionAdminService_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsMessage)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DSessionServiceServer).InvalidateSessionValue(ctx, in)
+		return srv.(DSessionAdminServiceServer).ListSessions(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/hobord.session.DSessionService/InvalidateSessionValue",
+		FullMethod: "/hobord.session.DSessionAdminService/ListSessions",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DSessionServiceServer).InvalidateSessionValue(ctx, req.(*InvalidateSessionValueMessage))
+		return srv.(DSessionAdminServiceServer).ListSessions(ctx, req.(*ListSessionsMessage))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DSessionService_InvalidateSessionValues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(InvalidateSessionValuesMessage)
+func _DSessionAdminService_ExportSessions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportSessionsMessage)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DSessionAdminServiceServer).ExportSessions(m, &dSessionAdminServiceExportSessionsServer{stream})
+}
+
+type DSessionAdminService_ExportSessionsServer interface {
+	Send(*ExportedSession) error
+	grpc.ServerStream
+}
+
+type dSessionAdminServiceExportSessionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *dSessionAdminServiceExportSessionsServer) Send(m *ExportedSession) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DSessionAdminService_ImportSessions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DSessionAdminServiceServer).ImportSessions(&dSessionAdminServiceImportSessionsServer{stream})
+}
+
+type DSessionAdminService_ImportSessionsServer interface {
+	SendAndClose(*ImportSessionsResponse) error
+	Recv() (*ImportSessionMessage, error)
+	grpc.ServerStream
+}
+
+type dSessionAdminServiceImportSessionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *dSessionAdminServiceImportSessionsServer) SendAndClose(m *ImportSessionsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dSessionAdminServiceImportSessionsServer) Recv() (*ImportSessionMessage, error) {
+	m := new(ImportSessionMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _DSessionAdminService_StartReencryption_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReencryptionMessage)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DSessionServiceServer).InvalidateSessionValues(ctx, in)
+		return srv.(DSessionAdminServiceServer).StartReencryption(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/hobord.session.DSessionService/InvalidateSessionValues",
+		FullMethod: "/hobord.session.DSessionAdminService/StartReencryption",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DSessionServiceServer).InvalidateSessionValues(ctx, req.(*InvalidateSessionValuesMessage))
+		return srv.(DSessionAdminServiceServer).StartReencryption(ctx, req.(*ReencryptionMessage))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _DSessionService_InvalidateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(InvalidateSessionMessage)
+func _DSessionAdminService_QueryAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditQueryMessage)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DSessionServiceServer).InvalidateSession(ctx, in)
+		return srv.(DSessionAdminServiceServer).QueryAuditLog(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/hobord.session.DSessionService/InvalidateSession",
+		FullMethod: "/hobord.session.DSessionAdminService/QueryAuditLog",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DSessionServiceServer).InvalidateSession(ctx, req.(*InvalidateSessionMessage))
+		return srv.(DSessionAdminServiceServer).QueryAuditLog(ctx, req.(*AuditQueryMessage))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _DSessionService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "hobord.session.DSessionService",
-	HandlerType: (*DSessionServiceServer)(nil),
+var _DSessionAdminService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "hobord.session.DSessionAdminService",
+	HandlerType: (*DSessionAdminServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "GetSession",
-			Handler:    _DSessionService_GetSession_Handler,
+			MethodName: "StartBulkInvalidation",
+			Handler:    _DSessionAdminService_StartBulkInvalidation_Handler,
 		},
 		{
-			MethodName: "CreateSession",
-			Handler:    _DSessionService_CreateSession_Handler,
+			MethodName: "GetJob",
+			Handler:    _DSessionAdminService_GetJob_Handler,
 		},
 		{
-			MethodName: "AddValueToSession",
-			Handler:    _DSessionService_AddValueToSession_Handler,
+			MethodName: "AckEvents",
+			Handler:    _DSessionAdminService_AckEvents_Handler,
 		},
 		{
-			MethodName: "AddValuesToSession",
-			Handler:    _DSessionService_AddValuesToSession_Handler,
+			MethodName: "ListSessions",
+			Handler:    _DSessionAdminService_ListSessions_Handler,
 		},
 		{
-			MethodName: "InvalidateSessionValue",
-			Handler:    _DSessionService_InvalidateSessionValue_Handler,
+			MethodName: "StartReencryption",
+			Handler:    _DSessionAdminService_StartReencryption_Handler,
 		},
 		{
-			MethodName: "InvalidateSessionValues",
-			Handler:    _DSessionService_InvalidateSessionValues_Handler,
+			MethodName: "QueryAuditLog",
+			Handler:    _DSessionAdminService_QueryAuditLog_Handler,
 		},
+	},
+	Streams: []grpc.StreamDesc{
 		{
-			MethodName: "InvalidateSession",
-			Handler:    _DSessionService_InvalidateSession_Handler,
+			StreamName:    "SubscribeEvents",
+			Handler:       _DSessionAdminService_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportSessions",
+			Handler:       _DSessionAdminService_ExportSessions_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportSessions",
+			Handler:       _DSessionAdminService_ImportSessions_Handler,
+			ClientStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "session.proto",
 }
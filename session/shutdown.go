@@ -0,0 +1,36 @@
+package session
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errDraining = errors.New("Server is shutting down")
+
+// drainedError ends the streaming calls on shutdown, the clients reconnect to another replica
+var drainedError = status.Error(codes.Unavailable, "Server is shutting down, reconnect")
+
+// Drain ends the watch and the event streams with Unavailable and fails the readiness checks,
+// the unary calls are still served until the gRPC server stops
+func (s *GrpcRedisImplServer) Drain() {
+	s.drainOnce.Do(func() {
+		close(s.draining)
+	})
+}
+
+// isDraining reports whether Drain was called
+func (s *GrpcRedisImplServer) isDraining() bool {
+	select {
+	case <-s.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close closes the redis pool, call it after the gRPC server stopped
+func (s *GrpcRedisImplServer) Close() error {
+	return s.RedisPool.Close()
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// existsConn answers EXISTS with 1
+type existsConn struct{ pingConn }
+
+func (c *existsConn) Do(string, ...interface{}) (interface{}, error) { return int64(1), nil }
+
+// watchStream is a server stream of WatchSession without a client
+type watchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header chan struct{}
+}
+
+func (w *watchStream) Context() context.Context     { return w.ctx }
+func (w *watchStream) SendHeader(metadata.MD) error { close(w.header); return nil }
+func (w *watchStream) Send(*SessionEvent) error     { return nil }
+
+func TestDrain(t *testing.T) {
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return &existsConn{}, nil }}
+	s := &GrpcRedisImplServer{
+		RedisPool: pool,
+		watchers:  newTestWatchHub(),
+		tokens:    &tokenCodec{},
+		draining:  make(chan struct{}),
+	}
+	s.health.checked = true
+
+	stream := &watchStream{ctx: context.Background(), header: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WatchSession(&WatchSessionMessage{Id: testID}, stream)
+	}()
+	<-stream.header
+
+	if err := s.Ready(); err != nil {
+		t.Errorf("Got unexpected error: %v", err)
+	}
+	s.Drain()
+	s.Drain()
+	select {
+	case err := <-done:
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("Got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Watch is not ended by the drain")
+	}
+	if err := s.Ready(); err == nil {
+		t.Errorf("Ready while draining")
+	}
+}
@@ -0,0 +1,117 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	uuid "github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tokenVersion is the prefix of the signed session tokens
+const tokenVersion = "v1"
+
+var errInvalidToken = status.Error(codes.InvalidArgument, "Invalid session id")
+
+// tokenCodec turns the session ids into signed tokens and back.
+// A token is v1.<kid>.<uuid>.<mac>, the mac is the HMAC-SHA256 of v1.<kid>.<uuid>
+// with the key of kid. Without keys the raw ids are used.
+type tokenCodec struct {
+	keys map[string][]byte
+	// current is the kid of the signing key, the other keys only verify
+	current string
+	// requireSigned rejects the raw ids
+	requireSigned bool
+}
+
+// newTokenCodec parses the kid=base64key list, the first key signs the new tokens
+func newTokenCodec(spec string, requireSigned bool) (*tokenCodec, error) {
+	t := &tokenCodec{keys: make(map[string][]byte), requireSigned: requireSigned}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		i := strings.Index(item, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("Invalid token key %q, want kid=base64key", item)
+		}
+		kid := item[:i]
+		if strings.ContainsAny(kid, ". ") {
+			return nil, fmt.Errorf("Invalid token key id %q", kid)
+		}
+		key, err := base64.StdEncoding.DecodeString(item[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid token key %s: %s", kid, err)
+		}
+		if len(key) < 32 {
+			return nil, fmt.Errorf("Token key %s is shorter than 32 bytes", kid)
+		}
+		if t.current == "" {
+			t.current = kid
+		}
+		t.keys[kid] = key
+	}
+	if requireSigned && t.current == "" {
+		return nil, fmt.Errorf("Signed session tokens are required but no key is given")
+	}
+	return t, nil
+}
+
+func (t *tokenCodec) mac(kid, id string) string {
+	h := hmac.New(sha256.New, t.keys[kid])
+	h.Write([]byte(tokenVersion + "." + kid + "." + id))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// sign returns the token of the session id
+func (t *tokenCodec) sign(id string) string {
+	if t == nil || t.current == "" {
+		return id
+	}
+	return tokenVersion + "." + t.current + "." + id + "." + t.mac(t.current, id)
+}
+
+// verify returns the session id of the token, it does not touch redis
+func (t *tokenCodec) verify(token string) (string, error) {
+	if !strings.HasPrefix(token, tokenVersion+".") {
+		if t != nil && t.requireSigned {
+			return "", errInvalidToken
+		}
+		if !validSessionID(token) {
+			return "", errInvalidToken
+		}
+		return token, nil
+	}
+	if t == nil {
+		return "", errInvalidToken
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return "", errInvalidToken
+	}
+	kid, id, mac := parts[1], parts[2], parts[3]
+	if _, ok := t.keys[kid]; !ok || !validSessionID(id) {
+		return "", errInvalidToken
+	}
+	if !hmac.Equal([]byte(mac), []byte(t.mac(kid, id))) {
+		return "", errInvalidToken
+	}
+	return id, nil
+}
+
+// SessionID returns the session id of a session token or of a raw id, it does not touch redis
+func (s *GrpcRedisImplServer) SessionID(token string) (string, error) {
+	return s.tokens.verify(token)
+}
+
+// validSessionID reports whether id is a session id in its canonical uuid form
+func validSessionID(id string) bool {
+	parsed, err := uuid.Parse(id)
+	return err == nil && parsed.String() == id
+}
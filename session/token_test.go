@@ -0,0 +1,105 @@
+package session
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+const testID = "8f60aaef-a0bd-4c55-ab49-00c4ed5a4091"
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32)))
+}
+
+func TestTokenSignVerify(t *testing.T) {
+	codec, err := newTokenCodec("k2="+testKey('b')+",k1="+testKey('a'), false)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	old, _ := newTokenCodec("k1="+testKey('a'), false)
+
+	token := codec.sign(testID)
+	if !strings.HasPrefix(token, "v1.k2."+testID+".") {
+		t.Fatalf("Got token %s", token)
+	}
+	mac := token[strings.LastIndex(token, ".")+1:]
+	tampered := strings.Replace(token, "8f60", "9f60", 1)
+
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{token, testID},
+		{old.sign(testID), testID},
+		{testID, testID},
+		{tampered, ""},
+		{token[:len(token)-1] + "A", ""},
+		{"v1.k3." + testID + "." + mac, ""},
+		{"v1.k2." + testID, ""},
+		{"v1.k2.foo." + mac, ""},
+		{"foo", ""},
+		{"*", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := codec.verify(tt.token)
+		if got != tt.want || (tt.want == "" && err == nil) {
+			t.Errorf("%q: Got %q, %v", tt.token, got, err)
+		}
+	}
+}
+
+func TestTokenRequireSigned(t *testing.T) {
+	codec, err := newTokenCodec("k1="+testKey('a'), true)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if _, err := codec.verify(testID); err == nil {
+		t.Errorf("Raw id is accepted")
+	}
+	if got, err := codec.verify(codec.sign(testID)); got != testID || err != nil {
+		t.Errorf("Got %q, %v", got, err)
+	}
+
+	if _, err := newTokenCodec("", true); err == nil {
+		t.Errorf("Required tokens without key is accepted")
+	}
+}
+
+func TestTokenWithoutKeys(t *testing.T) {
+	codec, _ := newTokenCodec("", false)
+	if got := codec.sign(testID); got != testID {
+		t.Errorf("Got %s", got)
+	}
+	if _, err := codec.verify("v1.k1." + testID + ".mac"); err == nil {
+		t.Errorf("Token is accepted without keys")
+	}
+}
+
+func TestNewTokenCodecErrors(t *testing.T) {
+	for _, spec := range []string{"k1", "=" + testKey('a'), "k.1=" + testKey('a'), "k1=!!", "k1=" + base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := newTokenCodec(spec, false); err == nil {
+			t.Errorf("%q: Got no error", spec)
+		}
+	}
+}
+
+func TestIdsLeaveSigned(t *testing.T) {
+	codec, _ := newTokenCodec("k1="+testKey('a'), false)
+	s := &GrpcRedisImplServer{tokens: codec}
+	token := codec.sign(testID)
+
+	ev := &SessionEvent{Type: SessionEventType_SESSION_CREATED, Id: testID}
+	if got := s.signedEvent(ev); got.Id != token {
+		t.Errorf("Got event id %s, want the token", got.Id)
+	}
+	if ev.Id != testID {
+		t.Errorf("Shared event is changed")
+	}
+
+	stored := &storedSession{ID: testID}
+	if got := stored.summary(codec, 60); got.Id != token {
+		t.Errorf("Got summary id %s, want the token", got.Id)
+	}
+}
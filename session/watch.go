@@ -0,0 +1,258 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/hobord/dsession/logging"
+)
+
+const (
+	// watchChannelPrefix + session id is the pub/sub channel of the session events
+	watchChannelPrefix = "dsession:session:"
+	// expiredChannelFormat + db is the channel of the expired keys in the redis keyspace notifications
+	expiredChannelFormat = "__keyevent@%d__:expired"
+	// watchBuffer is the number of events queued for one watcher
+	watchBuffer = 64
+)
+
+// watchHub keeps one pub/sub connection per process and fans the events out to the watchers
+type watchHub struct {
+	pool     *redis.Pool
+	db       int
+	once     sync.Once
+	mu       sync.Mutex
+	watchers map[string]map[chan *SessionEvent]struct{}
+	// onExpired is called with the expiry events before they are dispatched
+	onExpired func(ev *SessionEvent)
+	// configureNotifications lets the hub change notify-keyspace-events of the server,
+	// otherwise the setting is only checked
+	configureNotifications bool
+	// values opens the sealed events
+	values *valueCipher
+}
+
+func newWatchHub(pool *redis.Pool, db int) *watchHub {
+	return &watchHub{
+		pool:     pool,
+		db:       db,
+		watchers: make(map[string]map[chan *SessionEvent]struct{}),
+	}
+}
+
+// start runs the pub/sub subscription in the background once
+func (h *watchHub) start() {
+	h.once.Do(func() { go h.run() })
+}
+
+// subscribe registers a watcher of the session, the returned func removes it
+func (h *watchHub) subscribe(id string) (<-chan *SessionEvent, func()) {
+	h.start()
+
+	ch := make(chan *SessionEvent, watchBuffer)
+	h.mu.Lock()
+	if h.watchers[id] == nil {
+		h.watchers[id] = make(map[chan *SessionEvent]struct{})
+	}
+	h.watchers[id][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() { h.remove(id, ch) }
+}
+
+func (h *watchHub) remove(id string, ch chan *SessionEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.watchers[id][ch]; !ok {
+		return
+	}
+	delete(h.watchers[id], ch)
+	if len(h.watchers[id]) == 0 {
+		delete(h.watchers, id)
+	}
+	close(ch)
+}
+
+// dispatch delivers the event to the watchers of the session, watchers which fell behind are dropped
+func (h *watchHub) dispatch(ev *SessionEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.watchers[ev.Id] {
+		select {
+		case ch <- ev:
+		default:
+			delete(h.watchers[ev.Id], ch)
+			close(ch)
+		}
+	}
+	if len(h.watchers[ev.Id]) == 0 {
+		delete(h.watchers, ev.Id)
+	}
+}
+
+// run receives the published events and the expiry notifications, reconnecting on failure
+func (h *watchHub) run() {
+	backoff := time.Second
+	for {
+		start := time.Now()
+		err := h.receive()
+		logging.Error(context.Background(), "Session watch subscription lost", "error", err)
+		if time.Since(start) > time.Minute {
+			backoff = time.Second
+		}
+		time.Sleep(backoff)
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (h *watchHub) receive() error {
+	conn := h.pool.Get()
+	defer conn.Close()
+	checkExpiryNotifications(conn, h.configureNotifications)
+
+	psc := redis.PubSubConn{Conn: conn}
+	if err := psc.PSubscribe(watchChannelPrefix+"*", fmt.Sprintf(expiredChannelFormat, h.db)); err != nil {
+		return err
+	}
+
+	for {
+		// the subscription waits for the next message without the read timeout of the pool
+		switch msg := psc.ReceiveWithTimeout(0).(type) {
+		case redis.Message:
+			ev := parseWatchMessage(h.values, msg)
+			if ev == nil {
+				continue
+			}
+			if ev.Type == SessionEventType_SESSION_EXPIRED && h.onExpired != nil {
+				h.onExpired(ev)
+			}
+			h.dispatch(ev)
+		case error:
+			return msg
+		}
+	}
+}
+
+func parseWatchMessage(values *valueCipher, msg redis.Message) *SessionEvent {
+	if strings.HasPrefix(msg.Channel, "__keyevent@") {
+		// the rate limit buckets, the jobs and the expiry shadow keys expire too
+		if !validSessionID(string(msg.Data)) {
+			return nil
+		}
+		return &SessionEvent{
+			Type: SessionEventType_SESSION_EXPIRED,
+			Id:   string(msg.Data),
+			Time: nowMillis(),
+		}
+	}
+
+	ev, err := parseEvent(values, msg.Data)
+	if err != nil {
+		logging.Warn(context.Background(), "Invalid session event", "channel", msg.Channel, "error", err)
+		return nil
+	}
+	return ev
+}
+
+// requiredNotifyFlags are the notify-keyspace-events flags of the expiry events:
+// the keyevent notifications (E) of the expired keys (x)
+const requiredNotifyFlags = "Ex"
+
+// checkExpiryNotifications warns when the keyevent notifications of the expired keys are off.
+// The server setting is changed only when configure is set (REDIS_CONFIGURE_NOTIFICATIONS),
+// keeping the flags which are already set, as the redis server may be shared.
+func checkExpiryNotifications(conn redis.Conn, configure bool) {
+	res, err := redis.Strings(conn.Do("CONFIG", "GET", "notify-keyspace-events"))
+	if err != nil || len(res) != 2 {
+		// managed servers often reject CONFIG, the setting is documented instead
+		logging.Info(context.Background(), "Cannot read notify-keyspace-events, it must contain "+requiredNotifyFlags+" for the expiry events", "error", err)
+		return
+	}
+	flags := res[1]
+	if strings.ContainsRune(flags, 'E') && (strings.ContainsRune(flags, 'x') || strings.ContainsRune(flags, 'A')) {
+		return
+	}
+	if !configure {
+		logging.Warn(context.Background(), "Expiry notifications are off, the expiry events are missing: set notify-keyspace-events "+requiredNotifyFlags+" on the redis server",
+			"notify-keyspace-events", flags)
+		return
+	}
+	if !strings.ContainsRune(flags, 'E') {
+		flags += "E"
+	}
+	if !strings.ContainsRune(flags, 'x') && !strings.ContainsRune(flags, 'A') {
+		flags += "x"
+	}
+	if _, err := conn.Do("CONFIG", "SET", "notify-keyspace-events", flags); err != nil {
+		logging.Warn(context.Background(), "Failed to enable expiry notifications, expiry events may be missing", "error", err)
+		return
+	}
+	logging.Info(context.Background(), "Enabled the expiry notifications", "notify-keyspace-events", flags)
+}
+
+// WatchSession streams the changes of the session until it expires or is invalidated
+func (s *GrpcRedisImplServer) WatchSession(in *WatchSessionMessage, stream DSessionService_WatchSessionServer) error {
+	id, err := s.tokens.verify(in.Id)
+	if err != nil {
+		return err
+	}
+	conn := s.getConn(stream.Context())
+	err = authorizeSession(stream.Context(), conn, id)
+	conn.Close()
+	if err != nil {
+		return err
+	}
+	events, cancel := s.watchers.subscribe(id)
+	defer cancel()
+
+	exists, err := s.sessionExists(id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return status.Errorf(codes.NotFound, "Session %s not found", in.Id)
+	}
+	// tell the client that the watch is set up
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-s.draining:
+			return drainedError
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "Watcher fell behind the session events")
+			}
+			visible := visibleEvent(stream.Context(), ev)
+			if visible == nil {
+				continue
+			}
+			if err := stream.Send(s.signedEvent(visible)); err != nil {
+				return err
+			}
+			if ev.Type == SessionEventType_SESSION_EXPIRED || ev.Type == SessionEventType_SESSION_INVALIDATED {
+				return nil
+			}
+		}
+	}
+}
+
+func (s *GrpcRedisImplServer) sessionExists(id string) (bool, error) {
+	conn := s.RedisPool.Get()
+	defer conn.Close()
+	return redis.Bool(conn.Do("EXISTS", id))
+}
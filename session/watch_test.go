@@ -0,0 +1,111 @@
+package session
+
+import (
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/gomodule/redigo/redis"
+)
+
+func newTestWatchHub() *watchHub {
+	h := newWatchHub(&redis.Pool{}, 0)
+	// do not start the redis subscription
+	h.once.Do(func() {})
+	return h
+}
+
+func TestWatchHubDispatch(t *testing.T) {
+	h := newTestWatchHub()
+	events, cancel := h.subscribe("a")
+	defer cancel()
+	other, cancelOther := h.subscribe("b")
+	defer cancelOther()
+
+	h.dispatch(&SessionEvent{Type: SessionEventType_VALUES_CHANGED, Id: "a"})
+
+	select {
+	case ev := <-events:
+		if ev.Id != "a" {
+			t.Errorf("Got event of %s", ev.Id)
+		}
+	default:
+		t.Errorf("Event was not delivered")
+	}
+	select {
+	case ev := <-other:
+		t.Errorf("Unexpected event %v", ev)
+	default:
+	}
+}
+
+func TestWatchHubDropsSlowWatcher(t *testing.T) {
+	h := newTestWatchHub()
+	events, cancel := h.subscribe("a")
+	defer cancel()
+
+	for i := 0; i <= watchBuffer; i++ {
+		h.dispatch(&SessionEvent{Type: SessionEventType_VALUES_CHANGED, Id: "a"})
+	}
+
+	n := 0
+	for range events {
+		n++
+	}
+	if n != watchBuffer {
+		t.Errorf("Got %d events, want %d", n, watchBuffer)
+	}
+}
+
+func TestParseWatchMessage(t *testing.T) {
+	ev := parseWatchMessage(nil, redis.Message{Channel: "__keyevent@0__:expired", Data: []byte(testID)})
+	if ev.Type != SessionEventType_SESSION_EXPIRED || ev.Id != testID {
+		t.Errorf("Got %v", ev)
+	}
+	for _, key := range []string{"a", expiryKeyPrefix + testID, jobKeyPrefix + testID, "dsession:ratelimit:session:" + testID} {
+		if ev := parseWatchMessage(nil, redis.Message{Channel: "__keyevent@0__:expired", Data: []byte(key)}); ev != nil {
+			t.Errorf("Expiry of %s got %v", key, ev)
+		}
+	}
+
+	data, _ := proto.Marshal(&SessionEvent{Type: SessionEventType_VALUES_DELETED, Id: "b", Keys: []string{"foo"}})
+	ev = parseWatchMessage(nil, redis.Message{Channel: watchChannelPrefix + "b", Data: data})
+	if ev.Type != SessionEventType_VALUES_DELETED || ev.Id != "b" || len(ev.Keys) != 1 {
+		t.Errorf("Got %v", ev)
+	}
+}
+
+// configConn answers CONFIG GET notify-keyspace-events and records CONFIG SET
+type configConn struct {
+	pingConn
+	flags string
+	set   []string
+}
+
+func (c *configConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if args[0] == "SET" {
+		c.set = append(c.set, args[2].(string))
+		return "OK", nil
+	}
+	return []interface{}{[]byte("notify-keyspace-events"), []byte(c.flags)}, nil
+}
+
+func TestCheckExpiryNotifications(t *testing.T) {
+	tests := []struct {
+		flags     string
+		configure bool
+		want      []string
+	}{
+		{"", false, nil},
+		{"", true, []string{"Ex"}},
+		{"Kg", true, []string{"KgEx"}},
+		{"Ex", true, nil},
+		{"AKE", true, nil},
+	}
+	for _, tt := range tests {
+		conn := &configConn{flags: tt.flags}
+		checkExpiryNotifications(conn, tt.configure)
+		if len(conn.set) != len(tt.want) || (len(tt.want) > 0 && conn.set[0] != tt.want[0]) {
+			t.Errorf("%q %v: Got CONFIG SET %v, want %v", tt.flags, tt.configure, conn.set, tt.want)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/hobord/dsession/logging"
+)
+
+const (
+	// webhookGroupPrefix + webhook name is the consumer group of the webhook on the event stream
+	webhookGroupPrefix = "webhook:"
+	// deadLetterPrefix + webhook name is the list of the undeliverable events
+	deadLetterPrefix = "dsession:webhooks:dead:"
+	deadLetterMaxLen = 10000
+	// webhookClaimIdle is the idle time after a pending delivery of another replica is taken over
+	webhookClaimIdle = 5 * time.Minute
+)
+
+// Webhook is an HTTP endpoint which receives the session lifecycle events
+type Webhook struct {
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret"`       // HMAC-SHA256 key of the signature header
+	Events      []string `json:"events"`       // SessionEventType names, empty = all
+	Namespaces  []string `json:"namespaces"`   // empty = all
+	MaxAttempts int      `json:"max_attempts"` // deliveries before the event goes to the dead letters
+	BackoffMs   int      `json:"backoff_ms"`   // wait before the first retry, doubled after each attempt
+	TimeoutMs   int      `json:"timeout_ms"`   // timeout of one delivery
+
+	events     map[SessionEventType]bool
+	namespaces map[string]bool
+	client     *http.Client
+}
+
+// LoadWebhooks reads the webhook definitions from a JSON file
+func LoadWebhooks(path string) ([]*Webhook, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []*Webhook
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("Invalid webhooks config %s: %s", path, err)
+	}
+	names := make(map[string]bool)
+	for _, w := range hooks {
+		if err := w.init(); err != nil {
+			return nil, err
+		}
+		if names[w.Name] {
+			return nil, fmt.Errorf("Duplicated webhook name: %s", w.Name)
+		}
+		names[w.Name] = true
+	}
+	return hooks, nil
+}
+
+func (w *Webhook) init() error {
+	if w.Name == "" || w.URL == "" {
+		return fmt.Errorf("Webhook name and url are required")
+	}
+	if w.MaxAttempts <= 0 {
+		w.MaxAttempts = 5
+	}
+	if w.BackoffMs <= 0 {
+		w.BackoffMs = 1000
+	}
+	if w.TimeoutMs <= 0 {
+		w.TimeoutMs = 10000
+	}
+	w.events = make(map[SessionEventType]bool)
+	for _, name := range w.Events {
+		t, ok := SessionEventType_value[name]
+		if !ok {
+			return fmt.Errorf("Webhook %s: unknown event type %s", w.Name, name)
+		}
+		w.events[SessionEventType(t)] = true
+	}
+	w.namespaces = make(map[string]bool)
+	for _, ns := range w.Namespaces {
+		w.namespaces[ns] = true
+	}
+	w.client = &http.Client{Timeout: time.Duration(w.TimeoutMs) * time.Millisecond}
+	return nil
+}
+
+// accepts reports whether the event passes the filters of the webhook
+func (w *Webhook) accepts(ev *SessionEvent) bool {
+	if len(w.events) > 0 && !w.events[ev.Type] {
+		return false
+	}
+	if len(w.namespaces) > 0 && !w.namespaces[ev.Namespace] {
+		return false
+	}
+	return true
+}
+
+// signature is the hex HMAC-SHA256 of "timestamp.body"
+func signature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// permanentError is a delivery failure which is not retried
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+// post sends one delivery of the event
+func (w *Webhook) post(id string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-DSession-Event-Id", id)
+	req.Header.Set("X-DSession-Webhook", w.Name)
+	req.Header.Set("X-DSession-Timestamp", strconv.FormatInt(timestamp, 10))
+	if w.Secret != "" {
+		req.Header.Set("X-DSession-Signature", "sha256="+signature(w.Secret, timestamp, body))
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return nil
+	case res.StatusCode >= 500, res.StatusCode == http.StatusTooManyRequests, res.StatusCode == http.StatusRequestTimeout:
+		return fmt.Errorf("Webhook %s responded %s", w.Name, res.Status)
+	default:
+		return permanentError{fmt.Errorf("Webhook %s responded %s", w.Name, res.Status)}
+	}
+}
+
+// deliver posts the event until it succeeds or the attempts run out, it returns the last error.
+// The retries stop with errDraining when stop is closed, the event is left pending.
+func (w *Webhook) deliver(id string, body []byte, stop <-chan struct{}) (int, error) {
+	backoff := time.Duration(w.BackoffMs) * time.Millisecond
+	var err error
+	for attempt := 1; attempt <= w.MaxAttempts; attempt++ {
+		err = w.post(id, body)
+		if err == nil {
+			return attempt, nil
+		}
+		if _, ok := err.(permanentError); ok || attempt == w.MaxAttempts {
+			return attempt, err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-stop:
+			return attempt, errDraining
+		}
+		backoff *= 2
+	}
+	return w.MaxAttempts, err
+}
+
+// deadLetter is an undeliverable event stored in the dead letter list of the webhook
+type deadLetter struct {
+	EventID  string          `json:"event_id"`
+	Payload  json.RawMessage `json:"payload"`
+	Error    string          `json:"error"`
+	Attempts int             `json:"attempts"`
+	FailedAt int64           `json:"failed_at"`
+}
+
+// StartWebhooks starts delivering the session events to the webhooks.
+// Every webhook reads the event stream in its own consumer group, so the replicas share the deliveries.
+func (s *GrpcRedisImplServer) StartWebhooks(hooks []*Webhook) {
+	consumer, err := os.Hostname()
+	if err != nil || consumer == "" {
+		consumer = "dsession"
+	}
+	for _, w := range hooks {
+		go s.runWebhook(w, consumer)
+	}
+}
+
+// runWebhook consumes the events until the drain, restarting the consumer after the failures
+func (s *GrpcRedisImplServer) runWebhook(w *Webhook, consumer string) {
+	for {
+		err := s.consumeWebhook(w, consumer)
+		if s.isDraining() {
+			return
+		}
+		logging.Error(context.Background(), "Webhook stopped", "webhook", w.Name, "error", err)
+		select {
+		case <-time.After(5 * time.Second):
+		case <-s.draining:
+			return
+		}
+	}
+}
+
+func (s *GrpcRedisImplServer) consumeWebhook(w *Webhook, consumer string) error {
+	conn := s.RedisPool.Get()
+	defer conn.Close()
+
+	group := webhookGroupPrefix + w.Name
+	if err := createEventGroup(conn, group, "$"); err != nil {
+		return err
+	}
+
+	// the pending deliveries of a previous run come first
+	lastID := "0"
+	for !s.isDraining() {
+		entries, err := readEvents(conn, group, consumer, lastID)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			lastID = ">"
+			claimed, err := claimStaleEvents(conn, group, consumer)
+			if err != nil {
+				return err
+			}
+			if claimed > 0 {
+				lastID = "0"
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			if lastID != ">" {
+				lastID = entry.ID
+			}
+			if err := s.handleWebhookEntry(conn, w, entry); err != nil {
+				return err
+			}
+			if _, err := conn.Do("XACK", eventStream, group, entry.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return errDraining
+}
+
+func (s *GrpcRedisImplServer) handleWebhookEntry(conn redis.Conn, w *Webhook, entry streamEntry) error {
+	ev := s.decodeEvent(entry)
+	if ev == nil || !w.accepts(ev) {
+		return nil
+	}
+
+	body, err := (&jsonpb.Marshaler{}).MarshalToString(&EventRecord{StreamId: entry.ID, Event: s.signedEvent(ev)})
+	if err != nil {
+		return err
+	}
+	attempts, deliverErr := w.deliver(entry.ID, []byte(body), s.draining)
+	if deliverErr == nil {
+		return nil
+	}
+	if deliverErr == errDraining {
+		// another replica delivers it after the claim idle time
+		return deliverErr
+	}
+
+	logging.Warn(context.Background(), "Webhook failed to deliver the event", "webhook", w.Name, "event", entry.ID, "attempts", attempts, "error", deliverErr)
+	letter, err := json.Marshal(&deadLetter{
+		EventID:  entry.ID,
+		Payload:  json.RawMessage(body),
+		Error:    deliverErr.Error(),
+		Attempts: attempts,
+		FailedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	conn.Send("LPUSH", deadLetterPrefix+w.Name, letter)
+	conn.Send("LTRIM", deadLetterPrefix+w.Name, 0, deadLetterMaxLen-1)
+	_, err = conn.Do("")
+	return err
+}
+
+// claimStaleEvents takes over the deliveries which another consumer of the group left pending
+// and returns their number, they are read again with the pending entries of the consumer
+func claimStaleEvents(conn redis.Conn, group, consumer string) (int, error) {
+	pending, err := redis.Values(conn.Do("XPENDING", eventStream, group, "-", "+", eventReadCount))
+	if err != nil {
+		return 0, err
+	}
+	args := redis.Args{}.Add(eventStream, group, consumer, int64(webhookClaimIdle/time.Millisecond))
+	var ids []string
+	for _, p := range pending {
+		fields, err := redis.Values(p, nil)
+		if err != nil || len(fields) != 4 {
+			continue
+		}
+		owner, _ := redis.String(fields[1], nil)
+		idle, _ := redis.Int64(fields[2], nil)
+		if owner != consumer && idle >= int64(webhookClaimIdle/time.Millisecond) {
+			id, _ := redis.String(fields[0], nil)
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	_, err = conn.Do("XCLAIM", args.AddFlat(ids).Add("JUSTID")...)
+	return len(ids), err
+}
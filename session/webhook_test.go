@@ -0,0 +1,127 @@
+package session
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestWebhook(t *testing.T, url string) *Webhook {
+	w := &Webhook{
+		Name:        "test",
+		URL:         url,
+		Secret:      "secret",
+		Events:      []string{"SESSION_EXPIRED"},
+		Namespaces:  []string{"shop"},
+		MaxAttempts: 3,
+		BackoffMs:   1,
+	}
+	if err := w.init(); err != nil {
+		t.Fatalf("init got unexpected error: %v", err)
+	}
+	return w
+}
+
+func TestWebhookAccepts(t *testing.T) {
+	w := newTestWebhook(t, "http://localhost")
+
+	tests := []struct {
+		ev   *SessionEvent
+		want bool
+	}{
+		{&SessionEvent{Type: SessionEventType_SESSION_EXPIRED, Namespace: "shop"}, true},
+		{&SessionEvent{Type: SessionEventType_SESSION_EXPIRED, Namespace: "blog"}, false},
+		{&SessionEvent{Type: SessionEventType_VALUES_CHANGED, Namespace: "shop"}, false},
+	}
+	for _, tt := range tests {
+		if got := w.accepts(tt.ev); got != tt.want {
+			t.Errorf("accepts(%v) = %v, want %v", tt.ev, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookDeliverSigned(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get("X-DSession-Timestamp"), 10, 64)
+		if r.Header.Get("X-DSession-Signature") != "sha256="+signature("secret", ts, body) {
+			t.Errorf("Invalid signature")
+		}
+		if r.Header.Get("X-DSession-Event-Id") != "1-0" {
+			t.Errorf("Got event id %s", r.Header.Get("X-DSession-Event-Id"))
+		}
+	}))
+	defer srv.Close()
+
+	attempts, err := newTestWebhook(t, srv.URL).deliver("1-0", []byte(`{}`), nil)
+	if err != nil {
+		t.Fatalf("deliver got unexpected error: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Got %d attempts, want 2", attempts)
+	}
+}
+
+func TestWebhookDeliverPermanentFailure(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	_, err := newTestWebhook(t, srv.URL).deliver("1-0", []byte(`{}`), nil)
+	if err == nil {
+		t.Fatalf("deliver expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("Got %d calls, a client error must not be retried", calls)
+	}
+}
+
+func TestWebhookDeliverStops(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	w := newTestWebhook(t, srv.URL)
+	w.BackoffMs = int(time.Hour / time.Millisecond)
+	stop := make(chan struct{})
+	close(stop)
+	start := time.Now()
+	attempts, err := w.deliver("1-0", []byte(`{}`), stop)
+	if err != errDraining || attempts != 1 || calls != 1 {
+		t.Errorf("Got %d attempts, %d calls, %v", attempts, calls, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Backoff is not interrupted")
+	}
+}
+
+func TestRunWebhookStopsOnDrain(t *testing.T) {
+	_, pool := newTestRedis(t)
+	s := &GrpcRedisImplServer{RedisPool: pool, draining: make(chan struct{})}
+	s.Drain()
+	done := make(chan struct{})
+	go func() {
+		s.runWebhook(newTestWebhook(t, "http://localhost:1"), "test")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("Webhook runs after the drain")
+	}
+}
@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedisConn records a client span of every command of the connection, in the trace of the context.
+// The commands queued with Send are recorded with the Do("") which flushes them, as a pipeline.
+func RedisConn(ctx context.Context, c redis.Conn) redis.Conn {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return c
+	}
+	return &tracedConn{Conn: c, ctx: ctx}
+}
+
+type tracedConn struct {
+	redis.Conn
+	ctx     context.Context
+	pending []string
+}
+
+func (c *tracedConn) start(cmd string) trace.Span {
+	name := strings.ToUpper(cmd)
+	if cmd == "" {
+		name = "pipeline"
+	}
+	// Do flushes the queued commands too
+	operations := c.pending
+	if cmd != "" {
+		operations = append(operations, name)
+	}
+	c.pending = nil
+	_, span := otel.Tracer(instrumentationScope).Start(c.ctx, "redis "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation", strings.Join(operations, " ")),
+		))
+	return span
+}
+
+func finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (c *tracedConn) Send(cmd string, args ...interface{}) error {
+	c.pending = append(c.pending, strings.ToUpper(cmd))
+	return c.Conn.Send(cmd, args...)
+}
+
+func (c *tracedConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	span := c.start(cmd)
+	reply, err := c.Conn.Do(cmd, args...)
+	finish(span, err)
+	return reply, err
+}
+
+func (c *tracedConn) DoWithTimeout(timeout time.Duration, cmd string, args ...interface{}) (interface{}, error) {
+	span := c.start(cmd)
+	reply, err := redis.DoWithTimeout(c.Conn, timeout, cmd, args...)
+	finish(span, err)
+	return reply, err
+}
+
+func (c *tracedConn) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return redis.ReceiveWithTimeout(c.Conn, timeout)
+}
@@ -0,0 +1,84 @@
+// Package tracing configures the OpenTelemetry SDK, which records the spans of the gRPC calls
+// and of the redis commands, and exports them to a collector (OTLP/HTTP) or as JSON lines.
+// The trace context is propagated with the W3C traceparent header.
+package tracing
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/stats"
+
+	"github.com/hobord/dsession/logging"
+)
+
+// instrumentationScope names the instrumentation of the redis spans
+const instrumentationScope = "github.com/hobord/dsession/tracing"
+
+func init() {
+	logging.RegisterContextField("trace_id", TraceIDFromContext)
+}
+
+// TraceIDFromContext returns the trace id of the current span, empty without one
+func TraceIDFromContext(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// Options configures the tracer provider
+type Options struct {
+	ServiceName string
+	SampleRatio float64 // ratio of the new traces recorded, the sampled flag of the caller is followed
+}
+
+// NewProvider returns a tracer provider exporting the spans in batches with the exporter
+func NewProvider(exporter sdktrace.SpanExporter, opts Options) (*sdktrace.TracerProvider, error) {
+	if opts.ServiceName == "" {
+		opts.ServiceName = "dsession"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", opts.ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	), nil
+}
+
+// SetProvider sets the global tracer provider and the W3C trace context propagator
+func SetProvider(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// NewOTLPExporter posts the spans to the /v1/traces path of the OTLP/HTTP endpoint, like http://collector:4318
+func NewOTLPExporter(ctx context.Context, endpoint string, headers map[string]string) (sdktrace.SpanExporter, error) {
+	return otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpointURL(strings.TrimSuffix(endpoint, "/")+"/v1/traces"),
+		otlptracehttp.WithHeaders(headers),
+	)
+}
+
+// NewWriterExporter writes the spans as JSON lines, for the stdout and the file exporters
+func NewWriterExporter(w io.Writer) (sdktrace.SpanExporter, error) {
+	return stdouttrace.New(stdouttrace.WithWriter(w))
+}
+
+// ServerHandler records a span of every gRPC call, continuing the trace of the traceparent metadata
+func ServerHandler() stats.Handler {
+	return otelgrpc.NewServerHandler()
+}
@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// nopConn answers every command with OK
+type nopConn struct{}
+
+func (c *nopConn) Close() error                                   { return nil }
+func (c *nopConn) Err() error                                     { return nil }
+func (c *nopConn) Do(string, ...interface{}) (interface{}, error) { return "OK", nil }
+func (c *nopConn) Send(string, ...interface{}) error              { return nil }
+func (c *nopConn) Flush() error                                   { return nil }
+func (c *nopConn) Receive() (interface{}, error)                  { return nil, nil }
+
+func attributeValue(span sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == attribute.Key(key) {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestServerSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	SetProvider(tp)
+
+	lis := bufconn.Listen(1 << 16)
+	server := grpc.NewServer(grpc.StatsHandler(ServerHandler()), grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+		var req healthpb.HealthCheckRequest
+		stream.RecvMsg(&req)
+		conn := RedisConn(stream.Context(), &nopConn{})
+		conn.Send("HGETALL", "abc")
+		conn.Send("TTL", "abc")
+		conn.Do("")
+		redis.DoWithTimeout(conn, time.Second, "get", "abc")
+		return status.Error(codes.NotFound, "Session not found")
+	}))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	err = conn.Invoke(ctx, "/hobord.session.DSessionService/GetSession", &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Got error %v", err)
+	}
+	server.GracefulStop()
+
+	spans := exporter.GetSpans().Snapshots()
+	if len(spans) != 3 {
+		t.Fatalf("Got %d spans", len(spans))
+	}
+	pipeline, get, call := spans[0], spans[1], spans[2]
+	if call.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || call.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("Remote parent is not followed: %s %s", call.SpanContext().TraceID(), call.Parent().SpanID())
+	}
+	if call.Name() != "hobord.session.DSessionService/GetSession" || attributeValue(call, "rpc.grpc.status_code").AsInt64() != int64(codes.NotFound) {
+		t.Errorf("Got server span %s %v", call.Name(), call.Attributes())
+	}
+	if pipeline.Parent().SpanID() != call.SpanContext().SpanID() || attributeValue(pipeline, "db.operation").AsString() != "HGETALL TTL" {
+		t.Errorf("Got pipeline span %s %s", pipeline.Name(), attributeValue(pipeline, "db.operation").AsString())
+	}
+	if get.Name() != "redis GET" || get.SpanContext().TraceID() != call.SpanContext().TraceID() {
+		t.Errorf("Got command span %s", get.Name())
+	}
+}
+
+func TestRedisConnWithoutSpan(t *testing.T) {
+	c := &nopConn{}
+	if RedisConn(context.Background(), c) != c {
+		t.Errorf("Got a traced connection without a span")
+	}
+}
+
+func TestSample(t *testing.T) {
+	tp, err := NewProvider(tracetest.NewInMemoryExporter(), Options{SampleRatio: 0.25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.Shutdown(context.Background())
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		_, span := tp.Tracer("test").Start(context.Background(), "test")
+		if span.SpanContext().IsSampled() {
+			sampled++
+		}
+		span.End()
+	}
+	if sampled < 150 || sampled > 350 {
+		t.Errorf("Got %d sampled of 1000", sampled)
+	}
+}
+
+func TestWriterExporter(t *testing.T) {
+	var buf bytes.Buffer
+	exporter, err := NewWriterExporter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tp, err := NewProvider(exporter, Options{ServiceName: "sessions", SampleRatio: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, span := tp.Tracer("test").Start(context.Background(), "GetSession")
+	span.End()
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, `"Name":"GetSession"`) || !strings.Contains(out, `"Value":"sessions"`) {
+		t.Errorf("Got %s", out)
+	}
+}